@@ -2,9 +2,10 @@ package api
 
 import (
 	"errors"
+	"github.com/fayca121/simplebank/authz"
 	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pagination"
 	"github.com/fayca121/simplebank/token"
-	"github.com/fayca121/simplebank/util"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
 	"net/http"
@@ -23,11 +24,7 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-
-	if authPayload.Username != req.Owner && authPayload.Role != util.BankerRole.String() {
-		err := errors.New("you must create an account on your name")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if !authorize(ctx, authz.AccountCreate, req.Owner) {
 		return
 	}
 
@@ -75,11 +72,7 @@ func (server *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-
-	if authPayload.Username != account.Owner {
-		err := errors.New("account doesn't belong to authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if !authorize(ctx, authz.AccountRead, account.Owner) {
 		return
 	}
 	ctx.JSON(http.StatusOK, account)
@@ -87,8 +80,13 @@ func (server *Server) getAccount(ctx *gin.Context) {
 
 // ----------------------- List Account ---------------------
 type listAccountRequest struct {
-	PageId   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=20"`
+	PageSize  int32  `form:"page_size" binding:"required,min=5,max=100"`
+	PageToken string `form:"page_token"`
+}
+
+type listAccountResponse struct {
+	Accounts      []db.Account `json:"accounts"`
+	NextPageToken string       `json:"next_page_token"`
 }
 
 func (server *Server) listAccount(ctx *gin.Context) {
@@ -102,10 +100,22 @@ func (server *Server) listAccount(ctx *gin.Context) {
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
+	if !authorize(ctx, authz.AccountRead, authPayload.Username) {
+		return
+	}
+
+	scope := pagination.AccountsScope(authPayload.Username)
+	after, err := server.pages.Keyset(scope, req.PageToken)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
 	arg := db.ListAccountsParams{
-		Owner:  authPayload.Username,
-		Limit:  req.PageSize,
-		Offset: (req.PageId - 1) * req.PageSize,
+		Owner:          authPayload.Username,
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+		Limit:          req.PageSize + 1,
 	}
 
 	accounts, err := server.store.ListAccounts(ctx, arg)
@@ -115,61 +125,128 @@ func (server *Server) listAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	accounts, next := pagination.NextPage(server.pages, scope, accounts, req.PageSize, func(account db.Account) pagination.Cursor {
+		return pagination.NewCursor(account.CreatedAt, account.ID)
+	})
+	ctx.JSON(http.StatusOK, listAccountResponse{Accounts: accounts, NextPageToken: next})
 }
 
-// ----------------- Update account ------------------------
-type updateAccountRequest struct {
-	ID      int64 `json:"id" binding:"required,min=1"`
-	Balance int64 `json:"balance" binding:"required"`
-}
-
-func (server *Server) updateAccount(ctx *gin.Context) {
-	var req updateAccountRequest
+// ------------------ Delete Account ----------------------------------
+func (server *Server) deleteAccount(ctx *gin.Context) {
+	var req getAccountRequest
 
-	if err := ctx.ShouldBindJSON(&req); err != nil {
+	if err := ctx.ShouldBindUri(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
-	_, err := server.store.GetAccount(ctx, req.ID)
 
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+	account, valid := server.findAccount(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	if !authorize(ctx, authz.AccountDelete, account.Owner) {
+		return
+	}
+
+	if err := server.store.DeleteAccount(ctx, req.ID); err != nil {
+		// the entries and status changes of an account are kept, an account with a history is closed instead
+		if db.ErrCode(err) == db.ForeignKeyViolation {
+			ctx.JSON(http.StatusConflict, errorResponse(errors.New("account has entries, close it instead")))
 			return
 		}
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	arg := db.UpdateAccountParams{
-		ID:      req.ID,
-		Balance: req.Balance,
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// ------------------ Update Account Status ----------------------------------
+type updateAccountStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active frozen closed"`
+	Reason string `json:"reason" binding:"required,max=200"`
+}
+
+func (server *Server) updateAccountStatus(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req updateAccountStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
 	}
 
-	account, err := server.store.UpdateAccount(ctx, arg)
+	account, valid := server.findAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+	if !authorize(ctx, authz.AccountStatus, account.Owner) {
+		return
+	}
 
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.store.UpdateAccountStatusTx(ctx, db.UpdateAccountStatusTxParam{
+		AccountID: account.ID,
+		Status:    req.Status,
+		Reason:    req.Reason,
+		ChangedBy: authPayload.Username,
+	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		switch {
+		case errors.Is(err, db.ErrCashAccount):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		case errors.Is(err, db.ErrInvalidStatusChange):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		case errors.Is(err, db.ErrAccountNotEmpty):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
 		return
 	}
-
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, result)
 }
 
-// ------------------ Delete Account ----------------------------------
-func (server *Server) deleteAccount(ctx *gin.Context) {
-	var req getAccountRequest
+// ------------------ Update Overdraft Limit ----------------------------------
+type updateOverdraftLimitRequest struct {
+	OverdraftLimit *int64 `json:"overdraft_limit" binding:"required,min=0"`
+}
 
-	if err := ctx.ShouldBindUri(&req); err != nil {
+func (server *Server) updateOverdraftLimit(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req updateOverdraftLimitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	if err := server.store.DeleteAccount(ctx, req.ID); err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	account, valid := server.findAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+	if !authorize(ctx, authz.AccountOverdraft, account.Owner) {
 		return
 	}
 
-	ctx.JSON(http.StatusNoContent, nil)
+	account, err := server.store.UpdateAccountOverdraftLimit(ctx, db.UpdateAccountOverdraftLimitParams{
+		OverdraftLimit: *req.OverdraftLimit,
+		ID:             account.ID,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrCashAccount) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, account)
 }
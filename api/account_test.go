@@ -9,8 +9,10 @@ import (
 	db "github.com/fayca121/simplebank/db/sqlc"
 	"github.com/fayca121/simplebank/token"
 	"github.com/fayca121/simplebank/util"
+	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/require"
 	"io"
 	"net/http"
@@ -130,6 +132,27 @@ func TestGetAccountAPI(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name:      "AuditorReadsOtherAccount",
+			accountId: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				generatedToken, payload, err := tokenMaker.CreateToken(util.RandomOwner(), util.AuditorRole, time.Minute)
+				require.NoError(t, err)
+				require.NotEmpty(t, payload)
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, generatedToken)
+				request.Header.Add(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
 	}
 
 	//build stubs
@@ -285,12 +308,319 @@ func TestCreateAccountAPI(t *testing.T) {
 	}
 }
 
+func TestUpdateAccountStatusAPI(t *testing.T) {
+	account := randomAccount(util.RandomOwner())
+	banker := util.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		request       updateAccountStatusRequest
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Freeze",
+			request:   updateAccountStatusRequest{Status: db.AccountStatusFrozen, Reason: "fraud investigation"},
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				frozen := account
+				frozen.Status = db.AccountStatusFrozen
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Eq(db.UpdateAccountStatusTxParam{
+						AccountID: account.ID,
+						Status:    db.AccountStatusFrozen,
+						Reason:    "fraud investigation",
+						ChangedBy: banker,
+					})).
+					Times(1).
+					Return(db.UpdateAccountStatusTxResult{Account: frozen}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var resp db.UpdateAccountStatusTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, db.AccountStatusFrozen, resp.Account.Status)
+			},
+		},
+		{
+			name:      "OwnerCannotFreeze",
+			request:   updateAccountStatusRequest{Status: db.AccountStatusFrozen, Reason: "lost card"},
+			setupAuth: addSessionAuthorization(account.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "CloseNotEmpty",
+			request:   updateAccountStatusRequest{Status: db.AccountStatusClosed, Reason: "customer request"},
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateAccountStatusTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.UpdateAccountStatusTxResult{}, db.ErrAccountNotEmpty)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidChange",
+			request:   updateAccountStatusRequest{Status: db.AccountStatusFrozen, Reason: "fraud investigation"},
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateAccountStatusTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.UpdateAccountStatusTxResult{}, db.ErrInvalidStatusChange)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "UnknownStatus",
+			request:   updateAccountStatusRequest{Status: "deleted", Reason: "fraud investigation"},
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "MissingReason",
+			request:   updateAccountStatusRequest{Status: db.AccountStatusFrozen},
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			body, err := json.Marshal(tc.request)
+			require.NoError(t, err)
+			url := fmt.Sprintf("/accounts/%d/status", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+			require.NoError(t, err)
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestDeleteAccountAPI(t *testing.T) {
+	account := randomAccount(util.RandomOwner())
+	banker := util.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DeleteAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:      "HasEntries",
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DeleteAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).
+					Return(&pgconn.PgError{Code: db.ForeignKeyViolation})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "OwnerCannotDelete",
+			setupAuth: addSessionAuthorization(account.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DeleteAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/accounts/%d", account.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestUpdateOverdraftLimitAPI(t *testing.T) {
+	account := randomAccount(util.RandomOwner())
+	banker := util.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			body:      gin.H{"overdraft_limit": 500},
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				updated := account
+				updated.OverdraftLimit = 500
+				store.EXPECT().
+					UpdateAccountOverdraftLimit(gomock.Any(), gomock.Eq(db.UpdateAccountOverdraftLimitParams{
+						OverdraftLimit: 500,
+						ID:             account.ID,
+					})).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var resp db.Account
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, int64(500), resp.OverdraftLimit)
+			},
+		},
+		{
+			name:      "RemoveLimit",
+			body:      gin.H{"overdraft_limit": 0},
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					UpdateAccountOverdraftLimit(gomock.Any(), gomock.Eq(db.UpdateAccountOverdraftLimitParams{ID: account.ID})).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "OwnerCannotSetLimit",
+			body:      gin.H{"overdraft_limit": 500},
+			setupAuth: addSessionAuthorization(account.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateAccountOverdraftLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "CashAccount",
+			body:      gin.H{"overdraft_limit": 500},
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateAccountOverdraftLimit(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.Account{}, db.ErrCashAccount)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "NegativeLimit",
+			body:      gin.H{"overdraft_limit": -1},
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "MissingLimit",
+			body:      gin.H{},
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			url := fmt.Sprintf("/accounts/%d/overdraft-limit", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+			require.NoError(t, err)
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
 func randomAccount(owner string) db.Account {
 	return db.Account{
 		ID:       util.RandomInt(1, 1000),
 		Owner:    owner,
 		Balance:  util.RandomMoney(),
 		Currency: util.RandomCurrency(),
+		Status:   db.AccountStatusActive,
 	}
 }
 
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pagination"
+	"github.com/fayca121/simplebank/val"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// auditEventResponse is an audit event whose states are rendered as JSON rather than as bytes
+type auditEventResponse struct {
+	ID           int64           `json:"id"`
+	Actor        string          `json:"actor"`
+	ActorRole    string          `json:"actor_role"`
+	ClientIp     string          `json:"client_ip"`
+	UserAgent    string          `json:"user_agent"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	Before       json.RawMessage `json:"before"`
+	After        json.RawMessage `json:"after"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+func newAuditEventResponse(event db.AuditEvent) auditEventResponse {
+	return auditEventResponse{
+		ID:           event.ID,
+		Actor:        event.Actor,
+		ActorRole:    event.ActorRole,
+		ClientIp:     event.ClientIp,
+		UserAgent:    event.UserAgent,
+		Action:       event.Action,
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		Before:       event.Before,
+		After:        event.After,
+		CreatedAt:    event.CreatedAt,
+	}
+}
+
+// ------------------ List Audit Events ------------------------
+type listAuditEventsRequest struct {
+	PageSize     int32      `form:"page_size" binding:"required,min=5,max=100"`
+	PageToken    string     `form:"page_token"`
+	Actor        string     `form:"actor"`
+	Action       string     `form:"action"`
+	ResourceType string     `form:"resource_type"`
+	ResourceID   string     `form:"resource_id"`
+	CreatedFrom  *time.Time `form:"created_from"`
+	CreatedTo    *time.Time `form:"created_to"`
+}
+
+type listAuditEventsResponse struct {
+	Events        []auditEventResponse `json:"events"`
+	NextPageToken string               `json:"next_page_token"`
+}
+
+func (server *Server) listAuditEvents(ctx *gin.Context) {
+	var req listAuditEventsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := val.ValidateTimeRange(req.CreatedFrom, req.CreatedTo); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	// the events belong to no one, only the :any variant of the permission lets them be read
+	if !authorize(ctx, authz.AuditRead, "") {
+		return
+	}
+
+	scope := pagination.AuditEventsScope()
+	after, err := server.pages.Keyset(scope, req.PageToken)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	filter := db.AuditFilter{
+		Actor:        req.Actor,
+		Action:       req.Action,
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceID,
+		CreatedFrom:  req.CreatedFrom,
+		CreatedTo:    req.CreatedTo,
+	}
+	events, err := server.store.ListAuditEvents(ctx, filter.AuditEventsParams(after, req.PageSize+1))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	events, next := pagination.NextPage(server.pages, scope, events, req.PageSize, func(event db.AuditEvent) pagination.Cursor {
+		return pagination.NewCursor(event.CreatedAt, event.ID)
+	})
+	rsp := listAuditEventsResponse{
+		Events:        make([]auditEventResponse, 0, len(events)),
+		NextPageToken: next,
+	}
+	for _, event := range events {
+		rsp.Events = append(rsp.Events, newAuditEventResponse(event))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	mockdb "github.com/fayca121/simplebank/db/mock"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/token"
+	"github.com/fayca121/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListAuditEventsAPI(t *testing.T) {
+	banker := util.RandomOwner()
+	events := []db.AuditEvent{{
+		ID:           1,
+		Actor:        banker,
+		ActorRole:    util.BankerRole.String(),
+		Action:       db.AuditAccountStatus,
+		ResourceType: db.AuditResourceAccount,
+		ResourceID:   "7",
+		Before:       []byte(`{"status":"active"}`),
+		After:        []byte(`{"status":"frozen"}`),
+		CreatedAt:    time.Now(),
+	}}
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			query:     "?page_size=5&actor=" + banker + "&resource_type=account&resource_id=7",
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				arg := db.ListAuditEventsParams{
+					Actor:        pgtype.Text{String: banker, Valid: true},
+					ResourceType: pgtype.Text{String: db.AuditResourceAccount, Valid: true},
+					ResourceID:   pgtype.Text{String: "7", Valid: true},
+					Limit:        6,
+				}
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Eq(arg)).Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ListAuditEventsParams) ([]db.AuditEvent, error) {
+						// the store sees the authenticated user as the actor of the request
+						require.Equal(t, banker, db.ActorFrom(ctx).Username)
+						return events, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var listed listAuditEventsResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &listed))
+				require.Len(t, listed.Events, 1)
+				require.JSONEq(t, `{"status":"frozen"}`, string(listed.Events[0].After))
+				require.Empty(t, listed.NextPageToken)
+			},
+		},
+		{
+			name:      "DepositorCannotList",
+			query:     "?page_size=5",
+			setupAuth: addSessionAuthorization(util.RandomOwner(), util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			query:     "?page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidTimeRange",
+			query:     "?page_size=5&created_from=2024-02-01T00:00:00Z&created_to=2024-01-01T00:00:00Z",
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidPageToken",
+			query:     "?page_size=5&page_token=forged",
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			query:     "?page_size=5",
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(1).
+					Return(nil, errors.New("connection lost"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/audit-events"+tc.query, nil)
+			require.NoError(t, err)
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// ------------------ Deposit / Withdraw ------------------------
+type cashRequest struct {
+	Amount   int64  `json:"amount" binding:"required,gt=0"`
+	Currency string `json:"currency" binding:"required,currency"`
+}
+
+type cashResponse struct {
+	Transfer db.Transfer `json:"transfer"`
+	Account  db.Account  `json:"account"`
+	Entry    db.Entry    `json:"entry"`
+}
+
+func (server *Server) deposit(ctx *gin.Context) {
+	server.cashOperation(ctx, authz.AccountDeposit, server.store.DepositTx)
+}
+
+func (server *Server) withdraw(ctx *gin.Context) {
+	server.cashOperation(ctx, authz.AccountWithdraw, server.store.WithdrawTx)
+}
+
+// cashOperation moves the amount of the request between the account of the uri and the cash account of its currency
+func (server *Server) cashOperation(ctx *gin.Context, permission authz.Permission,
+	operation func(context.Context, db.CashTxParam) (db.CashTxResult, error)) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req cashRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, uri.ID, req.Currency)
+	if !valid {
+		return
+	}
+	if !authorize(ctx, permission, account.Owner) {
+		return
+	}
+
+	result, err := operation(ctx, db.CashTxParam{
+		AccountID: account.ID,
+		Amount:    req.Amount,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrCashAccount) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountFrozen) ||
+			errors.Is(err, db.ErrAccountClosed) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusCreated, cashResponse{
+		Transfer: result.Transfer,
+		Account:  result.Account,
+		Entry:    result.Entry,
+	})
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/fayca121/simplebank/db/mock"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/token"
+	"github.com/fayca121/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCashOperationsAPI(t *testing.T) {
+	user := randomUser()
+	account := randomAccount(user.Username)
+	banker := util.RandomOwner()
+	result := db.CashTxResult{
+		Transfer: db.Transfer{ID: 1, ToAccountID: account.ID, Amount: 100, Kind: db.TransferKindDeposit},
+		Account:  account,
+		Entry:    db.Entry{ID: 1, AccountID: account.ID, Amount: 100},
+	}
+	result.Account.Balance += 100
+
+	testCases := []struct {
+		name          string
+		path          string
+		request       cashRequest
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Deposit",
+			path:      "deposit",
+			request:   cashRequest{Amount: 100, Currency: account.Currency},
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Eq(db.CashTxParam{AccountID: account.ID, Amount: 100})).
+					Times(1).
+					Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				var resp cashResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, result.Account.Balance, resp.Account.Balance)
+				require.Equal(t, db.TransferKindDeposit, resp.Transfer.Kind)
+			},
+		},
+		{
+			name:      "WithdrawInsufficientFunds",
+			path:      "withdraw",
+			request:   cashRequest{Amount: 100, Currency: account.Currency},
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Eq(db.CashTxParam{AccountID: account.ID, Amount: 100})).
+					Times(1).
+					Return(db.CashTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:      "DepositorCannotDeposit",
+			path:      "deposit",
+			request:   cashRequest{Amount: 100, Currency: account.Currency},
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "CashAccount",
+			path:      "deposit",
+			request:   cashRequest{Amount: 100, Currency: account.Currency},
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CashTxResult{}, db.ErrCashAccount)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidAmount",
+			path:      "withdraw",
+			request:   cashRequest{Amount: -1, Currency: account.Currency},
+			setupAuth: addSessionAuthorization(banker, util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			body, err := json.Marshal(tc.request)
+			require.NoError(t, err)
+			path := fmt.Sprintf("/accounts/%d/%s", account.ID, tc.path)
+			request, err := http.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+			require.NoError(t, err)
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
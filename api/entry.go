@@ -0,0 +1,66 @@
+package api
+
+import (
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pagination"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// ----------------------- List Entries ---------------------
+type listEntriesRequest struct {
+	PageSize  int32  `form:"page_size" binding:"required,min=5,max=100"`
+	PageToken string `form:"page_token"`
+	listFilterRequest
+}
+
+type listEntriesResponse struct {
+	Entries       []db.Entry `json:"entries"`
+	NextPageToken string     `json:"next_page_token"`
+}
+
+func (server *Server) listEntries(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req listEntriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	filter, err := req.filter()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.findAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+	if !authorize(ctx, authz.AccountRead, account.Owner) {
+		return
+	}
+
+	scope := pagination.EntriesScope(account.ID)
+	after, err := server.pages.Keyset(scope, req.PageToken)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := filter.EntriesParams(account.ID, after, req.PageSize+1)
+	entries, err := server.store.ListEntries(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	entries, next := pagination.NextPage(server.pages, scope, entries, req.PageSize, func(entry db.Entry) pagination.Cursor {
+		return pagination.NewCursor(entry.CreatedAt, entry.ID)
+	})
+	ctx.JSON(http.StatusOK, listEntriesResponse{Entries: entries, NextPageToken: next})
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/fayca121/simplebank/db/mock"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/token"
+	"github.com/fayca121/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestListEntriesAPI(t *testing.T) {
+	user := randomUser()
+	account := randomAccount(user.Username)
+	entries := []db.Entry{
+		{ID: 1, AccountID: account.ID, Amount: 500, CreatedAt: time.Now()},
+		{ID: 2, AccountID: account.ID, Amount: 700, CreatedAt: time.Now()},
+	}
+	createdFrom := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			query:     url.Values{"page_size": {"5"}},
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				arg := db.ListEntriesParams{
+					AccountID: account.ID,
+					Direction: db.DirectionBoth,
+					Limit:     6,
+				}
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Eq(arg)).Times(1).Return(entries, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Filters",
+			query: url.Values{"page_size": {"5"}, "direction": {"incoming"},
+				"min_amount": {"100"}, "max_amount": {"1000"}, "created_from": {createdFrom.Format(time.RFC3339)},
+				"counterparty_account_id": {"42"}},
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				arg := db.ListEntriesParams{
+					AccountID:      account.ID,
+					Direction:      db.DirectionIncoming,
+					CounterpartyID: pgtype.Int8{Int64: 42, Valid: true},
+					CreatedFrom:    pgtype.Timestamptz{Time: createdFrom, Valid: true},
+					MinAmount:      pgtype.Int8{Int64: 100, Valid: true},
+					MaxAmount:      pgtype.Int8{Int64: 1000, Valid: true},
+					Limit:          6,
+				}
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Eq(arg)).Times(1).Return(entries, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidDirection",
+			query:     url.Values{"page_size": {"5"}, "direction": {"sideways"}},
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidAmountRange",
+			query:     url.Values{"page_size": {"5"}, "min_amount": {"500"}, "max_amount": {"100"}},
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidPageToken",
+			query:     url.Values{"page_size": {"5"}, "page_token": {"forged"}},
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "UnauthorizedUser",
+			query:     url.Values{"page_size": {"5"}},
+			setupAuth: addSessionAuthorization(util.RandomOwner(), util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			path := fmt.Sprintf("/accounts/%d/entries?%s", account.ID, tc.query.Encode())
+			request, err := http.NewRequest(http.MethodGet, path, nil)
+			require.NoError(t, err)
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListEntriesAPINextPage(t *testing.T) {
+	user := randomUser()
+	account := randomAccount(user.Username)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	entries := make([]db.Entry, 0, 6)
+	for i := 1; i <= 6; i++ {
+		entries = append(entries, db.Entry{ID: int64(i), AccountID: account.ID, Amount: 100, CreatedAt: now})
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(2).Return(account, nil)
+	gomock.InOrder(
+		store.EXPECT().
+			ListEntries(gomock.Any(), gomock.Eq(db.ListEntriesParams{AccountID: account.ID, Direction: db.DirectionBoth, Limit: 6})).
+			Times(1).
+			Return(entries, nil),
+		store.EXPECT().
+			ListEntries(gomock.Any(), gomock.Eq(db.ListEntriesParams{
+				AccountID:      account.ID,
+				Direction:      db.DirectionBoth,
+				AfterCreatedAt: pgtype.Timestamptz{Time: now, Valid: true},
+				AfterID:        pgtype.Int8{Int64: 5, Valid: true},
+				Limit:          6,
+			})).
+			Times(1).
+			Return(entries[5:], nil),
+	)
+
+	server := NewTestServer(t, store)
+	list := func(query url.Values) listEntriesResponse {
+		recorder := httptest.NewRecorder()
+		path := fmt.Sprintf("/accounts/%d/entries?%s", account.ID, query.Encode())
+		request, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		addSessionAuthorization(user.Username, util.DepositorRole)(t, request, server.tokenMaker)
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		return requireBodyMatchEntries(t, recorder.Body, nil)
+	}
+
+	first := list(url.Values{"page_size": {"5"}})
+	require.Len(t, first.Entries, 5)
+	require.NotEmpty(t, first.NextPageToken)
+
+	second := list(url.Values{"page_size": {"5"}, "page_token": {first.NextPageToken}})
+	require.Len(t, second.Entries, 1)
+	require.Equal(t, int64(6), second.Entries[0].ID)
+	require.Empty(t, second.NextPageToken)
+}
+
+// requireBodyMatchEntries decodes a page of entries and checks its ids when entries is not nil
+func requireBodyMatchEntries(t *testing.T, body *bytes.Buffer, entries []db.Entry) listEntriesResponse {
+	var resp listEntriesResponse
+	require.NoError(t, json.NewDecoder(body).Decode(&resp))
+	if entries != nil {
+		require.Len(t, resp.Entries, len(entries))
+		for i := range entries {
+			require.Equal(t, entries[i].ID, resp.Entries[i].ID)
+		}
+	}
+	return resp
+}
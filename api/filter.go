@@ -0,0 +1,34 @@
+package api
+
+import (
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/val"
+	"time"
+)
+
+// listFilterRequest holds the optional filters of the entries and transfers listings
+type listFilterRequest struct {
+	CreatedFrom    *time.Time `form:"created_from"`
+	CreatedTo      *time.Time `form:"created_to"`
+	MinAmount      *int64     `form:"min_amount" binding:"omitempty,min=0"`
+	MaxAmount      *int64     `form:"max_amount" binding:"omitempty,min=0"`
+	Direction      string     `form:"direction" binding:"omitempty,oneof=both incoming outgoing"`
+	CounterpartyID *int64     `form:"counterparty_account_id" binding:"omitempty,min=1"`
+}
+
+func (req listFilterRequest) filter() (db.ListFilter, error) {
+	if err := val.ValidateTimeRange(req.CreatedFrom, req.CreatedTo); err != nil {
+		return db.ListFilter{}, err
+	}
+	if err := val.ValidateAmountRange(req.MinAmount, req.MaxAmount); err != nil {
+		return db.ListFilter{}, err
+	}
+	return db.ListFilter{
+		Direction:      req.Direction,
+		CounterpartyID: req.CounterpartyID,
+		CreatedFrom:    req.CreatedFrom,
+		CreatedTo:      req.CreatedTo,
+		MinAmount:      req.MinAmount,
+		MaxAmount:      req.MaxAmount,
+	}, nil
+}
@@ -12,8 +12,11 @@ import (
 
 func NewTestServer(t *testing.T, store db.Store) *Server {
 	config := util.Config{
-		TokenSymmetricKey:   util.RandomString(32),
-		AccessTokenDuration: time.Minute,
+		TokenSymmetricKey:    util.RandomString(32),
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+		TokenRevocationStore: "memory",
+		PageTokenKey:         util.RandomString(32),
 	}
 	server, err := NewServer(config, store)
 	require.NoError(t, err)
@@ -2,6 +2,8 @@ package api
 
 import (
 	"errors"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
 	"github.com/fayca121/simplebank/token"
 	"github.com/gin-gonic/gin"
 	"net/http"
@@ -14,27 +16,13 @@ const (
 	authorizationPayloadKey = "authorization_payload_key"
 )
 
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+func authMiddleware(tokenMaker token.Maker, revocations token.RevocationStore) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		authorizationHeader := ctx.Request.Header.Get(authorizationHeaderKey)
-		if len(authorizationHeader) == 0 {
-			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
-			return
-		}
-		fields := strings.Fields(authorizationHeader)
-		if len(fields) < 2 {
-			err := errors.New("invalid authorization header format")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
-			return
-		}
-		authorizationType := strings.ToLower(fields[0])
-		if authorizationTypeBearer != authorizationType {
-			err := errors.New("unsupported authorization type")
+		accessToken, err := accessTokenFromHeader(ctx.Request.Header.Get(authorizationHeaderKey))
+		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
 			return
 		}
-		accessToken := fields[1]
 		//verification of token
 		payload, err := tokenMaker.VerifyToken(accessToken)
 		if err != nil {
@@ -42,7 +30,64 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 			return
 		}
 
+		if err = token.CheckRevocation(ctx, revocations, payload); err != nil {
+			if errors.Is(err, token.ErrRevokedToken) {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
 		ctx.Set(authorizationPayloadKey, payload)
+		setActor(ctx, payload.Username, payload.Role)
+		ctx.Next()
+	}
+}
+
+// actorMiddleware audits the mutations of the request as made by an anonymous actor,
+// authMiddleware replaces it with the authenticated user
+func actorMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		setActor(ctx, db.ActorAnonymous, "")
 		ctx.Next()
 	}
 }
+
+// setActor puts the actor in the context of the request, the handlers hand it to the store
+// with the *gin.Context since the engine falls back to the context of the request
+func setActor(ctx *gin.Context, username string, role string) {
+	actor := db.Actor{
+		Username:  username,
+		Role:      role,
+		ClientIP:  ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+	ctx.Request = ctx.Request.WithContext(db.WithActor(ctx.Request.Context(), actor))
+}
+
+func accessTokenFromHeader(authorizationHeader string) (string, error) {
+	if len(authorizationHeader) == 0 {
+		return "", errors.New("authorization header is not provided")
+	}
+	fields := strings.Fields(authorizationHeader)
+	if len(fields) < 2 {
+		return "", errors.New("invalid authorization header format")
+	}
+	authorizationType := strings.ToLower(fields[0])
+	if authorizationTypeBearer != authorizationType {
+		return "", errors.New("unsupported authorization type")
+	}
+	return fields[1], nil
+}
+
+// authorize answers 401 and returns false when the authenticated user may not perform the action
+// on a resource belonging to owner
+func authorize(ctx *gin.Context, permission authz.Permission, owner string) bool {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if err := authz.Authorize(authPayload, permission, owner); err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return false
+	}
+	return true
+}
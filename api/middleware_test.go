@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"github.com/fayca121/simplebank/token"
 	"github.com/fayca121/simplebank/util"
@@ -79,7 +80,7 @@ func TestMiddleware(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			server := NewTestServer(t, nil)
 			authPath := "/auth"
-			server.router.GET(authPath, authMiddleware(server.tokenMaker), func(ctx *gin.Context) {
+			server.router.GET(authPath, authMiddleware(server.tokenMaker, server.revocations), func(ctx *gin.Context) {
 				ctx.JSON(http.StatusOK, gin.H{})
 			})
 			recorder := httptest.NewRecorder()
@@ -91,3 +92,34 @@ func TestMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestMiddlewareRevokedToken(t *testing.T) {
+	server := NewTestServer(t, nil)
+	authPath := "/auth"
+	server.router.GET(authPath, authMiddleware(server.tokenMaker, server.revocations), func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{})
+	})
+
+	username := util.RandomOwner()
+	send := func(generatedToken string) int {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, authPath, nil)
+		require.NoError(t, err)
+		request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, generatedToken))
+		server.router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	token1, payload1, err := server.tokenMaker.CreateToken(username, util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+	token2, _, err := server.tokenMaker.CreateToken(username, util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, send(token1))
+
+	require.NoError(t, server.revocations.RevokeToken(context.Background(), payload1))
+	require.Equal(t, http.StatusUnauthorized, send(token1))
+	require.Equal(t, http.StatusOK, send(token2))
+
+	require.NoError(t, server.revocations.RevokeUserTokens(context.Background(), username, time.Now().Add(time.Second)))
+	require.Equal(t, http.StatusUnauthorized, send(token2))
+}
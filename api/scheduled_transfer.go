@@ -0,0 +1,259 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pagination"
+	"github.com/fayca121/simplebank/schedule"
+	"github.com/fayca121/simplebank/token"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"time"
+)
+
+// recentRuns is the number of runs returned along a scheduled transfer
+const recentRuns = 10
+
+// ------------------ Create Scheduled Transfer ------------------------
+type createScheduledTransferRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+	Schedule      string `json:"schedule" binding:"required,schedule"`
+	// StartAt defaults to now, the first transfer is made at the first occurrence of the schedule from then
+	StartAt *time.Time `json:"start_at"`
+}
+
+func (server *Server) createScheduledTransfer(ctx *gin.Context) {
+	var req createScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	now := time.Now()
+	start := now
+	if req.StartAt != nil {
+		if req.StartAt.Before(now) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("start_at must not be in the past")))
+			return
+		}
+		start = *req.StartAt
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+	if !authorize(ctx, authz.TransferCreate, fromAccount.Owner) {
+		return
+	}
+	// the transfers are made without conversion, both accounts hold the same currency
+	if _, valid = server.validAccount(ctx, req.ToAccountID, req.Currency); !valid {
+		return
+	}
+
+	sched, err := schedule.Parse(req.Schedule)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	first := schedule.First(sched, start)
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	scheduled, err := server.store.CreateScheduledTransfer(ctx, db.CreateScheduledTransferParams{
+		Username:      authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Schedule:      req.Schedule,
+		OccurrenceAt:  first,
+		NextRunAt:     first,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusCreated, scheduled)
+}
+
+// ------------------ Get Scheduled Transfer ------------------------
+type scheduledTransferUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type getScheduledTransferResponse struct {
+	db.ScheduledTransfer
+	// Runs are the most recent runs, latest first
+	Runs []db.ScheduledTransferRun `json:"runs"`
+}
+
+func (server *Server) getScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, valid := server.accessibleScheduledTransfer(ctx, uri.ID, authz.AccountRead)
+	if !valid {
+		return
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(ctx, db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		Limit:               recentRuns,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, getScheduledTransferResponse{ScheduledTransfer: scheduled, Runs: runs})
+}
+
+// ------------------ List Scheduled Transfers ------------------------
+type listScheduledTransfersRequest struct {
+	PageSize  int32  `form:"page_size" binding:"required,min=5,max=100"`
+	PageToken string `form:"page_token"`
+}
+
+type listScheduledTransfersResponse struct {
+	ScheduledTransfers []db.ScheduledTransfer `json:"scheduled_transfers"`
+	NextPageToken      string                 `json:"next_page_token"`
+}
+
+func (server *Server) listScheduledTransfers(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req listScheduledTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.findAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+	if !authorize(ctx, authz.AccountRead, account.Owner) {
+		return
+	}
+
+	scope := pagination.ScheduledTransfersScope(account.ID)
+	after, err := server.pages.Keyset(scope, req.PageToken)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, err := server.store.ListScheduledTransfers(ctx, db.ListScheduledTransfersParams{
+		FromAccountID:  account.ID,
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+		Limit:          req.PageSize + 1,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	scheduled, next := pagination.NextPage(server.pages, scope, scheduled, req.PageSize, func(scheduled db.ScheduledTransfer) pagination.Cursor {
+		return pagination.NewCursor(scheduled.CreatedAt, scheduled.ID)
+	})
+	ctx.JSON(http.StatusOK, listScheduledTransfersResponse{ScheduledTransfers: scheduled, NextPageToken: next})
+}
+
+// ------------------ Update Scheduled Transfer ------------------------
+type updateScheduledTransferRequest struct {
+	Amount   *int64  `json:"amount" binding:"omitempty,gt=0"`
+	Schedule *string `json:"schedule" binding:"omitempty,schedule"`
+	Status   *string `json:"status" binding:"omitempty,oneof=active paused"`
+}
+
+func (server *Server) updateScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req updateScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, valid := server.accessibleScheduledTransfer(ctx, uri.ID, authz.TransferCreate)
+	if !valid {
+		return
+	}
+
+	arg := db.UpdateScheduledTransferParams{ID: scheduled.ID}
+	if req.Amount != nil {
+		arg.Amount = pgtype.Int8{Int64: *req.Amount, Valid: true}
+	}
+	if req.Schedule != nil {
+		arg.Schedule = pgtype.Text{String: *req.Schedule, Valid: true}
+	}
+	if req.Status != nil {
+		arg.Status = pgtype.Text{String: *req.Status, Valid: true}
+	}
+	if err := schedule.Reschedule(scheduled, &arg, time.Now()); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, err := server.store.UpdateScheduledTransfer(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, scheduled)
+}
+
+// ------------------ Delete Scheduled Transfer ------------------------
+func (server *Server) deleteScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, valid := server.accessibleScheduledTransfer(ctx, uri.ID, authz.TransferCreate)
+	if !valid {
+		return
+	}
+
+	if err := server.store.DeleteScheduledTransfer(ctx, scheduled.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// accessibleScheduledTransfer loads the scheduled transfer and checks the permission against the owner of its source account
+func (server *Server) accessibleScheduledTransfer(ctx *gin.Context, id int64, permission authz.Permission) (db.ScheduledTransfer, bool) {
+	scheduled, err := server.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return scheduled, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return scheduled, false
+	}
+	fromAccount, valid := server.findAccount(ctx, scheduled.FromAccountID)
+	if !valid {
+		return scheduled, false
+	}
+	if !authorize(ctx, permission, fromAccount.Owner) {
+		return scheduled, false
+	}
+	return scheduled, true
+}
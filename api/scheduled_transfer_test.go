@@ -0,0 +1,291 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/fayca121/simplebank/db/mock"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/schedule"
+	"github.com/fayca121/simplebank/token"
+	"github.com/fayca121/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCreateScheduledTransferAPI(t *testing.T) {
+	user := randomUser()
+	fromAccount := randomAccount(user.Username)
+	toAccount := randomAccount(util.RandomOwner())
+	toAccount.Currency = fromAccount.Currency
+
+	startAt := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	monthly, err := schedule.Parse("0 9 1 * *")
+	require.NoError(t, err)
+	first := schedule.First(monthly, startAt)
+
+	testCases := []struct {
+		name          string
+		request       createScheduledTransferRequest
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			request: createScheduledTransferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        100,
+				Currency:      fromAccount.Currency,
+				Schedule:      "0 9 1 * *",
+				StartAt:       &startAt,
+			},
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				arg := db.CreateScheduledTransferParams{
+					Username:      user.Username,
+					FromAccountID: fromAccount.ID,
+					ToAccountID:   toAccount.ID,
+					Amount:        100,
+					Schedule:      "0 9 1 * *",
+					OccurrenceAt:  first,
+					NextRunAt:     first,
+				}
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.ScheduledTransfer{ID: 1, OccurrenceAt: first, Status: db.ScheduledTransferActive}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				var scheduled db.ScheduledTransfer
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &scheduled))
+				require.True(t, first.Equal(scheduled.OccurrenceAt))
+			},
+		},
+		{
+			name: "InvalidSchedule",
+			request: createScheduledTransferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        100,
+				Currency:      fromAccount.Currency,
+				Schedule:      "0 9 32 * *",
+			},
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "SameAccount",
+			request: createScheduledTransferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   fromAccount.ID,
+				Amount:        100,
+				Currency:      fromAccount.Currency,
+				Schedule:      "@weekly",
+			},
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			request: createScheduledTransferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        100,
+				Currency:      fromAccount.Currency,
+				Schedule:      "@every 24h",
+			},
+			setupAuth: addSessionAuthorization(toAccount.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			body, err := json.Marshal(tc.request)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/scheduled-transfers", bytes.NewReader(body))
+			require.NoError(t, err)
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestScheduledTransferAPI(t *testing.T) {
+	user := randomUser()
+	fromAccount := randomAccount(user.Username)
+	scheduled := db.ScheduledTransfer{
+		ID:            3,
+		Username:      user.Username,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   fromAccount.ID + 1,
+		Amount:        100,
+		Schedule:      "0 9 1 * *",
+		Status:        db.ScheduledTransferActive,
+		OccurrenceAt:  time.Date(2030, time.January, 1, 9, 0, 0, 0, time.UTC),
+	}
+	runs := []db.ScheduledTransferRun{{
+		ID:                  1,
+		ScheduledTransferID: scheduled.ID,
+		Attempt:             1,
+		Error:               pgtype.Text{String: db.ErrInsufficientFunds.Error(), Valid: true},
+	}}
+	paused := pgtype.Text{String: db.ScheduledTransferPaused, Valid: true}
+
+	testCases := []struct {
+		name          string
+		method        string
+		body          any
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Get",
+			method:    http.MethodGet,
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					ListScheduledTransferRuns(gomock.Any(), gomock.Eq(db.ListScheduledTransferRunsParams{
+						ScheduledTransferID: scheduled.ID,
+						Limit:               recentRuns,
+					})).
+					Times(1).
+					Return(runs, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var resp getScheduledTransferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, scheduled.ID, resp.ID)
+				require.Equal(t, runs, resp.Runs)
+			},
+		},
+		{
+			name:      "GetNotFound",
+			method:    http.MethodGet,
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).
+					Return(db.ScheduledTransfer{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "Pause",
+			method:    http.MethodPatch,
+			body:      updateScheduledTransferRequest{Status: &paused.String},
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					UpdateScheduledTransfer(gomock.Any(), gomock.Eq(db.UpdateScheduledTransferParams{ID: scheduled.ID, Status: paused})).
+					Times(1).
+					Return(scheduled, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidStatus",
+			method:    http.MethodPatch,
+			body:      map[string]string{"status": "cancelled"},
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "Delete",
+			method:    http.MethodDelete,
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().DeleteScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:      "AuditorCannotDelete",
+			method:    http.MethodDelete,
+			setupAuth: addSessionAuthorization(util.RandomOwner(), util.AuditorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().DeleteScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			body := &bytes.Buffer{}
+			if tc.body != nil {
+				require.NoError(t, json.NewEncoder(body).Encode(tc.body))
+			}
+			url := fmt.Sprintf("/scheduled-transfers/%d", scheduled.ID)
+			request, err := http.NewRequest(tc.method, url, body)
+			require.NoError(t, err)
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -3,6 +3,8 @@ package api
 import (
 	"fmt"
 	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/fx"
+	"github.com/fayca121/simplebank/pagination"
 	"github.com/fayca121/simplebank/token"
 	"github.com/fayca121/simplebank/util"
 	"github.com/gin-gonic/gin"
@@ -11,10 +13,13 @@ import (
 )
 
 type Server struct {
-	config     util.Config
-	store      db.Store
-	tokenMaker token.Maker
-	router     *gin.Engine
+	config      util.Config
+	store       db.Store
+	tokenMaker  token.Maker
+	rates       fx.RateProvider
+	revocations token.RevocationStore
+	pages       *pagination.Signer
+	router      *gin.Engine
 }
 
 func NewServer(config util.Config, store db.Store) (*Server, error) {
@@ -22,29 +27,82 @@ func NewServer(config util.Config, store db.Store) (*Server, error) {
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker")
 	}
+	rates, err := fx.NewRateProvider(config.ExchangeRatesFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create rate provider: %w", err)
+	}
+	revocations, err := db.NewRevocationStore(config.TokenRevocationStore, store)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token revocation store: %w", err)
+	}
+	pages, err := pagination.NewSigner(config.PageTokenKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create page token signer: %w", err)
+	}
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
+		config:      config,
+		store:       store,
+		tokenMaker:  tokenMaker,
+		rates:       rates,
+		revocations: revocations,
+		pages:       pages,
 	}
 	//add routes to router
 	router := gin.Default()
+	// the handlers pass the *gin.Context to the store, it must carry the actor set on the request
+	router.ContextWithFallback = true
+	router.Use(actorMiddleware())
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		_ = v.RegisterValidation("currency", validCurrency)
+		_ = v.RegisterValidation("schedule", validSchedule)
 	}
-	accountGrp := router.Group("/accounts").Use(authMiddleware(tokenMaker))
+	accountGrp := router.Group("/accounts").Use(authMiddleware(tokenMaker, revocations))
 	{
 		accountGrp.POST("/", server.createAccount)
 		accountGrp.GET("/:id", server.getAccount)
 		accountGrp.GET("/", server.listAccount)
-		accountGrp.PUT("/", server.updateAccount)
 		accountGrp.DELETE("/:id", server.deleteAccount)
+		accountGrp.GET("/:id/statement", server.generateStatement)
+		accountGrp.GET("/:id/entries", server.listEntries)
+		accountGrp.GET("/:id/transfers", server.listTransfers)
+		accountGrp.GET("/:id/scheduled-transfers", server.listScheduledTransfers)
+		accountGrp.POST("/:id/deposit", server.deposit)
+		accountGrp.POST("/:id/withdraw", server.withdraw)
+		accountGrp.POST("/:id/status", server.updateAccountStatus)
+		accountGrp.POST("/:id/overdraft-limit", server.updateOverdraftLimit)
+	}
+
+	transferGrp := router.Group("/transfers").Use(authMiddleware(tokenMaker, revocations))
+	{
+		transferGrp.POST("", server.createTransfer)
+		transferGrp.POST("/:id/reverse", server.reverseTransfer)
+		transferGrp.POST("/authorize", server.authorizeTransfer)
+		transferGrp.POST("/:id/capture", server.captureTransfer)
+		transferGrp.POST("/:id/void", server.voidTransfer)
+	}
+
+	scheduledTransferGrp := router.Group("/scheduled-transfers").Use(authMiddleware(tokenMaker, revocations))
+	{
+		scheduledTransferGrp.POST("", server.createScheduledTransfer)
+		scheduledTransferGrp.GET("/:id", server.getScheduledTransfer)
+		scheduledTransferGrp.PATCH("/:id", server.updateScheduledTransfer)
+		scheduledTransferGrp.DELETE("/:id", server.deleteScheduledTransfer)
 	}
 
-	router.POST("/transfers", server.createTransfer)
+	sessionGrp := router.Group("/sessions").Use(authMiddleware(tokenMaker, revocations))
+	{
+		sessionGrp.GET("", server.listSessions)
+		sessionGrp.DELETE("/:id", server.revokeSession)
+	}
+
+	router.GET("/audit-events", authMiddleware(tokenMaker, revocations), server.listAuditEvents)
+
 	router.POST("/users", server.createUser)
 	router.POST("/users/login", server.loginUser)
+	router.POST("/users/logout", server.logoutUser)
+	router.GET("/users/verify-email", server.verifyEmail)
+	router.DELETE("/users/:username/sessions", authMiddleware(tokenMaker, revocations), server.revokeUserSessions)
 	router.POST("/tokens/renew_access", server.renewAccessToken)
 	server.router = router
 	return server, nil
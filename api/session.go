@@ -0,0 +1,228 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pagination"
+	"github.com/fayca121/simplebank/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"time"
+)
+
+// sessionResponse is a session without its refresh token
+type sessionResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	IsBlocked bool      `json:"is_blocked"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newSessionResponse(session db.Session) sessionResponse {
+	return sessionResponse{
+		ID:        session.ID,
+		Username:  session.Username,
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		IsBlocked: session.IsBlocked,
+		ExpiresAt: session.ExpiresAt,
+		CreatedAt: session.CreatedAt,
+	}
+}
+
+// ------------------ Logout User ------------------------
+type logoutUserRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+func (server *Server) logoutUser(ctx *gin.Context) {
+	var req logoutUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	id, err := uuid.Parse(refreshPayload.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	session, valid := server.findSession(ctx, id)
+	if !valid {
+		return
+	}
+
+	if session.Username != refreshPayload.Username || session.RefreshToken != req.RefreshToken {
+		err := fmt.Errorf("mismatched session token")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if !session.IsBlocked {
+		if _, err = server.store.BlockSession(ctx, session.ID); err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	// the access token sent along the request, if any, is revoked as well
+	if accessToken, err := accessTokenFromHeader(ctx.GetHeader(authorizationHeaderKey)); err == nil {
+		payload, err := server.tokenMaker.VerifyToken(accessToken)
+		if err == nil && payload.Username == session.Username {
+			if err = server.revocations.RevokeToken(ctx, payload); err != nil {
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+		}
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ------------------ List Sessions ------------------------
+type listSessionsRequest struct {
+	// Username defaults to the authenticated user
+	Username  string `form:"username" binding:"omitempty,alphanum"`
+	PageSize  int32  `form:"page_size" binding:"required,min=5,max=100"`
+	PageToken string `form:"page_token"`
+}
+
+type listSessionsResponse struct {
+	Sessions      []sessionResponse `json:"sessions"`
+	NextPageToken string            `json:"next_page_token"`
+}
+
+func (server *Server) listSessions(ctx *gin.Context) {
+	var req listSessionsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	username := authPayload.Username
+	if req.Username != "" {
+		username = req.Username
+	}
+	if !authorize(ctx, authz.SessionRead, username) {
+		return
+	}
+
+	scope := pagination.SessionsScope(username)
+	afterCreatedAt, afterID, err := server.pages.UUIDKeyset(scope, req.PageToken)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	sessions, err := server.store.ListSessions(ctx, db.ListSessionsParams{
+		Username:       username,
+		AfterCreatedAt: afterCreatedAt,
+		AfterID:        afterID,
+		Limit:          req.PageSize + 1,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	sessions, next := pagination.NextPage(server.pages, scope, sessions, req.PageSize, func(session db.Session) pagination.Cursor {
+		return pagination.NewUUIDCursor(session.CreatedAt, session.ID)
+	})
+	resp := listSessionsResponse{
+		Sessions:      make([]sessionResponse, 0, len(sessions)),
+		NextPageToken: next,
+	}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, newSessionResponse(session))
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// ------------------ Revoke Session ------------------------
+type revokeSessionRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+func (server *Server) revokeSession(ctx *gin.Context) {
+	var req revokeSessionRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	session, valid := server.findSession(ctx, uuid.MustParse(req.ID))
+	if !valid {
+		return
+	}
+
+	if !authorize(ctx, authz.SessionRevoke, session.Username) {
+		return
+	}
+
+	session, err := server.store.BlockSession(ctx, session.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newSessionResponse(session))
+}
+
+// ------------------ Revoke User Sessions ------------------------
+type revokeUserSessionsRequest struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+type revokeUserSessionsResponse struct {
+	RevokedSessions int64 `json:"revoked_sessions"`
+}
+
+func (server *Server) revokeUserSessions(ctx *gin.Context) {
+	var req revokeUserSessionsRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !authorize(ctx, authz.SessionRevoke, req.Username) {
+		return
+	}
+
+	revoked, err := server.store.BlockUserSessions(ctx, req.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// the access tokens already handed out by these sessions are denied as well
+	if err = server.revocations.RevokeUserTokens(ctx, req.Username, time.Now()); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, revokeUserSessionsResponse{RevokedSessions: revoked})
+}
+
+func (server *Server) findSession(ctx *gin.Context, id uuid.UUID) (db.Session, bool) {
+	session, err := server.store.GetSession(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return session, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return session, false
+	}
+	return session, true
+}
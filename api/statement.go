@@ -0,0 +1,62 @@
+package api
+
+import (
+	"fmt"
+	"github.com/fayca121/simplebank/authz"
+	"github.com/fayca121/simplebank/statement"
+	"github.com/fayca121/simplebank/val"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// ------------------ Generate Statement ------------------------
+type generateStatementRequest struct {
+	From   time.Time `form:"from" binding:"required"`
+	To     time.Time `form:"to" binding:"required"`
+	Format string    `form:"format"`
+}
+
+func (server *Server) generateStatement(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req generateStatementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := val.ValidateStatementPeriod(req.From, req.To); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("period %w", err)))
+		return
+	}
+	format, err := statement.ParseFormat(req.Format)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.findAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+	if !authorize(ctx, authz.AccountRead, account.Owner) {
+		return
+	}
+
+	generated, err := statement.Generate(ctx, server.store, account, req.From, req.To)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Header("Content-Type", format.ContentType())
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", generated.Filename(format)))
+	ctx.Status(http.StatusOK)
+	if err = statement.Write(ctx.Writer, generated, format); err != nil {
+		// the headers are sent, the client sees a truncated statement
+		_ = ctx.Error(err)
+	}
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	mockdb "github.com/fayca121/simplebank/db/mock"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/token"
+	"github.com/fayca121/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestGenerateStatementAPI(t *testing.T) {
+	user := randomUser()
+	account := randomAccount(user.Username)
+	from := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	entries := []db.Entry{
+		{ID: 1, AccountID: account.ID, Amount: 500, CreatedAt: from.Add(time.Hour)},
+		{ID: 2, AccountID: account.ID, Amount: -200, CreatedAt: from.Add(2 * time.Hour)},
+	}
+
+	buildGenerated := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+		store.EXPECT().
+			GetOpeningBalance(gomock.Any(), gomock.Eq(db.GetOpeningBalanceParams{Since: from, AccountID: account.ID})).
+			Times(1).
+			Return(int64(1000), nil)
+		store.EXPECT().
+			ListEntriesBetween(gomock.Any(), gomock.Eq(db.ListEntriesBetweenParams{AccountID: account.ID, FromTime: from, ToTime: to})).
+			Times(1).
+			Return(entries, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "CSV",
+			query:     url.Values{"from": {from.Format(time.RFC3339)}, "to": {to.Format(time.RFC3339)}},
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: buildGenerated,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Body.String(), "closing balance,,13.00,"+account.Currency)
+			},
+		},
+		{
+			name: "PDF",
+			query: url.Values{"from": {from.Format(time.RFC3339)}, "to": {to.Format(time.RFC3339)},
+				"format": {"pdf"}},
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: buildGenerated,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/pdf", recorder.Header().Get("Content-Type"))
+				require.True(t, bytes.HasPrefix(recorder.Body.Bytes(), []byte("%PDF")))
+			},
+		},
+		{
+			name:      "AuditorOtherAccount",
+			query:     url.Values{"from": {from.Format(time.RFC3339)}, "to": {to.Format(time.RFC3339)}},
+			setupAuth: addSessionAuthorization(util.RandomOwner(), util.AuditorRole),
+			buildStub: buildGenerated,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "UnauthorizedUser",
+			query:     url.Values{"from": {from.Format(time.RFC3339)}, "to": {to.Format(time.RFC3339)}},
+			setupAuth: addSessionAuthorization(util.RandomOwner(), util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetOpeningBalance(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			query:     url.Values{"from": {from.Format(time.RFC3339)}, "to": {to.Format(time.RFC3339)}},
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidPeriod",
+			query:     url.Values{"from": {to.Format(time.RFC3339)}, "to": {from.Format(time.RFC3339)}},
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnsupportedFormat",
+			query: url.Values{"from": {from.Format(time.RFC3339)}, "to": {to.Format(time.RFC3339)},
+				"format": {"xlsx"}},
+			setupAuth: addSessionAuthorization(user.Username, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			path := fmt.Sprintf("/accounts/%d/statement?%s", account.ID, tc.query.Encode())
+			request, err := http.NewRequest(http.MethodGet, path, nil)
+			require.NoError(t, err)
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
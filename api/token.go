@@ -3,6 +3,7 @@ package api
 import (
 	"errors"
 	"fmt"
+	db "github.com/fayca121/simplebank/db/sqlc"
 	"github.com/fayca121/simplebank/util"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -16,10 +17,15 @@ type renewAccessTokenRequest struct {
 }
 
 type renewAccessTokenResponse struct {
-	AccessToken          string    `json:"access_token"`
-	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	SessionID             uuid.UUID `json:"session_id"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
 }
 
+// renewAccessToken rotates the refresh token: the presented token can't be used again and a new one
+// is returned with the access token. Presenting a rotated token revokes every session of its family.
 func (server *Server) renewAccessToken(ctx *gin.Context) {
 	var req renewAccessTokenRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -84,9 +90,50 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
+	refreshToken, newRefreshPayload, err := server.tokenMaker.CreateToken(session.Username,
+		util.Role(refreshPayload.Role),
+		server.config.RefreshTokenDuration)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	newID, err := uuid.Parse(newRefreshPayload.ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	newSession, err := server.store.RotateSessionTx(ctx, db.RotateSessionTxParam{
+		SessionID: session.ID,
+		NewSession: db.CreateSessionParams{
+			ID:           newID,
+			Username:     session.Username,
+			RefreshToken: refreshToken,
+			UserAgent:    ctx.Request.UserAgent(),
+			ClientIp:     ctx.ClientIP(),
+			IsBlocked:    false,
+			ExpiresAt:    newRefreshPayload.ExpiredAt,
+		},
+	})
+
+	if err != nil {
+		if errors.Is(err, db.ErrRefreshTokenReused) {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
 	resp := renewAccessTokenResponse{
-		AccessToken:          accessToken,
-		AccessTokenExpiresAt: accessPayload.ExpiredAt,
+		SessionID:             newSession.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: newRefreshPayload.ExpiredAt,
 	}
 
 	ctx.JSON(http.StatusOK, resp)
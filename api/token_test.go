@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	mockdb "github.com/fayca121/simplebank/db/mock"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRenewAccessTokenAPI(t *testing.T) {
+	user := randomUser()
+
+	testCases := []struct {
+		name          string
+		buildStub     func(store *mockdb.MockStore, session db.Session)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session)
+	}{
+		{
+			name: "OK",
+			buildStub: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.RotateSessionTxParam) (db.Session, error) {
+						require.Equal(t, session.ID, arg.SessionID)
+						require.Equal(t, session.Username, arg.NewSession.Username)
+						require.NotEqual(t, session.RefreshToken, arg.NewSession.RefreshToken)
+						newSession := db.Session{
+							ID:           arg.NewSession.ID,
+							Username:     arg.NewSession.Username,
+							RefreshToken: arg.NewSession.RefreshToken,
+							ExpiresAt:    arg.NewSession.ExpiresAt,
+							FamilyID:     session.FamilyID,
+						}
+						return newSession, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var resp renewAccessTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.NotEmpty(t, resp.AccessToken)
+				require.NotEmpty(t, resp.RefreshToken)
+				require.NotEqual(t, session.RefreshToken, resp.RefreshToken)
+				require.NotEqual(t, session.ID, resp.SessionID)
+			},
+		},
+		{
+			name: "RefreshTokenReused",
+			buildStub: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.Session{}, db.ErrRefreshTokenReused)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "BlockedSession",
+			buildStub: func(store *mockdb.MockStore, session db.Session) {
+				session.IsBlocked = true
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "MismatchedToken",
+			buildStub: func(store *mockdb.MockStore, session db.Session) {
+				session.RefreshToken = util.RandomString(32)
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStub: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			server := NewTestServer(t, store)
+
+			refreshToken, payload, err := server.tokenMaker.CreateToken(user.Username, util.DepositorRole, time.Minute)
+			require.NoError(t, err)
+			id := uuid.MustParse(payload.ID)
+			session := db.Session{
+				ID:           id,
+				Username:     user.Username,
+				RefreshToken: refreshToken,
+				ExpiresAt:    payload.ExpiredAt,
+				FamilyID:     id,
+			}
+			tc.buildStub(store, session)
+
+			recorder := httptest.NewRecorder()
+			body, err := json.Marshal(renewAccessTokenRequest{RefreshToken: refreshToken})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/tokens/renew_access", bytes.NewReader(body))
+			require.NoError(t, err)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, session)
+		})
+	}
+}
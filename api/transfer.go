@@ -3,15 +3,22 @@ package api
 import (
 	"errors"
 	"fmt"
+	"github.com/fayca121/simplebank/authz"
 	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/fx"
+	"github.com/fayca121/simplebank/pagination"
 	"github.com/fayca121/simplebank/token"
-	"github.com/fayca121/simplebank/util"
+	"github.com/fayca121/simplebank/val"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
+	"io"
 	"net/http"
+	"time"
 )
 
 // ------------------ Create Transfer ------------------------
+const idempotencyKeyHeader = "Idempotency-Key"
+
 type transferRequest struct {
 	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
 	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
@@ -34,32 +41,70 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
-	if authPayload.Username != fromAccount.Owner && authPayload.Role != util.BankerRole.String() {
-		err := errors.New("")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if !authorize(ctx, authz.TransferCreate, fromAccount.Owner) {
 		return
 	}
-	_, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
+	// the destination account may hold another currency, the amount is then converted
+	toAccount, valid := server.findAccount(ctx, req.ToAccountID)
 
 	if !valid {
 		return
 	}
 
+	idempotencyKey := ctx.GetHeader(idempotencyKeyHeader)
+	if err := val.ValidateIdempotencyKey(idempotencyKey); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("%s header %s", idempotencyKeyHeader, err)))
+		return
+	}
+
 	arg := db.TransferTxParam{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
-		Amount:        req.Amount,
+		FromAccountID:  req.FromAccountID,
+		ToAccountID:    req.ToAccountID,
+		Amount:         req.Amount,
+		Username:       authPayload.Username,
+		IdempotencyKey: idempotencyKey,
 	}
 
-	result, err := server.store.TransferTx(ctx, arg)
+	var result db.TransferTxResult
+	var err error
+	if toAccount.Currency == fromAccount.Currency {
+		result, err = server.store.TransferTx(ctx, arg)
+	} else {
+		rate, rateErr := server.rates.Rate(ctx, fromAccount.Currency, toAccount.Currency)
+		if rateErr != nil {
+			if errors.Is(rateErr, fx.ErrRateNotFound) {
+				ctx.JSON(http.StatusBadRequest, errorResponse(rateErr))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(rateErr))
+			return
+		}
+		result, err = server.store.CrossCurrencyTransferTx(ctx, db.CrossCurrencyTransferTxParam{
+			TransferTxParam: arg,
+			Rate:            rate,
+		})
+	}
 	if err != nil {
+		if errors.Is(err, fx.ErrAmountTooSmall) || errors.Is(err, db.ErrCashAccount) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountFrozen) ||
+			errors.Is(err, db.ErrAccountClosed) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrIdempotencyKeyConflict) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 	ctx.JSON(http.StatusCreated, result)
 }
 
-func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
+func (server *Server) findAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
 	account, err := server.store.GetAccount(ctx, accountID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -69,10 +114,266 @@ func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency s
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return account, false
 	}
+	return account, true
+}
+
+func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
+	account, valid := server.findAccount(ctx, accountID)
+	if !valid {
+		return account, false
+	}
 	if account.Currency != currency {
-		err = fmt.Errorf("account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, currency)
+		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, currency)
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return account, false
 	}
 	return account, true
 }
+
+type transferUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// ----------------------- List Transfers ---------------------
+type listTransfersRequest struct {
+	PageSize  int32  `form:"page_size" binding:"required,min=5,max=100"`
+	PageToken string `form:"page_token"`
+	listFilterRequest
+}
+
+type listTransfersResponse struct {
+	Transfers     []db.Transfer `json:"transfers"`
+	NextPageToken string        `json:"next_page_token"`
+}
+
+func (server *Server) listTransfers(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req listTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	filter, err := req.filter()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.findAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+	if !authorize(ctx, authz.AccountRead, account.Owner) {
+		return
+	}
+
+	scope := pagination.TransfersScope(account.ID)
+	after, err := server.pages.Keyset(scope, req.PageToken)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := filter.TransfersParams(account.ID, after, req.PageSize+1)
+	transfers, err := server.store.ListTransfers(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	transfers, next := pagination.NextPage(server.pages, scope, transfers, req.PageSize, func(transfer db.Transfer) pagination.Cursor {
+		return pagination.NewCursor(transfer.CreatedAt, transfer.ID)
+	})
+	ctx.JSON(http.StatusOK, listTransfersResponse{Transfers: transfers, NextPageToken: next})
+}
+
+// ----------------------- Reverse Transfer ---------------------
+type reverseTransferRequest struct {
+	// Amount is optional, the whole amount not yet refunded by default
+	Amount int64 `json:"amount" binding:"min=0"`
+}
+
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	var uri transferUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req reverseTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	// the money goes back from the destination account, its owner may refund the transfer
+	toAccount, valid := server.findAccount(ctx, transfer.ToAccountID)
+	if !valid {
+		return
+	}
+	if !authorize(ctx, authz.TransferReverse, toAccount.Owner) {
+		return
+	}
+
+	result, err := server.store.ReverseTransferTx(ctx, db.ReverseTransferTxParam{
+		TransferID: transfer.ID,
+		Amount:     req.Amount,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrTransferNotReversible), errors.Is(err, db.ErrRefundTooLarge),
+			errors.Is(err, fx.ErrAmountTooSmall):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		case errors.Is(err, db.ErrTransferAlreadyReversed):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrAccountFrozen),
+			errors.Is(err, db.ErrAccountClosed):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, result)
+}
+
+// ----------------------- Authorize Transfer ---------------------
+type authorizeTransferRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+	// ExpiresAt is optional, the hold is released if the transfer isn't captured by then
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (server *Server) authorizeTransfer(ctx *gin.Context) {
+	var req authorizeTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	now := time.Now()
+	expiresAt := now.Add(db.DefaultHoldDuration)
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(now) || req.ExpiresAt.After(now.Add(db.MaxHoldDuration)) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("expires_at must be within %s", db.MaxHoldDuration)))
+			return
+		}
+		expiresAt = *req.ExpiresAt
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+	if !authorize(ctx, authz.TransferCreate, fromAccount.Owner) {
+		return
+	}
+	// the amount is captured later, without conversion, both accounts hold the same currency
+	if _, valid = server.validAccount(ctx, req.ToAccountID, req.Currency); !valid {
+		return
+	}
+
+	result, err := server.store.AuthorizeTransferTx(ctx, db.AuthorizeTransferTxParam{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		ExpiresAt:     expiresAt,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrCashAccount) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountFrozen) ||
+			errors.Is(err, db.ErrAccountClosed) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusCreated, result)
+}
+
+// ----------------------- Capture Transfer ---------------------
+func (server *Server) captureTransfer(ctx *gin.Context) {
+	transfer, valid := server.pendingTransfer(ctx)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.CaptureTransferTx(ctx, transfer.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrTransferNotPending), errors.Is(err, db.ErrHoldExpired):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrAccountFrozen),
+			errors.Is(err, db.ErrAccountClosed):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// ----------------------- Void Transfer ---------------------
+func (server *Server) voidTransfer(ctx *gin.Context) {
+	transfer, valid := server.pendingTransfer(ctx)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.VoidTransferTx(ctx, transfer.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrTransferNotPending) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// pendingTransfer loads the transfer of the uri, it may be captured or voided by the owner of its destination account
+func (server *Server) pendingTransfer(ctx *gin.Context) (db.Transfer, bool) {
+	var uri transferUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.Transfer{}, false
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return transfer, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return transfer, false
+	}
+	toAccount, valid := server.findAccount(ctx, transfer.ToAccountID)
+	if !valid {
+		return transfer, false
+	}
+	if !authorize(ctx, authz.TransferCapture, toAccount.Owner) {
+		return transfer, false
+	}
+	return transfer, true
+}
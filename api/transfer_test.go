@@ -0,0 +1,774 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/fayca121/simplebank/db/mock"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/fx"
+	"github.com/fayca121/simplebank/token"
+	"github.com/fayca121/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCreateTransferAPI(t *testing.T) {
+	user1 := randomUser()
+	user2 := randomUser()
+	amount := int64(10)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account2.Currency = account1.Currency
+	account3 := randomAccount(user2.Username)
+	for account3.Currency == account1.Currency {
+		account3.Currency = util.RandomCurrency()
+	}
+	account4 := randomAccount(user2.Username)
+	for account4.Currency == account1.Currency || account4.Currency == account3.Currency {
+		account4.Currency = util.RandomCurrency()
+	}
+	rate := fx.Rate{From: account1.Currency, To: account3.Currency, Value: 2 * fx.RateScale}
+
+	addAuthorization := func(username string) func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		return func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			generatedToken, payload, err := tokenMaker.CreateToken(username, util.DepositorRole, time.Minute)
+			require.NoError(t, err)
+			require.NotEmpty(t, payload)
+			authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, generatedToken)
+			request.Header.Add(authorizationHeaderKey, authorizationHeader)
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		request       transferRequest
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			request: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      account1.Currency,
+			},
+			setupAuth: addAuthorization(user1.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				arg := db.TransferTxParam{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+					Username:      user1.Username,
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			request: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      account1.Currency,
+			},
+			setupAuth: addAuthorization(user2.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			request: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			request: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      account3.Currency,
+			},
+			setupAuth: addAuthorization(user1.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "CrossCurrency",
+			request: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account3.ID,
+				Amount:        amount,
+				Currency:      account1.Currency,
+			},
+			setupAuth: addAuthorization(user1.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				arg := db.CrossCurrencyTransferTxParam{
+					TransferTxParam: db.TransferTxParam{
+						FromAccountID: account1.ID,
+						ToAccountID:   account3.ID,
+						Amount:        amount,
+						Username:      user1.Username,
+					},
+					Rate: rate,
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CrossCurrencyTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "UnsupportedCurrencyPair",
+			request: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account4.ID,
+				Amount:        amount,
+				Currency:      account1.Currency,
+			},
+			setupAuth: addAuthorization(user1.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account4.ID)).Times(1).Return(account4, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CrossCurrencyTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			request: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      account1.Currency,
+			},
+			setupAuth: addAuthorization(user1.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferTxResult{}, fmt.Errorf("%w: account [%d]", db.ErrInsufficientFunds, account1.ID))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "CashAccount",
+			request: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      account1.Currency,
+			},
+			setupAuth: addAuthorization(user1.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferTxResult{}, fmt.Errorf("%w: account [%d]", db.ErrCashAccount, account2.ID))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "IdempotencyKeyConflict",
+			request: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(user1.Username)(t, request, tokenMaker)
+				request.Header.Set(idempotencyKeyHeader, "retry-key")
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				arg := db.TransferTxParam{
+					FromAccountID:  account1.ID,
+					ToAccountID:    account2.ID,
+					Amount:         amount,
+					Username:       user1.Username,
+					IdempotencyKey: "retry-key",
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.TransferTxResult{}, db.ErrIdempotencyKeyConflict)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			request: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      account1.Currency,
+			},
+			setupAuth: addAuthorization(user1.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+			// start test server and send request
+			server := NewTestServer(t, store)
+			server.rates = fx.NewStaticProvider(rate)
+			recorder := httptest.NewRecorder()
+			body, err := json.Marshal(tc.request)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(body))
+			require.NoError(t, err)
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListTransfersAPI(t *testing.T) {
+	user := randomUser()
+	account := randomAccount(user.Username)
+	counterparty := randomAccount(util.RandomOwner())
+	transfers := []db.Transfer{{ID: 1, FromAccountID: account.ID, ToAccountID: counterparty.ID, Amount: 10}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	arg := db.ListTransfersParams{
+		AccountID:      account.ID,
+		Direction:      db.DirectionOutgoing,
+		CounterpartyID: pgtype.Int8{Int64: counterparty.ID, Valid: true},
+		Limit:          6,
+	}
+	store.EXPECT().ListTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return(transfers, nil)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+	url := fmt.Sprintf("/accounts/%d/transfers?page_size=5&direction=outgoing&counterparty_account_id=%d",
+		account.ID, counterparty.ID)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	addSessionAuthorization(user.Username, util.DepositorRole)(t, request, server.tokenMaker)
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var listed listTransfersResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &listed))
+	require.Equal(t, transfers, listed.Transfers)
+	require.Empty(t, listed.NextPageToken)
+}
+
+func TestReverseTransferAPI(t *testing.T) {
+	payer := randomAccount(util.RandomOwner())
+	payee := randomAccount(util.RandomOwner())
+	payee.Currency = payer.Currency
+	original := db.Transfer{ID: 7, FromAccountID: payer.ID, ToAccountID: payee.ID, Amount: 100, ToAmount: 100, Kind: db.TransferKindTransfer}
+	result := db.ReverseTransferTxResult{
+		TransferTxResult: db.TransferTxResult{
+			Transfer: db.Transfer{
+				ID: 8, FromAccountID: payee.ID, ToAccountID: payer.ID, Amount: 40, ToAmount: 40,
+				Kind: db.TransferKindReversal, ReversedTransferID: pgtype.Int8{Int64: original.ID, Valid: true},
+			},
+		},
+		OriginalTransfer: original,
+	}
+
+	testCases := []struct {
+		name          string
+		request       *reverseTransferRequest
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "PartialRefund",
+			request:   &reverseTransferRequest{Amount: 40},
+			setupAuth: addSessionAuthorization(payee.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(original.ID)).Times(1).Return(original, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Eq(db.ReverseTransferTxParam{TransferID: original.ID, Amount: 40})).
+					Times(1).
+					Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				var resp db.ReverseTransferTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, result.Transfer, resp.Transfer)
+				require.Equal(t, original.ID, resp.OriginalTransfer.ID)
+			},
+		},
+		{
+			name:      "FullRefundWithoutBody",
+			setupAuth: addSessionAuthorization(payee.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(original.ID)).Times(1).Return(original, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Eq(db.ReverseTransferTxParam{TransferID: original.ID})).
+					Times(1).
+					Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:      "PayerCannotRefund",
+			request:   &reverseTransferRequest{Amount: 40},
+			setupAuth: addSessionAuthorization(payer.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(original.ID)).Times(1).Return(original, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "BankerRefund",
+			setupAuth: addSessionAuthorization(util.RandomOwner(), util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(original.ID)).Times(1).Return(original, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:      "TransferNotFound",
+			setupAuth: addSessionAuthorization(payee.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(original.ID)).Times(1).Return(db.Transfer{}, pgx.ErrNoRows)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "AlreadyReversed",
+			setupAuth: addSessionAuthorization(payee.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(original.ID)).Times(1).Return(original, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrTransferAlreadyReversed)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "RefundTooLarge",
+			request:   &reverseTransferRequest{Amount: 500},
+			setupAuth: addSessionAuthorization(payee.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(original.ID)).Times(1).Return(original, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrRefundTooLarge)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "NegativeAmount",
+			request:   &reverseTransferRequest{Amount: -1},
+			setupAuth: addSessionAuthorization(payee.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			body := &bytes.Buffer{}
+			if tc.request != nil {
+				require.NoError(t, json.NewEncoder(body).Encode(tc.request))
+			}
+			url := fmt.Sprintf("/transfers/%d/reverse", original.ID)
+			request, err := http.NewRequest(http.MethodPost, url, body)
+			require.NoError(t, err)
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAuthorizeTransferAPI(t *testing.T) {
+	payer := randomAccount(util.RandomOwner())
+	payee := randomAccount(util.RandomOwner())
+	payee.Currency = payer.Currency
+	other := randomAccount(util.RandomOwner())
+	other.Currency = util.RandomCurrency()
+	for other.Currency == payer.Currency {
+		other.Currency = util.RandomCurrency()
+	}
+	pending := db.Transfer{ID: 9, FromAccountID: payer.ID, ToAccountID: payee.ID, Amount: 10, ToAmount: 10, Status: db.TransferStatusPending}
+	expiresAt := time.Now().Add(db.MaxHoldDuration + time.Hour)
+
+	testCases := []struct {
+		name          string
+		request       authorizeTransferRequest
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "DefaultExpiry",
+			request:   authorizeTransferRequest{FromAccountID: payer.ID, ToAccountID: payee.ID, Amount: 10, Currency: payer.Currency},
+			setupAuth: addSessionAuthorization(payer.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payer.ID)).Times(1).Return(payer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().AuthorizeTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.AuthorizeTransferTxParam) (db.HoldTxResult, error) {
+						require.Equal(t, int64(10), arg.Amount)
+						require.WithinDuration(t, time.Now().Add(db.DefaultHoldDuration), arg.ExpiresAt, time.Minute)
+						return db.HoldTxResult{Transfer: pending}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				var resp db.HoldTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, db.TransferStatusPending, resp.Transfer.Status)
+			},
+		},
+		{
+			name: "ExpiryTooFar",
+			request: authorizeTransferRequest{FromAccountID: payer.ID, ToAccountID: payee.ID, Amount: 10, Currency: payer.Currency,
+				ExpiresAt: &expiresAt},
+			setupAuth: addSessionAuthorization(payer.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().AuthorizeTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "CurrencyMismatch",
+			request:   authorizeTransferRequest{FromAccountID: payer.ID, ToAccountID: other.ID, Amount: 10, Currency: payer.Currency},
+			setupAuth: addSessionAuthorization(payer.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payer.ID)).Times(1).Return(payer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(other.ID)).Times(1).Return(other, nil)
+				store.EXPECT().AuthorizeTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "NotOwner",
+			request:   authorizeTransferRequest{FromAccountID: payer.ID, ToAccountID: payee.ID, Amount: 10, Currency: payer.Currency},
+			setupAuth: addSessionAuthorization(payee.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payer.ID)).Times(1).Return(payer, nil)
+				store.EXPECT().AuthorizeTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "InsufficientFunds",
+			request:   authorizeTransferRequest{FromAccountID: payer.ID, ToAccountID: payee.ID, Amount: 10, Currency: payer.Currency},
+			setupAuth: addSessionAuthorization(payer.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payer.ID)).Times(1).Return(payer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().AuthorizeTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.HoldTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:      "CashAccount",
+			request:   authorizeTransferRequest{FromAccountID: payer.ID, ToAccountID: payee.ID, Amount: 10, Currency: payer.Currency},
+			setupAuth: addSessionAuthorization(payer.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payer.ID)).Times(1).Return(payer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().AuthorizeTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.HoldTxResult{}, db.ErrCashAccount)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			data, err := json.Marshal(tc.request)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/transfers/authorize", bytes.NewReader(data))
+			require.NoError(t, err)
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCaptureAndVoidTransferAPI(t *testing.T) {
+	payer := randomAccount(util.RandomOwner())
+	payee := randomAccount(util.RandomOwner())
+	pending := db.Transfer{ID: 9, FromAccountID: payer.ID, ToAccountID: payee.ID, Amount: 10, ToAmount: 10, Status: db.TransferStatusPending}
+
+	testCases := []struct {
+		name          string
+		action        string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Capture",
+			action:    "capture",
+			setupAuth: addSessionAuthorization(payee.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				posted := pending
+				posted.Status = db.TransferStatusPosted
+				store.EXPECT().CaptureTransferTx(gomock.Any(), gomock.Eq(pending.ID)).Times(1).
+					Return(db.TransferTxResult{Transfer: posted}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var resp db.TransferTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, db.TransferStatusPosted, resp.Transfer.Status)
+			},
+		},
+		{
+			name:      "CaptureExpired",
+			action:    "capture",
+			setupAuth: addSessionAuthorization(payee.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().CaptureTransferTx(gomock.Any(), gomock.Eq(pending.ID)).Times(1).
+					Return(db.TransferTxResult{}, db.ErrHoldExpired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "PayerCannotCapture",
+			action:    "capture",
+			setupAuth: addSessionAuthorization(payer.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().CaptureTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "BankerCapture",
+			action:    "capture",
+			setupAuth: addSessionAuthorization(util.RandomOwner(), util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().CaptureTransferTx(gomock.Any(), gomock.Eq(pending.ID)).Times(1).
+					Return(db.TransferTxResult{Transfer: pending}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "Void",
+			action:    "void",
+			setupAuth: addSessionAuthorization(payee.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				voided := pending
+				voided.Status = db.TransferStatusVoided
+				store.EXPECT().VoidTransferTx(gomock.Any(), gomock.Eq(pending.ID)).Times(1).
+					Return(db.HoldTxResult{Transfer: voided}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "PayerCannotVoid",
+			action:    "void",
+			setupAuth: addSessionAuthorization(payer.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().VoidTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "BankerVoid",
+			action:    "void",
+			setupAuth: addSessionAuthorization(util.RandomOwner(), util.BankerRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().VoidTransferTx(gomock.Any(), gomock.Eq(pending.ID)).Times(1).
+					Return(db.HoldTxResult{Transfer: pending}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "VoidNotPending",
+			action:    "void",
+			setupAuth: addSessionAuthorization(payee.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().VoidTransferTx(gomock.Any(), gomock.Eq(pending.ID)).Times(1).
+					Return(db.HoldTxResult{}, db.ErrTransferNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "TransferNotFound",
+			action:    "void",
+			setupAuth: addSessionAuthorization(payee.Owner, util.DepositorRole),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(db.Transfer{}, pgx.ErrNoRows)
+				store.EXPECT().VoidTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/transfers/%d/%s", pending.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -20,12 +20,24 @@ type createUserRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 }
 
-type createUserResponse struct {
+type userResponse struct {
 	Username          string    `json:"username"`
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	IsEmailVerified   bool      `json:"is_email_verified"`
+}
+
+func newUserResponse(user db.User) userResponse {
+	return userResponse{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: user.PasswordChangedAt,
+		CreatedAt:         user.CreatedAt,
+		IsEmailVerified:   user.IsEmailVerified,
+	}
 }
 
 func (server *Server) createUser(ctx *gin.Context) {
@@ -59,14 +71,36 @@ func (server *Server) createUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
-	response := createUserResponse{
-		Username:          user.Username,
-		FullName:          user.FullName,
-		Email:             user.Email,
-		PasswordChangedAt: user.PasswordChangedAt,
-		CreatedAt:         user.CreatedAt,
+	ctx.JSON(http.StatusCreated, newUserResponse(user))
+}
+
+// ------------------ Verify Email ------------------------
+type verifyEmailRequest struct {
+	EmailID    int64  `form:"email_id" binding:"required,min=1"`
+	SecretCode string `form:"secret_code" binding:"required,min=32,max=128"`
+}
+
+// verifyEmail uses the code of the link sent to the user to verify its email
+func (server *Server) verifyEmail(ctx *gin.Context) {
+	var req verifyEmailRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.VerifyEmailTx(ctx, db.VerifyEmailTxParam{
+		EmailID:    req.EmailID,
+		SecretCode: req.SecretCode,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidVerifyEmail) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(db.ErrInvalidVerifyEmail))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
 	}
-	ctx.JSON(http.StatusCreated, response)
+	ctx.JSON(http.StatusOK, newUserResponse(result.User))
 }
 
 // ------------------ Login User ------------------------
@@ -131,6 +165,7 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		ClientIp:     ctx.ClientIP(),
 		IsBlocked:    false,
 		ExpiresAt:    refreshTokenPayload.ExpiredAt,
+		FamilyID:     id,
 	})
 
 	if err != nil {
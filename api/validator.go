@@ -1,6 +1,7 @@
 package api
 
 import (
+	"github.com/fayca121/simplebank/schedule"
 	"github.com/fayca121/simplebank/util"
 	"github.com/go-playground/validator/v10"
 )
@@ -11,3 +12,11 @@ var validCurrency validator.Func = func(fl validator.FieldLevel) bool {
 	}
 	return false
 }
+
+var validSchedule validator.Func = func(fl validator.FieldLevel) bool {
+	if spec, ok := fl.Field().Interface().(string); ok {
+		_, err := schedule.Parse(spec)
+		return err == nil && len(spec) <= 100
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package authz
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fayca121/simplebank/token"
+	"github.com/fayca121/simplebank/util"
+	"sync"
+)
+
+// Permission names an action, a role holding it may perform the action on its own resources.
+// The ":any" variant of a permission extends it to the resources of every user.
+type Permission string
+
+const (
+	AccountCreate Permission = "account:create"
+	AccountRead   Permission = "account:read"
+	AccountDelete Permission = "account:delete"
+	// AccountDeposit and AccountWithdraw move cash in or out of an account
+	AccountDeposit  Permission = "account:deposit"
+	AccountWithdraw Permission = "account:withdraw"
+	// AccountStatus freezes, closes and reopens an account
+	AccountStatus Permission = "account:status"
+	// AccountOverdraft sets the overdraft limit of an account, only its :any variant is granted
+	AccountOverdraft Permission = "account:overdraft"
+	TransferCreate   Permission = "transfer:create"
+	// TransferReverse is checked against the owner of the destination account of the transfer
+	TransferReverse Permission = "transfer:reverse"
+	// TransferCapture captures or voids a pending transfer, it is checked against the owner of its
+	// destination account
+	TransferCapture Permission = "transfer:capture"
+	UserUpdate      Permission = "user:update"
+	SessionRead     Permission = "session:read"
+	SessionRevoke   Permission = "session:revoke"
+	// AuditRead lists the audit events, only its :any variant is granted
+	AuditRead Permission = "audit:read"
+)
+
+func (permission Permission) Any() Permission {
+	return permission + ":any"
+}
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Registry maps each role to the permissions it holds
+type Registry struct {
+	mu    sync.RWMutex
+	roles map[string]map[Permission]bool
+}
+
+func NewRegistry(grants map[util.Role][]Permission) *Registry {
+	registry := &Registry{}
+	registry.Load(grants)
+	return registry
+}
+
+// Load replaces the content of the registry
+func (registry *Registry) Load(grants map[util.Role][]Permission) {
+	roles := make(map[string]map[Permission]bool, len(grants))
+	for role, permissions := range grants {
+		held := make(map[Permission]bool, len(permissions))
+		for _, permission := range permissions {
+			held[permission] = true
+		}
+		roles[role.String()] = held
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.roles = roles
+}
+
+// Can reports whether the role holds the permission
+func (registry *Registry) Can(role string, permission Permission) bool {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return registry.roles[role][permission]
+}
+
+// Holds reports whether the role holds the permission or its :any variant, that is whether it may
+// perform the action on some resources
+func (registry *Registry) Holds(role string, permission Permission) bool {
+	return registry.Can(role, permission) || registry.Can(role, permission.Any())
+}
+
+// Authorize checks that the authenticated user may perform the action on a resource belonging
+// to owner: either its role holds the ":any" variant of the permission, or it holds the
+// permission and owns the resource. Errors wrap ErrPermissionDenied.
+func (registry *Registry) Authorize(payload *token.Payload, permission Permission, owner string) error {
+	if registry.Can(payload.Role, permission.Any()) {
+		return nil
+	}
+	if !registry.Can(payload.Role, permission) {
+		return fmt.Errorf("%w: role %s doesn't hold %s", ErrPermissionDenied, payload.Role, permission)
+	}
+	if owner != payload.Username {
+		return fmt.Errorf("%w: %s on another user requires %s", ErrPermissionDenied, permission, permission.Any())
+	}
+	return nil
+}
+
+// selfService are the permissions every role holds on its own resources
+var selfService = []Permission{UserUpdate, SessionRead, SessionRevoke}
+
+// Roles is the registry used by the handlers, it holds the built-in grants until
+// LoadRoles is called with the content of the database
+var Roles = NewRegistry(map[util.Role][]Permission{
+	util.DepositorRole: append([]Permission{AccountCreate, AccountRead, TransferCreate, TransferReverse, TransferCapture}, selfService...),
+	util.BankerRole: append([]Permission{
+		AccountCreate.Any(), AccountRead.Any(), AccountDelete.Any(), AccountDeposit.Any(), AccountWithdraw.Any(), AccountStatus.Any(),
+		AccountOverdraft.Any(), TransferCreate.Any(), TransferReverse.Any(), TransferCapture.Any(), UserUpdate.Any(),
+		SessionRead.Any(), SessionRevoke.Any(), AuditRead.Any(),
+	}, selfService...),
+	util.AuditorRole: append([]Permission{AccountRead.Any()}, selfService...),
+	util.SupportRole: append([]Permission{AccountRead.Any(), SessionRead.Any()}, selfService...),
+})
+
+func LoadRoles(grants map[util.Role][]Permission) {
+	Roles.Load(grants)
+}
+
+func Holds(role string, permission Permission) bool {
+	return Roles.Holds(role, permission)
+}
+
+func Authorize(payload *token.Payload, permission Permission, owner string) error {
+	return Roles.Authorize(payload, permission, owner)
+}
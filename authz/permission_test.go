@@ -0,0 +1,91 @@
+package authz
+
+import (
+	"github.com/fayca121/simplebank/token"
+	"github.com/fayca121/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newPayload(t *testing.T, role util.Role) *token.Payload {
+	payload, err := token.NewPayLoad(util.RandomOwner(), role, time.Minute)
+	require.NoError(t, err)
+	return payload
+}
+
+func TestAuthorize(t *testing.T) {
+	testCases := []struct {
+		name       string
+		role       util.Role
+		permission Permission
+		own        bool
+		allowed    bool
+	}{
+		{name: "DepositorOwnAccount", role: util.DepositorRole, permission: AccountRead, own: true, allowed: true},
+		{name: "DepositorOtherAccount", role: util.DepositorRole, permission: AccountRead, own: false, allowed: false},
+		{name: "DepositorOwnTransfer", role: util.DepositorRole, permission: TransferCreate, own: true, allowed: true},
+		{name: "DepositorDeleteOwnAccount", role: util.DepositorRole, permission: AccountDelete, own: true, allowed: false},
+		{name: "BankerOtherAccount", role: util.BankerRole, permission: AccountDelete, own: false, allowed: true},
+		{name: "BankerOtherTransfer", role: util.BankerRole, permission: TransferCreate, own: false, allowed: true},
+		{name: "DepositorReverseReceivedTransfer", role: util.DepositorRole, permission: TransferReverse, own: true, allowed: true},
+		{name: "DepositorReverseOtherTransfer", role: util.DepositorRole, permission: TransferReverse, own: false, allowed: false},
+		{name: "BankerReverseOtherTransfer", role: util.BankerRole, permission: TransferReverse, own: false, allowed: true},
+		{name: "DepositorCaptureReceivedTransfer", role: util.DepositorRole, permission: TransferCapture, own: true, allowed: true},
+		{name: "DepositorCaptureOtherTransfer", role: util.DepositorRole, permission: TransferCapture, own: false, allowed: false},
+		{name: "BankerCaptureOtherTransfer", role: util.BankerRole, permission: TransferCapture, own: false, allowed: true},
+		{name: "DepositorOverdraftOwnAccount", role: util.DepositorRole, permission: AccountOverdraft, own: true, allowed: false},
+		{name: "BankerOverdraftOtherAccount", role: util.BankerRole, permission: AccountOverdraft, own: false, allowed: true},
+		{name: "AuditorReadOtherAccount", role: util.AuditorRole, permission: AccountRead, own: false, allowed: true},
+		{name: "AuditorTransfer", role: util.AuditorRole, permission: TransferCreate, own: true, allowed: false},
+		{name: "AuditorReadOtherSessions", role: util.AuditorRole, permission: SessionRead, own: false, allowed: false},
+		{name: "SupportReadOtherSessions", role: util.SupportRole, permission: SessionRead, own: false, allowed: true},
+		{name: "SupportRevokeOtherSessions", role: util.SupportRole, permission: SessionRevoke, own: false, allowed: false},
+		{name: "SupportRevokeOwnSessions", role: util.SupportRole, permission: SessionRevoke, own: true, allowed: true},
+		{name: "UnknownRole", role: util.Role("intruder"), permission: AccountRead, own: true, allowed: false},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			payload := newPayload(t, tc.role)
+			owner := util.RandomOwner()
+			if tc.own {
+				owner = payload.Username
+			}
+			err := Authorize(payload, tc.permission, owner)
+			if tc.allowed {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrPermissionDenied)
+			}
+		})
+	}
+}
+
+func TestRegistry_Load(t *testing.T) {
+	registry := NewRegistry(map[util.Role][]Permission{
+		util.DepositorRole: {AccountRead},
+	})
+	payload := newPayload(t, util.DepositorRole)
+	require.NoError(t, registry.Authorize(payload, AccountRead, payload.Username))
+	require.ErrorIs(t, registry.Authorize(payload, AccountCreate, payload.Username), ErrPermissionDenied)
+
+	// the new grants replace the previous ones
+	registry.Load(map[util.Role][]Permission{
+		util.DepositorRole: {AccountCreate},
+	})
+	require.NoError(t, registry.Authorize(payload, AccountCreate, payload.Username))
+	require.ErrorIs(t, registry.Authorize(payload, AccountRead, payload.Username), ErrPermissionDenied)
+}
+
+func TestRegistry_Holds(t *testing.T) {
+	registry := NewRegistry(map[util.Role][]Permission{
+		util.DepositorRole: {AccountRead},
+		util.AuditorRole:   {AccountRead.Any()},
+	})
+	require.True(t, registry.Holds(util.DepositorRole.String(), AccountRead))
+	require.True(t, registry.Holds(util.AuditorRole.String(), AccountRead))
+	require.False(t, registry.Holds(util.AuditorRole.String(), AccountCreate))
+	require.False(t, registry.Holds("intruder", AccountRead))
+}
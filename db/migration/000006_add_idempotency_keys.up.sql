@@ -0,0 +1,13 @@
+CREATE TABLE "idempotency_keys" (
+                                    "key" varchar NOT NULL,
+                                    "username" varchar NOT NULL,
+                                    "request_hash" varchar NOT NULL,
+                                    "transfer_id" bigint,
+                                    "response" jsonb,
+                                    "created_at" timestamptz NOT NULL DEFAULT (now()),
+                                    PRIMARY KEY ("username", "key")
+);
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,11 @@
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+
+UPDATE "transfers" SET "to_amount" = "amount";
+
+ALTER TABLE "transfers" ALTER COLUMN "to_amount" SET NOT NULL;
+
+ALTER TABLE "transfers" ADD COLUMN "exchange_rate" bigint NOT NULL DEFAULT 100000000;
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'amount credited in the currency of the destination account';
+
+COMMENT ON COLUMN "transfers"."exchange_rate" IS 'rate applied to amount, scaled by 1e8';
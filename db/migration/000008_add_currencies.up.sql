@@ -0,0 +1,17 @@
+CREATE TABLE "currencies" (
+                              "code" varchar(3) PRIMARY KEY,
+                              "minor_units" int NOT NULL,
+                              "enabled" boolean NOT NULL DEFAULT true,
+                              "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "currencies"."code" IS 'ISO 4217 alphabetic code';
+
+COMMENT ON COLUMN "currencies"."minor_units" IS 'number of decimal places of the minor unit, 2 for USD, 0 for JPY';
+
+INSERT INTO "currencies" ("code", "minor_units") VALUES
+    ('USD', 2),
+    ('EUR', 2),
+    ('CAD', 2);
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
@@ -0,0 +1,13 @@
+ALTER TABLE "sessions" ADD COLUMN "family_id" uuid;
+
+UPDATE "sessions" SET "family_id" = "id";
+
+ALTER TABLE "sessions" ALTER COLUMN "family_id" SET NOT NULL;
+
+ALTER TABLE "sessions" ADD COLUMN "rotated_at" timestamptz;
+
+CREATE INDEX ON "sessions" ("family_id");
+
+COMMENT ON COLUMN "sessions"."family_id" IS 'id of the login session, shared by every session created by rotating its refresh token';
+
+COMMENT ON COLUMN "sessions"."rotated_at" IS 'set when the refresh token has been exchanged, presenting it again revokes the family';
@@ -0,0 +1,43 @@
+CREATE TABLE "roles" (
+                         "name" varchar PRIMARY KEY,
+                         "description" varchar NOT NULL DEFAULT '',
+                         "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "role_permissions" (
+                                    "role" varchar NOT NULL,
+                                    "permission" varchar NOT NULL,
+                                    PRIMARY KEY ("role", "permission")
+);
+
+ALTER TABLE "role_permissions" ADD FOREIGN KEY ("role") REFERENCES "roles" ("name") ON DELETE CASCADE;
+
+COMMENT ON COLUMN "role_permissions"."permission" IS 'action such as account:read, the :any suffix extends it to the resources of every user';
+
+INSERT INTO "roles" ("name", "description") VALUES
+    ('depositor', 'customer managing its own accounts'),
+    ('banker', 'employee managing the accounts of every customer'),
+    ('auditor', 'read-only access to the accounts of every customer'),
+    ('support', 'read-only access to the accounts and sessions of every customer');
+
+INSERT INTO "role_permissions" ("role", "permission") VALUES
+    ('depositor', 'account:create'),
+    ('depositor', 'account:read'),
+    ('depositor', 'transfer:create'),
+    ('banker', 'account:create:any'),
+    ('banker', 'account:read:any'),
+    ('banker', 'account:update:any'),
+    ('banker', 'account:delete:any'),
+    ('banker', 'transfer:create:any'),
+    ('banker', 'user:update:any'),
+    ('banker', 'session:read:any'),
+    ('banker', 'session:revoke:any'),
+    ('auditor', 'account:read:any'),
+    ('support', 'account:read:any'),
+    ('support', 'session:read:any');
+
+INSERT INTO "role_permissions" ("role", "permission")
+SELECT "name", "permission"
+FROM "roles", (VALUES ('user:update'), ('session:read'), ('session:revoke')) AS self_service ("permission");
+
+ALTER TABLE "users" ADD FOREIGN KEY ("role") REFERENCES "roles" ("name");
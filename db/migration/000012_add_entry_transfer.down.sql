@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "transfers_created_at_idx";
+
+DROP INDEX IF EXISTS "entries_account_id_created_at_idx";
+
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "transfer_id";
@@ -0,0 +1,12 @@
+-- the list queries page through (created_at, id) after the last row of the previous page
+DROP INDEX IF EXISTS "entries_account_id_created_at_idx";
+
+CREATE INDEX ON "entries" ("account_id", "created_at", "id");
+
+CREATE INDEX ON "accounts" ("owner", "created_at", "id");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at", "id");
+
+CREATE INDEX ON "transfers" ("to_account_id", "created_at", "id");
+
+CREATE INDEX ON "sessions" ("username", "created_at", "id");
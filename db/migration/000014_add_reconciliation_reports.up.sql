@@ -0,0 +1,14 @@
+CREATE TABLE "reconciliation_reports" (
+  "id" bigserial PRIMARY KEY,
+  "started_at" timestamptz NOT NULL,
+  "finished_at" timestamptz NOT NULL,
+  "drifted_accounts" int NOT NULL,
+  "orphaned_entries" int NOT NULL,
+  "orphaned_transfers" int NOT NULL,
+  "findings" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "reconciliation_reports" ("created_at");
+
+COMMENT ON COLUMN "reconciliation_reports"."findings" IS 'drifted accounts, orphaned entries and orphaned transfers found by the run';
@@ -0,0 +1,6 @@
+DELETE FROM "role_permissions" WHERE "permission" IN ('account:deposit:any', 'account:withdraw:any');
+
+INSERT INTO "role_permissions" ("role", "permission") VALUES ('banker', 'account:update:any');
+
+-- the cash accounts and their entries are kept, they balance the deposits and withdrawals of the customers
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "kind";
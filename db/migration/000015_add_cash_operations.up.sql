@@ -0,0 +1,19 @@
+ALTER TABLE "transfers" ADD COLUMN "kind" varchar NOT NULL DEFAULT 'transfer';
+
+COMMENT ON COLUMN "transfers"."kind" IS 'transfer, deposit or withdrawal, deposits and withdrawals move money from or to the cash account of the currency';
+
+-- the bank owns one cash account per currency, it can't log in: no password hash matches an empty one
+INSERT INTO "users" ("username", "role", "hashed_password", "full_name", "email")
+VALUES ('bank', 'auditor', '', 'Simple Bank', 'ledger@simplebank.invalid')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+SELECT 'bank', 0, "code" FROM "currencies"
+ON CONFLICT DO NOTHING;
+
+-- balances only change through the ledger, the raw balance overwrite is retired
+DELETE FROM "role_permissions" WHERE "permission" = 'account:update:any';
+
+INSERT INTO "role_permissions" ("role", "permission") VALUES
+    ('banker', 'account:deposit:any'),
+    ('banker', 'account:withdraw:any');
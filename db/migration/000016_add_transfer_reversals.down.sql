@@ -0,0 +1,5 @@
+DELETE FROM "role_permissions" WHERE "permission" IN ('transfer:reverse', 'transfer:reverse:any');
+
+COMMENT ON COLUMN "transfers"."kind" IS 'transfer, deposit or withdrawal, deposits and withdrawals move money from or to the cash account of the currency';
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "reversed_transfer_id";
@@ -0,0 +1,14 @@
+ALTER TABLE "transfers" ADD COLUMN "reversed_transfer_id" bigint;
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reversed_transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "transfers" ("reversed_transfer_id");
+
+COMMENT ON COLUMN "transfers"."kind" IS 'transfer, deposit, withdrawal or reversal, deposits and withdrawals move money from or to the cash account of the currency';
+
+COMMENT ON COLUMN "transfers"."reversed_transfer_id" IS 'transfer refunded by this reversal, the reversals of a transfer refund at most its amount';
+
+-- the receiving owner of a transfer may refund it
+INSERT INTO "role_permissions" ("role", "permission") VALUES
+    ('depositor', 'transfer:reverse'),
+    ('banker', 'transfer:reverse:any');
@@ -0,0 +1,57 @@
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "schedule" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "occurrence_at" timestamptz NOT NULL,
+  "next_run_at" timestamptz NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "scheduled_transfer_runs" (
+  "id" bigserial PRIMARY KEY,
+  "scheduled_transfer_id" bigint NOT NULL,
+  "occurrence_at" timestamptz NOT NULL,
+  "attempt" int NOT NULL,
+  "transfer_id" bigint,
+  "error" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "scheduled_transfers" ("status", "next_run_at");
+
+CREATE INDEX ON "scheduled_transfers" ("from_account_id", "created_at", "id");
+
+CREATE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id", "created_at");
+
+COMMENT ON COLUMN "scheduled_transfers"."username" IS 'user who scheduled the transfer, the transfers are made on its behalf';
+
+COMMENT ON COLUMN "scheduled_transfers"."amount" IS 'must be positive';
+
+COMMENT ON COLUMN "scheduled_transfers"."schedule" IS 'cron expression of five fields, @hourly, @daily, @weekly, @monthly, @yearly or @every <duration>, evaluated in UTC';
+
+COMMENT ON COLUMN "scheduled_transfers"."status" IS 'active or paused';
+
+COMMENT ON COLUMN "scheduled_transfers"."occurrence_at" IS 'occurrence of the schedule the next run executes';
+
+COMMENT ON COLUMN "scheduled_transfers"."next_run_at" IS 'occurrence_at, pushed back while a run is in progress or a failed run waits for its retry';
+
+COMMENT ON COLUMN "scheduled_transfers"."attempts" IS 'failed attempts of the current occurrence';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."transfer_id" IS 'transfer made by a successful run';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."error" IS 'failure of the run';
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "transfer_holds";
+
+DELETE FROM "transfers" WHERE "status" <> 'posted';
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "status";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "available_balance";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "held_amount";
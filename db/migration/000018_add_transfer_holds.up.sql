@@ -0,0 +1,33 @@
+ALTER TABLE "accounts" ADD COLUMN "held_amount" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD COLUMN "available_balance" bigint GENERATED ALWAYS AS ("balance" - "held_amount") STORED;
+
+ALTER TABLE "transfers" ADD COLUMN "status" varchar NOT NULL DEFAULT 'posted';
+
+CREATE TABLE "transfer_holds" (
+  "id" bigserial PRIMARY KEY,
+  "transfer_id" bigint UNIQUE NOT NULL,
+  "account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "released_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "transfer_holds" ("account_id");
+
+CREATE INDEX ON "transfer_holds" ("expires_at");
+
+COMMENT ON COLUMN "accounts"."held_amount" IS 'sum of the active holds of the pending transfers from the account';
+
+COMMENT ON COLUMN "accounts"."available_balance" IS 'balance minus held_amount, what the account can still spend';
+
+COMMENT ON COLUMN "transfers"."status" IS 'posted, or pending until captured, voided or expired, only posted transfers have entries';
+
+COMMENT ON COLUMN "transfer_holds"."amount" IS 'amount held on the account, in its currency';
+
+COMMENT ON COLUMN "transfer_holds"."released_at" IS 'set when the transfer is captured, voided or expired';
+
+ALTER TABLE "transfer_holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_holds" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
@@ -0,0 +1,5 @@
+DELETE FROM "role_permissions" WHERE "permission" = 'account:status:any';
+
+DROP TABLE IF EXISTS "account_status_changes";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "status";
@@ -0,0 +1,24 @@
+ALTER TABLE "accounts" ADD COLUMN "status" varchar NOT NULL DEFAULT 'active';
+
+CREATE TABLE "account_status_changes" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "from_status" varchar NOT NULL,
+  "to_status" varchar NOT NULL,
+  "reason" varchar NOT NULL,
+  "changed_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "account_status_changes" ("account_id", "created_at");
+
+COMMENT ON COLUMN "accounts"."status" IS 'active, frozen or closed, frozen accounts can only be credited, closed accounts can''t move money';
+
+COMMENT ON COLUMN "account_status_changes"."changed_by" IS 'banker who changed the status';
+
+ALTER TABLE "account_status_changes" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "account_status_changes" ADD FOREIGN KEY ("changed_by") REFERENCES "users" ("username");
+
+INSERT INTO "role_permissions" ("role", "permission") VALUES
+    ('banker', 'account:status:any');
@@ -0,0 +1,5 @@
+DELETE FROM "role_permissions" WHERE "permission" = 'audit:read:any';
+
+DROP TABLE IF EXISTS "audit_events";
+
+DROP FUNCTION IF EXISTS reject_audit_event_change();
@@ -0,0 +1,44 @@
+CREATE TABLE "audit_events" (
+  "id" bigserial PRIMARY KEY,
+  "actor" varchar NOT NULL,
+  "actor_role" varchar NOT NULL DEFAULT '',
+  "client_ip" varchar NOT NULL DEFAULT '',
+  "user_agent" varchar NOT NULL DEFAULT '',
+  "action" varchar NOT NULL,
+  "resource_type" varchar NOT NULL,
+  "resource_id" varchar NOT NULL,
+  "before" jsonb,
+  "after" jsonb,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "audit_events" ("created_at", "id");
+
+CREATE INDEX ON "audit_events" ("actor", "created_at");
+
+CREATE INDEX ON "audit_events" ("resource_type", "resource_id", "created_at");
+
+COMMENT ON TABLE "audit_events" IS 'append-only, updates and deletes are rejected by a trigger';
+
+COMMENT ON COLUMN "audit_events"."actor" IS 'user whose access token authorized the mutation, anonymous for public requests such as a login, system for the background jobs';
+
+COMMENT ON COLUMN "audit_events"."action" IS 'such as account.create or transfer.capture';
+
+COMMENT ON COLUMN "audit_events"."before" IS 'state of the resource before the mutation, null when it is created';
+
+COMMENT ON COLUMN "audit_events"."after" IS 'state of the resource after the mutation, null when it is deleted';
+
+CREATE FUNCTION reject_audit_event_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_events_append_only" BEFORE UPDATE OR DELETE ON "audit_events"
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_event_change();
+
+CREATE TRIGGER "audit_events_no_truncate" BEFORE TRUNCATE ON "audit_events"
+    FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_event_change();
+
+INSERT INTO "role_permissions" ("role", "permission") VALUES
+    ('banker', 'audit:read:any');
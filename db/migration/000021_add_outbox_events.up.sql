@@ -0,0 +1,22 @@
+CREATE TABLE "outbox_events" (
+  "id" bigserial PRIMARY KEY,
+  "event_type" varchar NOT NULL,
+  "aggregate_type" varchar NOT NULL,
+  "aggregate_id" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" varchar,
+  "available_at" timestamptz NOT NULL DEFAULT (now()),
+  "published_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "outbox_events" ("published_at", "id");
+
+COMMENT ON COLUMN "outbox_events"."event_type" IS 'such as transfer.posted or account.status_changed';
+
+COMMENT ON COLUMN "outbox_events"."attempts" IS 'failed publications of the event';
+
+COMMENT ON COLUMN "outbox_events"."available_at" IS 'the relay skips the event until then, while it is claimed or waits for its retry';
+
+COMMENT ON COLUMN "outbox_events"."published_at" IS 'set once the relay has published the event';
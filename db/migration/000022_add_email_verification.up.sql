@@ -0,0 +1,37 @@
+ALTER TABLE "users" ADD COLUMN "is_email_verified" bool NOT NULL DEFAULT false;
+
+CREATE TABLE "verify_emails" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "email" varchar NOT NULL,
+  "secret_code" varchar NOT NULL,
+  "is_used" bool NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expired_at" timestamptz NOT NULL DEFAULT (now() + interval '15 minutes')
+);
+
+CREATE TABLE "tasks" (
+  "id" bigserial PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" varchar,
+  "run_at" timestamptz NOT NULL DEFAULT (now()),
+  "completed_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "verify_emails" ("username");
+
+CREATE INDEX ON "tasks" ("status", "run_at");
+
+COMMENT ON COLUMN "verify_emails"."email" IS 'address the code was sent to, it verifies the user only while it is still the email of the user';
+
+COMMENT ON COLUMN "tasks"."kind" IS 'such as send_verify_email, selects the handler of the worker';
+
+COMMENT ON COLUMN "tasks"."status" IS 'pending, done, or failed once its attempts are exhausted';
+
+COMMENT ON COLUMN "tasks"."run_at" IS 'the worker skips the task until then, while it is claimed or waits for its retry';
+
+ALTER TABLE "verify_emails" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
@@ -0,0 +1,4 @@
+-- the receiving owner of a pending transfer captures or voids it
+INSERT INTO "role_permissions" ("role", "permission") VALUES
+    ('depositor', 'transfer:capture'),
+    ('banker', 'transfer:capture:any');
@@ -0,0 +1,3 @@
+ALTER TABLE "account_status_changes" DROP CONSTRAINT "account_status_changes_account_id_fkey";
+
+ALTER TABLE "account_status_changes" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
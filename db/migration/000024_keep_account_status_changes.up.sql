@@ -0,0 +1,4 @@
+-- the status changes of an account are kept, an account with a history is closed rather than deleted
+ALTER TABLE "account_status_changes" DROP CONSTRAINT "account_status_changes_account_id_fkey";
+
+ALTER TABLE "account_status_changes" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
@@ -0,0 +1,3 @@
+-- bankers set how far below zero an account may go
+INSERT INTO "role_permissions" ("role", "permission") VALUES
+    ('banker', 'account:overdraft:any');
@@ -0,0 +1,13 @@
+-- the cash accounts go back to bank unless a customer holds that username
+UPDATE "users" SET "email" = 'bank@simplebank.invalid' WHERE "username" = '@bank';
+
+INSERT INTO "users" ("username", "role", "hashed_password", "full_name", "email")
+VALUES ('bank', 'auditor', '', 'Simple Bank', 'ledger@simplebank.invalid')
+ON CONFLICT DO NOTHING;
+
+UPDATE "accounts" SET "owner" = 'bank'
+WHERE "owner" = '@bank'
+  AND EXISTS (SELECT 1 FROM "users" WHERE "username" = 'bank' AND "hashed_password" = '');
+
+DELETE FROM "users" WHERE "username" = '@bank'
+  AND NOT EXISTS (SELECT 1 FROM "accounts" WHERE "owner" = '@bank');
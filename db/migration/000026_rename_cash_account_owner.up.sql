@@ -0,0 +1,18 @@
+-- the cash accounts move to a username the customers can't register. The user created by 000015
+-- is recognized by its empty password hash: when a customer had registered bank first, its
+-- accounts are left to it and the missing cash accounts are created below.
+UPDATE "users" SET "email" = 'bank@simplebank.invalid'
+WHERE "username" = 'bank' AND "hashed_password" = '';
+
+INSERT INTO "users" ("username", "role", "hashed_password", "full_name", "email")
+VALUES ('@bank', 'auditor', '', 'Simple Bank', 'ledger@simplebank.invalid');
+
+UPDATE "accounts" SET "owner" = '@bank'
+WHERE "owner" = 'bank'
+  AND EXISTS (SELECT 1 FROM "users" WHERE "username" = 'bank' AND "hashed_password" = '');
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+SELECT '@bank', 0, "code" FROM "currencies"
+ON CONFLICT DO NOTHING;
+
+DELETE FROM "users" WHERE "username" = 'bank' AND "hashed_password" = '';
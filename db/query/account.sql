@@ -15,25 +15,42 @@ select * from accounts
 where id= $1 limit 1
 FOR NO KEY UPDATE;
 
+-- name: GetCashAccount :one
+select * from accounts
+where owner = '@bank' and currency = $1 limit 1;
+
 -- name: ListAccounts :many
 select * from accounts
-where owner = $1
-order by id
-LIMIT $2
-offset $3;
+where owner = sqlc.arg(owner)
+  and (sqlc.narg(after_created_at)::timestamptz is null
+    or (created_at, id) > (sqlc.narg(after_created_at), sqlc.narg(after_id)::bigint))
+order by created_at, id
+LIMIT sqlc.arg('limit');
 
--- name: UpdateAccount :one
+-- name: AddAccountBalance :one
 update accounts
-set balance = $2
-where id = $1
+set balance = balance + sqlc.arg(amount)
+where id = sqlc.arg(id)
 returning *;
 
--- name: AddAccountBalance :one
+-- name: AddAccountHeldAmount :one
 update accounts
-set balance = balance + sqlc.arg(amount)
+set held_amount = held_amount + sqlc.arg(amount)
 where id = sqlc.arg(id)
 returning *;
 
 -- name: DeleteAccount :exec
 delete from accounts
 where id = $1;
+
+-- name: UpdateAccountStatus :one
+update accounts
+set status = sqlc.arg(status)
+where id = sqlc.arg(id)
+returning *;
+
+-- name: UpdateAccountOverdraftLimit :one
+update accounts
+set overdraft_limit = sqlc.arg(overdraft_limit)
+where id = sqlc.arg(id)
+returning *;
@@ -0,0 +1,14 @@
+-- name: CreateAccountStatusChange :one
+insert into account_status_changes (
+    account_id, from_status, to_status, reason, changed_by
+) VALUES (
+    $1,$2,$3,$4,$5
+)
+returning *;
+
+-- name: ListAccountStatusChanges :many
+-- the latest changes first
+select * from account_status_changes
+where account_id = sqlc.arg(account_id)
+order by created_at desc, id desc
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,21 @@
+-- name: CreateAuditEvent :one
+insert into audit_events (
+    actor, actor_role, client_ip, user_agent, action, resource_type, resource_id, before, after
+) VALUES (
+    $1,$2,$3,$4,$5,$6,$7,$8,$9
+)
+returning *;
+
+-- name: ListAuditEvents :many
+-- every filter is optional
+SELECT * FROM audit_events
+WHERE (sqlc.narg(actor)::text IS NULL OR actor = sqlc.narg(actor))
+  AND (sqlc.narg(action)::text IS NULL OR action = sqlc.narg(action))
+  AND (sqlc.narg(resource_type)::text IS NULL OR resource_type = sqlc.narg(resource_type))
+  AND (sqlc.narg(resource_id)::text IS NULL OR resource_id = sqlc.narg(resource_id))
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to))
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+    OR (created_at, id) > (sqlc.narg(after_created_at), sqlc.narg(after_id)::bigint))
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
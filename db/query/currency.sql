@@ -0,0 +1,3 @@
+-- name: ListCurrencies :many
+select * from currencies
+order by code;
\ No newline at end of file
@@ -1,9 +1,10 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
     account_id,
-    amount
+    amount,
+    transfer_id
 ) VALUES (
- $1, $2
+ $1, $2, $3
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -11,8 +12,35 @@ SELECT * FROM entries
 WHERE id = $1 LIMIT 1;
 
 -- name: ListEntries :many
+SELECT e.* FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE e.account_id = sqlc.arg(account_id)
+  AND (sqlc.arg(direction)::text = 'both'
+    OR (sqlc.arg(direction)::text = 'incoming' AND e.amount > 0)
+    OR (sqlc.arg(direction)::text = 'outgoing' AND e.amount < 0))
+  AND (sqlc.narg(counterparty_id)::bigint IS NULL
+    OR sqlc.narg(counterparty_id)::bigint = CASE WHEN t.from_account_id = e.account_id THEN t.to_account_id ELSE t.from_account_id END)
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR e.created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR e.created_at < sqlc.narg(created_to))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR abs(e.amount) >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR abs(e.amount) <= sqlc.narg(max_amount))
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+    OR (e.created_at, e.id) > (sqlc.narg(after_created_at), sqlc.narg(after_id)::bigint))
+ORDER BY e.created_at, e.id
+LIMIT sqlc.arg('limit');
+
+-- name: GetOpeningBalance :one
+SELECT (a.balance - COALESCE((
+    SELECT SUM(e.amount) FROM entries e
+    WHERE e.account_id = a.id
+      AND e.created_at >= sqlc.arg(since)
+), 0))::bigint AS opening_balance
+FROM accounts a
+WHERE a.id = sqlc.arg(account_id);
+
+-- name: ListEntriesBetween :many
 SELECT * FROM entries
-WHERE account_id = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3;
\ No newline at end of file
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(from_time)
+  AND created_at < sqlc.arg(to_time)
+ORDER BY created_at, id;
@@ -0,0 +1,19 @@
+-- name: CreateIdempotencyKey :one
+insert into idempotency_keys (
+    key, username, request_hash
+) VALUES (
+             $1,$2,$3
+         )
+on conflict (username, key) do nothing
+returning *;
+
+-- name: GetIdempotencyKey :one
+select * from idempotency_keys
+where username = $1 and key = $2 limit 1;
+
+-- name: UpdateIdempotencyKeyResponse :one
+update idempotency_keys
+set transfer_id = $3,
+    response = $4
+where username = $1 and key = $2
+returning *;
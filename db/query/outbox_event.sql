@@ -0,0 +1,45 @@
+-- name: CreateOutboxEvent :one
+insert into outbox_events (
+    event_type, aggregate_type, aggregate_id, payload
+) VALUES (
+    $1,$2,$3,$4
+)
+returning *;
+
+-- name: ClaimOutboxEvents :many
+-- the claimed events are pushed back to lease_until, so that the other relays skip them
+-- until they are published, or the claim expires if the relay dies. The claim stops before the
+-- first unpublished event that isn't due, claimed or waiting for its retry, so that the events
+-- are published in order; it fails rather than wait while another relay claims.
+update outbox_events
+set available_at = sqlc.arg(lease_until)
+where id in (
+    select id from outbox_events
+    where published_at is null and available_at <= sqlc.arg(due_at)
+      and not exists (
+        select 1 from outbox_events pending
+        where pending.published_at is null and pending.available_at > sqlc.arg(due_at)
+          and pending.id < outbox_events.id
+      )
+    order by id
+    limit sqlc.arg('limit')
+    for update nowait
+)
+returning *;
+
+-- name: MarkOutboxEventPublished :exec
+update outbox_events
+set published_at = sqlc.arg(published_at)::timestamptz
+where id = sqlc.arg(id);
+
+-- name: RecordOutboxEventFailure :exec
+update outbox_events
+set attempts = attempts + 1,
+    last_error = sqlc.arg(last_error)::text,
+    available_at = sqlc.arg(retry_at)
+where id = sqlc.arg(id);
+
+-- name: DelayOutboxEvents :exec
+update outbox_events
+set available_at = sqlc.arg(available_at)
+where id = any(sqlc.arg(ids)::bigint[]);
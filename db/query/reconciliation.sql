@@ -0,0 +1,43 @@
+-- name: CreateReconciliationReport :one
+insert into reconciliation_reports (
+    started_at, finished_at, drifted_accounts, orphaned_entries, orphaned_transfers, findings
+) VALUES (
+    $1,$2,$3,$4,$5,$6
+)
+returning *;
+
+-- name: GetLatestReconciliationReport :one
+select * from reconciliation_reports
+order by created_at desc, id desc
+limit 1;
+
+-- name: ListBalanceDrifts :many
+-- accounts whose balance is not the sum of their entries
+select a.id as account_id, a.balance, coalesce(sum(e.amount), 0)::bigint as entries_total
+from accounts a
+left join entries e on e.account_id = a.id
+group by a.id
+having a.balance <> coalesce(sum(e.amount), 0)
+order by a.id;
+
+-- name: ListOrphanedEntries :many
+-- entries that don't belong to a transfer of their account
+select e.* from entries e
+left join transfers t on t.id = e.transfer_id
+where t.id is null
+   or e.account_id not in (t.from_account_id, t.to_account_id)
+order by e.id;
+
+-- name: ListOrphanedTransfers :many
+-- posted transfers without exactly one debit entry on the source account and one credit entry on the destination account
+select t.id, t.from_account_id, t.to_account_id, t.amount, t.to_amount,
+       count(e.id) filter (where e.account_id = t.from_account_id and e.amount = -t.amount) as debit_entries,
+       count(e.id) filter (where e.account_id = t.to_account_id and e.amount = t.to_amount) as credit_entries
+from transfers t
+left join entries e on e.transfer_id = t.id
+where t.status = 'posted'
+group by t.id
+having count(e.id) <> 2
+    or count(e.id) filter (where e.account_id = t.from_account_id and e.amount = -t.amount) <> 1
+    or count(e.id) filter (where e.account_id = t.to_account_id and e.amount = t.to_amount) <> 1
+order by t.id;
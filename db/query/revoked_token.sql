@@ -0,0 +1,22 @@
+-- name: CreateRevokedToken :exec
+insert into revoked_tokens (
+    id, username, expires_at
+) VALUES (
+             $1,$2,$3
+         )
+on conflict (id) do nothing;
+
+-- name: IsTokenRevoked :one
+select exists(select 1 from revoked_tokens where id = sqlc.arg('id'))
+    or exists(select 1 from users
+              where username = sqlc.arg('username')
+                and date_trunc('second', greatest(password_changed_at, tokens_revoked_at)) > sqlc.arg('issued_at')) as revoked;
+
+-- name: RevokeUserTokens :exec
+update users
+set tokens_revoked_at = greatest(tokens_revoked_at, sqlc.arg('issued_before'))
+where username = sqlc.arg('username');
+
+-- name: DeleteExpiredRevokedTokens :execrows
+delete from revoked_tokens
+where expires_at < now();
@@ -0,0 +1,5 @@
+-- name: ListRolePermissions :many
+select r.name as role, rp.permission
+from roles r
+left join role_permissions rp on rp.role = r.name
+order by r.name, rp.permission;
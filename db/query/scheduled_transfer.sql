@@ -0,0 +1,80 @@
+-- name: CreateScheduledTransfer :one
+insert into scheduled_transfers (
+    username, from_account_id, to_account_id, amount, schedule, occurrence_at, next_run_at
+) VALUES (
+    $1,$2,$3,$4,$5,$6,$7
+)
+returning *;
+
+-- name: GetScheduledTransfer :one
+select * from scheduled_transfers
+where id = $1 limit 1;
+
+-- name: GetScheduledTransferForUpdate :one
+select * from scheduled_transfers
+where id = $1 limit 1
+FOR NO KEY UPDATE;
+
+-- name: ListScheduledTransfers :many
+select * from scheduled_transfers
+where from_account_id = sqlc.arg(from_account_id)
+  and (sqlc.narg(after_created_at)::timestamptz is null
+    or (created_at, id) > (sqlc.narg(after_created_at), sqlc.narg(after_id)::bigint))
+order by created_at, id
+LIMIT sqlc.arg('limit');
+
+-- name: UpdateScheduledTransfer :one
+-- a new occurrence_at restarts the schedule, the retries of the previous occurrence are dropped
+update scheduled_transfers
+set amount = coalesce(sqlc.narg('amount'), amount),
+    schedule = coalesce(sqlc.narg('schedule'), schedule),
+    status = coalesce(sqlc.narg('status'), status),
+    occurrence_at = coalesce(sqlc.narg('occurrence_at'), occurrence_at),
+    next_run_at = coalesce(sqlc.narg('occurrence_at'), next_run_at),
+    attempts = case when sqlc.narg('occurrence_at')::timestamptz is null then attempts else 0 end
+where id = sqlc.arg('id')
+returning *;
+
+-- name: DeleteScheduledTransfer :exec
+delete from scheduled_transfers
+where id = $1;
+
+-- name: ClaimDueScheduledTransfers :many
+-- the claimed transfers are pushed back to lease_until, so that the other workers skip them
+-- until the run is recorded, or the claim expires if the worker dies
+update scheduled_transfers
+set next_run_at = sqlc.arg(lease_until)
+where id in (
+    select id from scheduled_transfers
+    where status = 'active' and next_run_at <= sqlc.arg(due_at)
+    order by next_run_at
+    limit sqlc.arg('limit')
+    for update skip locked
+)
+returning *;
+
+-- name: AdvanceScheduledTransfer :one
+-- the transfer only advances from the occurrence and lease it was claimed with, an update made
+-- during the run has moved it already and is kept
+update scheduled_transfers
+set occurrence_at = sqlc.arg(occurrence_at),
+    next_run_at = sqlc.arg(next_run_at),
+    attempts = sqlc.arg(attempts)
+where id = sqlc.arg(id)
+  and occurrence_at = sqlc.arg(claimed_occurrence_at)
+  and next_run_at = sqlc.arg(claimed_next_run_at)
+returning *;
+
+-- name: CreateScheduledTransferRun :one
+insert into scheduled_transfer_runs (
+    scheduled_transfer_id, occurrence_at, attempt, transfer_id, error
+) VALUES (
+    $1,$2,$3,$4,$5
+)
+returning *;
+
+-- name: ListScheduledTransferRuns :many
+select * from scheduled_transfer_runs
+where scheduled_transfer_id = $1
+order by created_at desc, id desc
+limit $2;
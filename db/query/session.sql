@@ -1,11 +1,41 @@
 -- name: CreateSession :one
 insert into sessions (
-    id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at
+    id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, family_id
 ) VALUES (
-             $1,$2,$3,$4,$5,$6,$7
+             $1,$2,$3,$4,$5,$6,$7,$8
          )
 returning *;
 
 -- name: GetSession :one
 select * from sessions
-where id= $1 limit 1;
\ No newline at end of file
+where id= $1 limit 1;
+
+-- name: ListSessions :many
+select * from sessions
+where username = sqlc.arg(username) and rotated_at is null
+  and (sqlc.narg(after_created_at)::timestamptz is null
+    or (created_at, id) < (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+order by created_at desc, id desc
+LIMIT sqlc.arg('limit');
+
+-- name: BlockSession :one
+update sessions
+set is_blocked = true
+where id = $1
+returning *;
+
+-- name: BlockUserSessions :execrows
+update sessions
+set is_blocked = true
+where username = $1 and is_blocked = false;
+
+-- name: RotateSession :one
+update sessions
+set rotated_at = now()
+where id = $1 and rotated_at is null
+returning *;
+
+-- name: BlockSessionFamily :execrows
+update sessions
+set is_blocked = true
+where family_id = $1 and is_blocked = false;
@@ -0,0 +1,36 @@
+-- name: CreateTask :one
+insert into tasks (
+    kind, payload
+) VALUES (
+    $1,$2
+)
+returning *;
+
+-- name: ClaimTasks :many
+-- the claimed tasks are pushed back to lease_until, so that the other workers skip them
+-- until their outcome is recorded, or the claim expires if the worker dies
+update tasks
+set run_at = sqlc.arg(lease_until)
+where id in (
+    select id from tasks
+    where status = 'pending' and run_at <= sqlc.arg(due_at)
+    order by run_at
+    limit sqlc.arg('limit')
+    for update skip locked
+)
+returning *;
+
+-- name: CompleteTask :exec
+update tasks
+set status = 'done',
+    completed_at = sqlc.arg(completed_at)::timestamptz
+where id = sqlc.arg(id);
+
+-- name: RecordTaskFailure :exec
+-- a task retried later stays pending until run_at, a failed one isn't run again
+update tasks
+set attempts = attempts + 1,
+    last_error = sqlc.arg(last_error)::text,
+    status = sqlc.arg(status),
+    run_at = sqlc.arg(run_at)
+where id = sqlc.arg(id);
@@ -0,0 +1,24 @@
+-- name: CreateTransferHold :one
+insert into transfer_holds (
+    transfer_id, account_id, amount, expires_at
+) VALUES (
+    $1,$2,$3,$4
+)
+returning *;
+
+-- name: GetTransferHold :one
+select * from transfer_holds
+where transfer_id = $1 limit 1;
+
+-- name: ReleaseTransferHold :one
+update transfer_holds
+set released_at = now()
+where transfer_id = $1 and released_at is null
+returning *;
+
+-- name: ListExpiredTransferHolds :many
+-- holds still active past their expiry, the oldest first
+select * from transfer_holds
+where released_at is null and expires_at <= sqlc.arg(expired_at)
+order by expires_at, id
+LIMIT sqlc.arg('limit');
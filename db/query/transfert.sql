@@ -2,20 +2,50 @@
 INSERT INTO transfers (
     from_account_id,
     to_account_id,
-    amount
+    amount,
+    to_amount,
+    exchange_rate,
+    kind,
+    reversed_transfer_id,
+    status
 ) VALUES (
- $1, $2, $3
+ $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
+-- name: GetReversedTotals :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS amount,
+       COALESCE(SUM(to_amount), 0)::bigint AS to_amount
+FROM transfers
+WHERE reversed_transfer_id = sqlc.arg(transfer_id)::bigint;
+
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
-WHERE
-from_account_id = $1 OR
-to_account_id = $2
-ORDER BY id
-LIMIT $3
-OFFSET $4;
\ No newline at end of file
+WHERE ((from_account_id = sqlc.arg(account_id) AND sqlc.arg(direction)::text IN ('outgoing', 'both'))
+    OR (to_account_id = sqlc.arg(account_id) AND sqlc.arg(direction)::text IN ('incoming', 'both')))
+  AND (sqlc.narg(counterparty_id)::bigint IS NULL
+    OR sqlc.narg(counterparty_id)::bigint = CASE WHEN from_account_id = sqlc.arg(account_id) THEN to_account_id ELSE from_account_id END)
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to))
+  AND (sqlc.narg(min_amount)::bigint IS NULL
+    OR CASE WHEN from_account_id = sqlc.arg(account_id) THEN amount ELSE to_amount END >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL
+    OR CASE WHEN from_account_id = sqlc.arg(account_id) THEN amount ELSE to_amount END <= sqlc.narg(max_amount))
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+    OR (created_at, id) > (sqlc.narg(after_created_at), sqlc.narg(after_id)::bigint))
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
+
+-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET status = $2
+WHERE id = $1
+RETURNING *;
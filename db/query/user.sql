@@ -10,11 +10,17 @@ returning *;
 select * from users
 where username= $1 limit 1;
 
+-- name: GetUserForUpdate :one
+select * from users
+where username= $1 limit 1
+FOR NO KEY UPDATE;
+
 -- name: UpdateUser :one
 update users
 set hashed_password = coalesce(sqlc.narg('hashed_password'),hashed_password),
     password_changed_at = coalesce(sqlc.narg('password_changed_at'),password_changed_at),
     full_name = coalesce(sqlc.narg('full_name'),full_name),
-    email = coalesce(sqlc.narg('email'),email)
+    email = coalesce(sqlc.narg('email'),email),
+    is_email_verified = coalesce(sqlc.narg('is_email_verified'),is_email_verified)
 where username = sqlc.arg('username')
 returning *;
\ No newline at end of file
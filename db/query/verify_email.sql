@@ -0,0 +1,17 @@
+-- name: CreateVerifyEmail :one
+insert into verify_emails (
+    username, email, secret_code
+) VALUES (
+    $1,$2,$3
+)
+returning *;
+
+-- name: UseVerifyEmail :one
+-- a code is used once, before it expires
+update verify_emails
+set is_used = true
+where id = sqlc.arg(id)
+    and secret_code = sqlc.arg(secret_code)
+    and is_used = false
+    and expired_at > now()
+returning *;
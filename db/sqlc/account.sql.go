@@ -7,13 +7,15 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
 update accounts
 set balance = balance + $1
 where id = $2
-returning id, owner, balance, currency, created_at
+returning id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, status
 `
 
 type AddAccountBalanceParams struct {
@@ -30,6 +32,39 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Status,
+	)
+	return i, err
+}
+
+const addAccountHeldAmount = `-- name: AddAccountHeldAmount :one
+update accounts
+set held_amount = held_amount + $1
+where id = $2
+returning id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, status
+`
+
+type AddAccountHeldAmountParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountHeldAmount(ctx context.Context, arg AddAccountHeldAmountParams) (Account, error) {
+	row := q.db.QueryRow(ctx, addAccountHeldAmount, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Status,
 	)
 	return i, err
 }
@@ -40,7 +75,7 @@ insert into accounts (
 ) VALUES (
   $1,$2,$3
 )
-returning id, owner, balance, currency, created_at
+returning id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, status
 `
 
 type CreateAccountParams struct {
@@ -58,6 +93,10 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Status,
 	)
 	return i, err
 }
@@ -73,7 +112,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-select id, owner, balance, currency, created_at from accounts
+select id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, status from accounts
 where id= $1 limit 1
 `
 
@@ -86,12 +125,16 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Status,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-select id, owner, balance, currency, created_at from accounts
+select id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, status from accounts
 where id= $1 limit 1
 FOR NO KEY UPDATE
 `
@@ -105,26 +148,59 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getCashAccount = `-- name: GetCashAccount :one
+select id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, status from accounts
+where owner = '@bank' and currency = $1 limit 1
+`
+
+func (q *Queries) GetCashAccount(ctx context.Context, currency string) (Account, error) {
+	row := q.db.QueryRow(ctx, getCashAccount, currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Status,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-select id, owner, balance, currency, created_at from accounts
+select id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, status from accounts
 where owner = $1
-order by id
-LIMIT $2
-offset $3
+  and ($2::timestamptz is null
+    or (created_at, id) > ($2, $3::bigint))
+order by created_at, id
+LIMIT $4
 `
 
 type ListAccountsParams struct {
-	Owner  string `json:"owner"`
-	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
+	Owner          string             `json:"owner"`
+	AfterCreatedAt pgtype.Timestamptz `json:"after_created_at"`
+	AfterID        pgtype.Int8        `json:"after_id"`
+	Limit          int32              `json:"limit"`
 }
 
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.Query(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listAccounts,
+		arg.Owner,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -138,6 +214,10 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.HeldAmount,
+			&i.AvailableBalance,
+			&i.Status,
 		); err != nil {
 			return nil, err
 		}
@@ -149,20 +229,49 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
-const updateAccount = `-- name: UpdateAccount :one
+const updateAccountOverdraftLimit = `-- name: UpdateAccountOverdraftLimit :one
 update accounts
-set balance = $2
-where id = $1
-returning id, owner, balance, currency, created_at
+set overdraft_limit = $1
+where id = $2
+returning id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, status
+`
+
+type UpdateAccountOverdraftLimitParams struct {
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	ID             int64 `json:"id"`
+}
+
+func (q *Queries) UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error) {
+	row := q.db.QueryRow(ctx, updateAccountOverdraftLimit, arg.OverdraftLimit, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Status,
+	)
+	return i, err
+}
+
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+update accounts
+set status = $1
+where id = $2
+returning id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, status
 `
 
-type UpdateAccountParams struct {
-	ID      int64 `json:"id"`
-	Balance int64 `json:"balance"`
+type UpdateAccountStatusParams struct {
+	Status string `json:"status"`
+	ID     int64  `json:"id"`
 }
 
-func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
-	row := q.db.QueryRow(ctx, updateAccount, arg.ID, arg.Balance)
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	row := q.db.QueryRow(ctx, updateAccountStatus, arg.Status, arg.ID)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -170,6 +279,10 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Status,
 	)
 	return i, err
 }
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Statuses of the accounts. A frozen account can still be credited, a closed account can't move money at all.
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+var (
+	// ErrAccountFrozen is returned when debiting a frozen account
+	ErrAccountFrozen = errors.New("account is frozen")
+	// ErrAccountClosed is returned when debiting or crediting a closed account
+	ErrAccountClosed = errors.New("account is closed")
+	// ErrAccountNotEmpty is returned when closing an account whose balance or held amount isn't zero
+	ErrAccountNotEmpty = errors.New("account balance must be zero")
+	// ErrInvalidStatusChange is returned by UpdateAccountStatusTx for a change the lifecycle doesn't allow
+	ErrInvalidStatusChange = errors.New("invalid account status change")
+)
+
+type UpdateAccountStatusTxParam struct {
+	AccountID int64  `json:"account_id"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason"`
+	// ChangedBy is the banker changing the status
+	ChangedBy string `json:"changed_by"`
+}
+
+type UpdateAccountStatusTxResult struct {
+	Account Account             `json:"account"`
+	Change  AccountStatusChange `json:"change"`
+}
+
+// UpdateAccountStatusTx freezes, closes or reopens the account and records the change with its reason.
+// An account is closed only once its balance and held amount are zero, a closed account is reopened as active.
+func (store *SQLStore) UpdateAccountStatusTx(ctx context.Context, arg UpdateAccountStatusTxParam) (UpdateAccountStatusTxResult, error) {
+	var result UpdateAccountStatusTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// the lock keeps the balance checked below from changing before the account is closed
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		if account.Owner == CashAccountOwner {
+			return fmt.Errorf("%w: account [%d]", ErrCashAccount, account.ID)
+		}
+		if account.Status == arg.Status {
+			return fmt.Errorf("%w: account [%d] is already %s", ErrInvalidStatusChange, account.ID, account.Status)
+		}
+		if account.Status == AccountStatusClosed && arg.Status != AccountStatusActive {
+			return fmt.Errorf("%w: account [%d] is closed, it can only be reopened", ErrInvalidStatusChange, account.ID)
+		}
+		if arg.Status == AccountStatusClosed && (account.Balance != 0 || account.HeldAmount != 0) {
+			return fmt.Errorf("%w: account [%d] has a balance of %d and %d held",
+				ErrAccountNotEmpty, account.ID, account.Balance, account.HeldAmount)
+		}
+
+		result.Account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			Status: arg.Status,
+			ID:     account.ID,
+		})
+		if err != nil {
+			return err
+		}
+		result.Change, err = q.CreateAccountStatusChange(ctx, CreateAccountStatusChangeParams{
+			AccountID:  account.ID,
+			FromStatus: account.Status,
+			ToStatus:   arg.Status,
+			Reason:     arg.Reason,
+			ChangedBy:  arg.ChangedBy,
+		})
+		if err != nil {
+			return err
+		}
+		if err = enqueueEvent(ctx, q, EventAccountStatusChanged, EventAggregateAccount, account.ID, result.Account); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditAccountStatus, AuditResourceAccount, account.ID, account, result)
+	})
+
+	return result, err
+}
+
+// checkDebit fails when money can't leave the account, the account must be locked by the caller
+func checkDebit(account Account) error {
+	switch account.Status {
+	case AccountStatusFrozen:
+		return fmt.Errorf("%w: account [%d] cannot be debited", ErrAccountFrozen, account.ID)
+	case AccountStatusClosed:
+		return fmt.Errorf("%w: account [%d] cannot be debited", ErrAccountClosed, account.ID)
+	}
+	return nil
+}
+
+// checkCredit fails when money can't enter the account, the account must be locked by the caller
+func checkCredit(account Account) error {
+	if account.Status == AccountStatusClosed {
+		return fmt.Errorf("%w: account [%d] cannot be credited", ErrAccountClosed, account.ID)
+	}
+	return nil
+}
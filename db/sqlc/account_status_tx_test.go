@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func changeAccountStatus(t *testing.T, account Account, status string) (UpdateAccountStatusTxResult, error) {
+	banker := createRandomUser(t)
+	return testStore.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusTxParam{
+		AccountID: account.ID,
+		Status:    status,
+		Reason:    "investigation",
+		ChangedBy: banker.Username,
+	})
+}
+
+func TestFreezeAccount(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	result, err := changeAccountStatus(t, account1, AccountStatusFrozen)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusFrozen, result.Account.Status)
+	require.Equal(t, AccountStatusActive, result.Change.FromStatus)
+	require.Equal(t, AccountStatusFrozen, result.Change.ToStatus)
+	require.Equal(t, "investigation", result.Change.Reason)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	// a frozen account can still be credited
+	_, err = testStore.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	_, err = changeAccountStatus(t, account1, AccountStatusFrozen)
+	require.ErrorIs(t, err, ErrInvalidStatusChange)
+
+	result, err = changeAccountStatus(t, account1, AccountStatusActive)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusActive, result.Account.Status)
+
+	changes, err := testStore.ListAccountStatusChanges(context.Background(), ListAccountStatusChangesParams{
+		AccountID: account1.ID,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, AccountStatusActive, changes[0].ToStatus)
+}
+
+func TestCloseAndReopenAccount(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := changeAccountStatus(t, account1, AccountStatusClosed)
+	require.ErrorIs(t, err, ErrAccountNotEmpty)
+
+	emptied := fundAccount(t, account1, -account1.Balance)
+	require.Zero(t, emptied.Balance)
+
+	result, err := changeAccountStatus(t, account1, AccountStatusClosed)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, result.Account.Status)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	_, err = changeAccountStatus(t, account1, AccountStatusFrozen)
+	require.ErrorIs(t, err, ErrInvalidStatusChange)
+
+	result, err = changeAccountStatus(t, account1, AccountStatusActive)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusActive, result.Account.Status)
+}
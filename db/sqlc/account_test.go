@@ -44,22 +44,6 @@ func TestQueries_GetAccount(t *testing.T) {
 	require.WithinDuration(t, account1.CreatedAt, account2.CreatedAt, time.Second)
 }
 
-func TestQueries_UpdateAccount(t *testing.T) {
-	account1 := createRandomAccount(t)
-	arg := UpdateAccountParams{
-		ID:      account1.ID,
-		Balance: util.RandomMoney(),
-	}
-	account2, err := testStore.UpdateAccount(context.Background(), arg)
-	require.NoError(t, err)
-	require.NotEmpty(t, account2)
-	require.Equal(t, account1.ID, account2.ID)
-	require.Equal(t, account1.Owner, account2.Owner)
-	require.Equal(t, arg.Balance, account2.Balance)
-	require.Equal(t, account1.Currency, account2.Currency)
-	require.WithinDuration(t, account1.CreatedAt, account2.CreatedAt, time.Second)
-}
-
 func TestQueries_DeleteAccount(t *testing.T) {
 	account1 := createRandomAccount(t)
 	err := testStore.DeleteAccount(context.Background(), account1.ID)
@@ -76,9 +60,8 @@ func TestQueries_GetAccounts(t *testing.T) {
 		lastAccount = createRandomAccount(t)
 	}
 	arg := ListAccountsParams{
-		Owner:  lastAccount.Owner,
-		Limit:  5,
-		Offset: 0,
+		Owner: lastAccount.Owner,
+		Limit: 5,
 	}
 
 	accounts, err := testStore.ListAccounts(context.Background(), arg)
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Actor is who performs a mutation, it is recorded on the audit events the mutation writes
+type Actor struct {
+	Username  string
+	Role      string
+	ClientIP  string
+	UserAgent string
+}
+
+const (
+	// ActorAnonymous performs the mutations of the public requests, such as a login
+	ActorAnonymous = "anonymous"
+	// ActorSystem performs the mutations made without an actor in the context, those of the background jobs
+	ActorSystem = "system"
+)
+
+type actorKey struct{}
+
+// WithActor returns a context whose mutations are audited as performed by actor
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor of the context, the system actor when it has none
+func ActorFrom(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorKey{}).(Actor); ok {
+		return actor
+	}
+	return Actor{Username: ActorSystem}
+}
+
+// Types of the resources the audit events are about
+const (
+	AuditResourceUser              = "user"
+	AuditResourceAccount           = "account"
+	AuditResourceTransfer          = "transfer"
+	AuditResourceScheduledTransfer = "scheduled_transfer"
+	AuditResourceSession           = "session"
+	AuditResourceToken             = "token"
+)
+
+// Actions of the audit events
+const (
+	AuditUserCreate              = "user.create"
+	AuditUserUpdate              = "user.update"
+	AuditUserEmailVerify         = "user.email.verify"
+	AuditUserSessionsBlock       = "user.sessions.block"
+	AuditUserTokensRevoke        = "user.tokens.revoke"
+	AuditAccountCreate           = "account.create"
+	AuditAccountDelete           = "account.delete"
+	AuditAccountStatus           = "account.status"
+	AuditAccountOverdraftLimit   = "account.overdraft_limit"
+	AuditTransferCreate          = "transfer.create"
+	AuditTransferDeposit         = "transfer.deposit"
+	AuditTransferWithdraw        = "transfer.withdraw"
+	AuditTransferReverse         = "transfer.reverse"
+	AuditTransferAuthorize       = "transfer.authorize"
+	AuditTransferCapture         = "transfer.capture"
+	AuditTransferVoid            = "transfer.void"
+	AuditTransferExpire          = "transfer.expire"
+	AuditScheduledTransferCreate = "scheduled_transfer.create"
+	AuditScheduledTransferUpdate = "scheduled_transfer.update"
+	AuditScheduledTransferDelete = "scheduled_transfer.delete"
+	AuditScheduledTransferRun    = "scheduled_transfer.run"
+	AuditSessionCreate           = "session.create"
+	AuditSessionRotate           = "session.rotate"
+	AuditSessionBlock            = "session.block"
+	AuditSessionFamilyBlock      = "session.family.block"
+	AuditTokenRevoke             = "token.revoke"
+)
+
+// redacted replaces the secrets in the states recorded by the audit events
+const redacted = "[redacted]"
+
+// recordAudit writes the audit event of a mutation made through q. It must be called within the
+// transaction of the mutation, so the event is committed or rolled back with it.
+// A nil before or after is recorded as null.
+func recordAudit(ctx context.Context, q *Queries, action, resourceType string, resourceID any, before, after any) error {
+	actor := ActorFrom(ctx)
+	arg := CreateAuditEventParams{
+		Actor:        actor.Username,
+		ActorRole:    actor.Role,
+		ClientIp:     actor.ClientIP,
+		UserAgent:    actor.UserAgent,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   fmt.Sprint(resourceID),
+	}
+
+	var err error
+	if before != nil {
+		if arg.Before, err = json.Marshal(before); err != nil {
+			return fmt.Errorf("cannot marshal the state before %s: %w", action, err)
+		}
+	}
+	if after != nil {
+		if arg.After, err = json.Marshal(after); err != nil {
+			return fmt.Errorf("cannot marshal the state after %s: %w", action, err)
+		}
+	}
+
+	_, err = q.CreateAuditEvent(ctx, arg)
+	return err
+}
+
+func redactedUser(user User) User {
+	user.HashedPassword = redacted
+	return user
+}
+
+func redactedSession(session Session) Session {
+	session.RefreshToken = redacted
+	return session
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/fayca121/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+)
+
+func listResourceEvents(t *testing.T, resourceType string, resourceID string) []AuditEvent {
+	events, err := testStore.ListAuditEvents(context.Background(), AuditFilter{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+	}.AuditEventsParams(Keyset{}, 10))
+	require.NoError(t, err)
+	return events
+}
+
+func TestAuditAccountLifecycle(t *testing.T) {
+	user := createRandomUser(t)
+	actor := Actor{
+		Username:  user.Username,
+		Role:      util.BankerRole.String(),
+		ClientIP:  "10.0.0.1",
+		UserAgent: "test-agent",
+	}
+	ctx := WithActor(context.Background(), actor)
+
+	account, err := testStore.CreateAccount(ctx, CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  0,
+		Currency: util.RandomCurrency(),
+	})
+	require.NoError(t, err)
+	err = testStore.DeleteAccount(ctx, account.ID)
+	require.NoError(t, err)
+
+	events := listResourceEvents(t, AuditResourceAccount, strconv.FormatInt(account.ID, 10))
+	require.Len(t, events, 2)
+
+	created := events[0]
+	require.Equal(t, AuditAccountCreate, created.Action)
+	require.Equal(t, actor.Username, created.Actor)
+	require.Equal(t, actor.Role, created.ActorRole)
+	require.Equal(t, actor.ClientIP, created.ClientIp)
+	require.Equal(t, actor.UserAgent, created.UserAgent)
+	require.Nil(t, created.Before)
+	var after Account
+	require.NoError(t, json.Unmarshal(created.After, &after))
+	require.Equal(t, account.ID, after.ID)
+
+	deleted := events[1]
+	require.Equal(t, AuditAccountDelete, deleted.Action)
+	require.NotNil(t, deleted.Before)
+	require.Nil(t, deleted.After)
+}
+
+func TestAuditUpdateUser(t *testing.T) {
+	user := createRandomUser(t)
+
+	// without an actor in the context the mutation is audited as made by the system
+	updated, err := testStore.UpdateUser(context.Background(), UpdateUserParams{
+		FullName: pgtype.Text{String: util.RandomOwner(), Valid: true},
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+
+	events := listResourceEvents(t, AuditResourceUser, user.Username)
+	require.Len(t, events, 2)
+	require.Equal(t, AuditUserCreate, events[0].Action)
+
+	event := events[1]
+	require.Equal(t, AuditUserUpdate, event.Action)
+	require.Equal(t, ActorSystem, event.Actor)
+
+	var before, after User
+	require.NoError(t, json.Unmarshal(event.Before, &before))
+	require.NoError(t, json.Unmarshal(event.After, &after))
+	require.Equal(t, user.FullName, before.FullName)
+	require.Equal(t, updated.FullName, after.FullName)
+	require.Equal(t, redacted, before.HashedPassword)
+	require.Equal(t, redacted, after.HashedPassword)
+}
+
+func TestAuditRolledBackMutation(t *testing.T) {
+	account := createRandomAccount(t)
+
+	_, err := changeAccountStatus(t, account, AccountStatusClosed)
+	require.ErrorIs(t, err, ErrAccountNotEmpty)
+
+	// the event is rolled back with the failed mutation
+	events := listResourceEvents(t, AuditResourceAccount, strconv.FormatInt(account.ID, 10))
+	require.Len(t, events, 1)
+	require.Equal(t, AuditAccountCreate, events[0].Action)
+}
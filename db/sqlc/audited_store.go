@@ -0,0 +1,223 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// The methods below override the queries of the mutations made by the handlers outside of a
+// transaction: each one runs its query and records its audit event in the same transaction.
+
+// CreateUser creates the user along with the task sending it the code that verifies its email
+func (store *SQLStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+		if err = enqueueTask(ctx, q, TaskSendVerifyEmail, SendVerifyEmailPayload{Username: user.Username}); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditUserCreate, AuditResourceUser, user.Username, nil, redactedUser(user))
+	})
+
+	return user, err
+}
+
+// UpdateUser updates the user, a new email has to be verified again
+func (store *SQLStore) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetUserForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		emailChanged := arg.Email.Valid && arg.Email.String != before.Email
+		if emailChanged {
+			arg.IsEmailVerified = pgtype.Bool{Bool: false, Valid: true}
+		}
+		user, err = q.UpdateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+		if emailChanged {
+			if err = enqueueTask(ctx, q, TaskSendVerifyEmail, SendVerifyEmailPayload{Username: user.Username}); err != nil {
+				return err
+			}
+		}
+		return recordAudit(ctx, q, AuditUserUpdate, AuditResourceUser, user.Username, redactedUser(before), redactedUser(user))
+	})
+
+	return user, err
+}
+
+func (store *SQLStore) RevokeUserTokens(ctx context.Context, arg RevokeUserTokensParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		if err := q.RevokeUserTokens(ctx, arg); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditUserTokensRevoke, AuditResourceUser, arg.Username, nil, arg)
+	})
+}
+
+func (store *SQLStore) CreateRevokedToken(ctx context.Context, arg CreateRevokedTokenParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		if err := q.CreateRevokedToken(ctx, arg); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditTokenRevoke, AuditResourceToken, arg.ID, nil, arg)
+	})
+}
+
+func (store *SQLStore) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.CreateAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditAccountCreate, AuditResourceAccount, account.ID, nil, account)
+	})
+
+	return account, err
+}
+
+func (store *SQLStore) DeleteAccount(ctx context.Context, id int64) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err = q.DeleteAccount(ctx, id); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditAccountDelete, AuditResourceAccount, id, before, nil)
+	})
+}
+
+// UpdateAccountOverdraftLimit sets how far below zero the available balance of the account may go,
+// the cash accounts have no limit
+func (store *SQLStore) UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetAccountForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		if before.Owner == CashAccountOwner {
+			return fmt.Errorf("%w: account [%d]", ErrCashAccount, before.ID)
+		}
+		account, err = q.UpdateAccountOverdraftLimit(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditAccountOverdraftLimit, AuditResourceAccount, account.ID, before, account)
+	})
+
+	return account, err
+}
+
+func (store *SQLStore) CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error) {
+	var scheduled ScheduledTransfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		scheduled, err = q.CreateScheduledTransfer(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditScheduledTransferCreate, AuditResourceScheduledTransfer, scheduled.ID, nil, scheduled)
+	})
+
+	return scheduled, err
+}
+
+func (store *SQLStore) UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error) {
+	var scheduled ScheduledTransfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetScheduledTransferForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		scheduled, err = q.UpdateScheduledTransfer(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditScheduledTransferUpdate, AuditResourceScheduledTransfer, scheduled.ID, before, scheduled)
+	})
+
+	return scheduled, err
+}
+
+func (store *SQLStore) DeleteScheduledTransfer(ctx context.Context, id int64) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetScheduledTransferForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err = q.DeleteScheduledTransfer(ctx, id); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditScheduledTransferDelete, AuditResourceScheduledTransfer, id, before, nil)
+	})
+}
+
+func (store *SQLStore) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	var session Session
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		session, err = q.CreateSession(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditSessionCreate, AuditResourceSession, session.ID, nil, redactedSession(session))
+	})
+
+	return session, err
+}
+
+func (store *SQLStore) BlockSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	var session Session
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetSession(ctx, id)
+		if err != nil {
+			return err
+		}
+		session, err = q.BlockSession(ctx, id)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditSessionBlock, AuditResourceSession, id, redactedSession(before), redactedSession(session))
+	})
+
+	return session, err
+}
+
+func (store *SQLStore) BlockUserSessions(ctx context.Context, username string) (int64, error) {
+	var blocked int64
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		blocked, err = q.BlockUserSessions(ctx, username)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditUserSessionsBlock, AuditResourceUser, username, nil, map[string]int64{
+			"blocked_sessions": blocked,
+		})
+	})
+
+	return blocked, err
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fayca121/simplebank/fx"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CashAccountOwner is the user owning the cash account of each currency. Deposits are transfers
+// from the cash account of the currency, withdrawals are transfers to it. The username starts with
+// a character the usernames of the customers can't start with.
+const CashAccountOwner = "@bank"
+
+// Kinds of the transfers
+const (
+	TransferKindTransfer   = "transfer"
+	TransferKindDeposit    = "deposit"
+	TransferKindWithdrawal = "withdrawal"
+)
+
+// ErrCashAccount is returned when a deposit, a withdrawal or a transfer targets a cash account
+var ErrCashAccount = errors.New("cash accounts can't be credited or debited directly")
+
+// checkNotCash fails on a cash account, money only enters and leaves them through deposits and withdrawals
+func checkNotCash(account Account) error {
+	if account.Owner == CashAccountOwner {
+		return fmt.Errorf("%w: account [%d]", ErrCashAccount, account.ID)
+	}
+	return nil
+}
+
+type CashTxParam struct {
+	AccountID int64 `json:"account_id"`
+	// Amount is positive, in the currency of the account
+	Amount int64 `json:"amount"`
+}
+
+type CashTxResult struct {
+	Transfer    Transfer `json:"transfer"`
+	Account     Account  `json:"account"`
+	Entry       Entry    `json:"entry"`
+	CashAccount Account  `json:"cash_account"`
+	CashEntry   Entry    `json:"cash_entry"`
+}
+
+// DepositTx credits the account with cash: the amount is moved from the cash account of its currency
+func (store *SQLStore) DepositTx(ctx context.Context, arg CashTxParam) (CashTxResult, error) {
+	return store.cashTx(ctx, arg, TransferKindDeposit)
+}
+
+// WithdrawTx pays cash out of the account: the amount is moved to the cash account of its currency.
+// It fails with ErrInsufficientFunds when the account would go below its overdraft limit, and with
+// ErrAccountFrozen or ErrAccountClosed when money can't leave it.
+func (store *SQLStore) WithdrawTx(ctx context.Context, arg CashTxParam) (CashTxResult, error) {
+	return store.cashTx(ctx, arg, TransferKindWithdrawal)
+}
+
+func (store *SQLStore) cashTx(ctx context.Context, arg CashTxParam, kind string) (CashTxResult, error) {
+	var result CashTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		if err = checkNotCash(account); err != nil {
+			return err
+		}
+		cashAccount, err := cashAccountOf(ctx, q, account.Currency)
+		if err != nil {
+			return err
+		}
+
+		// the cash account is debited by deposits and credited by withdrawals, it can go below zero
+		fromAccountID, toAccountID := cashAccount.ID, account.ID
+		if kind == TransferKindWithdrawal {
+			fromAccountID, toAccountID = account.ID, cashAccount.ID
+		}
+
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: fromAccountID,
+			ToAccountID:   toAccountID,
+			Amount:        arg.Amount,
+			ToAmount:      arg.Amount,
+			ExchangeRate:  fx.RateScale,
+			Kind:          kind,
+			Status:        TransferStatusPosted,
+		})
+		if err != nil {
+			return err
+		}
+
+		fromEntry, err := q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  fromAccountID,
+			Amount:     -arg.Amount,
+			TransferID: pgtype.Int8{Int64: result.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		toEntry, err := q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  toAccountID,
+			Amount:     arg.Amount,
+			TransferID: pgtype.Int8{Int64: result.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		// the balances are updated in the order of the ids, like in transferTx, to avoid deadlocks
+		var fromAccount, toAccount Account
+		if fromAccountID < toAccountID {
+			fromAccount, toAccount, err = addMoney(ctx, q, fromAccountID, -arg.Amount, toAccountID, arg.Amount)
+		} else {
+			toAccount, fromAccount, err = addMoney(ctx, q, toAccountID, arg.Amount, fromAccountID, -arg.Amount)
+		}
+		if err != nil {
+			return err
+		}
+
+		action := AuditTransferDeposit
+		if kind == TransferKindWithdrawal {
+			action = AuditTransferWithdraw
+			result.Account, result.Entry = fromAccount, fromEntry
+			result.CashAccount, result.CashEntry = toAccount, toEntry
+			if err = checkDebit(result.Account); err != nil {
+				return err
+			}
+			if result.Account.AvailableBalance < -result.Account.OverdraftLimit {
+				return fmt.Errorf("%w: account [%d] cannot be debited by %d",
+					ErrInsufficientFunds, account.ID, arg.Amount)
+			}
+		} else {
+			result.Account, result.Entry = toAccount, toEntry
+			result.CashAccount, result.CashEntry = fromAccount, fromEntry
+			if err = checkCredit(result.Account); err != nil {
+				return err
+			}
+		}
+		if err = enqueueTransferEvent(ctx, q, EventTransferPosted, result.Transfer); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, action, AuditResourceTransfer, result.Transfer.ID, nil, result)
+	})
+
+	return result, err
+}
+
+// cashAccountOf returns the cash account of the currency, it is created on the first cash operation
+// of a currency added after the migration seeding the cash accounts
+func cashAccountOf(ctx context.Context, q *Queries, currency string) (Account, error) {
+	account, err := q.GetCashAccount(ctx, currency)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return q.CreateAccount(ctx, CreateAccountParams{
+			Owner:    CashAccountOwner,
+			Balance:  0,
+			Currency: currency,
+		})
+	}
+	return account, err
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestDepositTx(t *testing.T) {
+	account := createRandomAccount(t)
+
+	result, err := testStore.DepositTx(context.Background(), CashTxParam{AccountID: account.ID, Amount: 100})
+	require.NoError(t, err)
+
+	require.Equal(t, TransferKindDeposit, result.Transfer.Kind)
+	require.Equal(t, result.CashAccount.ID, result.Transfer.FromAccountID)
+	require.Equal(t, account.ID, result.Transfer.ToAccountID)
+	require.Equal(t, CashAccountOwner, result.CashAccount.Owner)
+	require.Equal(t, account.Currency, result.CashAccount.Currency)
+
+	require.Equal(t, account.Balance+100, result.Account.Balance)
+	require.Equal(t, int64(100), result.Entry.Amount)
+	require.Equal(t, int64(-100), result.CashEntry.Amount)
+	require.Equal(t, result.Transfer.ID, result.Entry.TransferID.Int64)
+	require.Equal(t, result.Transfer.ID, result.CashEntry.TransferID.Int64)
+}
+
+func TestWithdrawTx(t *testing.T) {
+	account := createRandomAccount(t)
+
+	result, err := testStore.WithdrawTx(context.Background(), CashTxParam{AccountID: account.ID, Amount: account.Balance})
+	require.NoError(t, err)
+
+	require.Equal(t, TransferKindWithdrawal, result.Transfer.Kind)
+	require.Equal(t, account.ID, result.Transfer.FromAccountID)
+	require.Equal(t, result.CashAccount.ID, result.Transfer.ToAccountID)
+	require.Zero(t, result.Account.Balance)
+	require.Equal(t, -account.Balance, result.Entry.Amount)
+	require.Equal(t, account.Balance, result.CashEntry.Amount)
+
+	// the account has no overdraft
+	_, err = testStore.WithdrawTx(context.Background(), CashTxParam{AccountID: account.ID, Amount: 1})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updated, err := testStore.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Zero(t, updated.Balance)
+}
+
+func TestCashTxOnCashAccount(t *testing.T) {
+	account := createRandomAccount(t)
+	result, err := testStore.DepositTx(context.Background(), CashTxParam{AccountID: account.ID, Amount: 10})
+	require.NoError(t, err)
+
+	_, err = testStore.DepositTx(context.Background(), CashTxParam{AccountID: result.CashAccount.ID, Amount: 10})
+	require.ErrorIs(t, err, ErrCashAccount)
+}
+
+func TestTransferTxOnCashAccount(t *testing.T) {
+	account := createRandomAccount(t)
+	result, err := testStore.DepositTx(context.Background(), CashTxParam{AccountID: account.ID, Amount: 10})
+	require.NoError(t, err)
+	cashAccount := result.CashAccount
+
+	// a transfer into a cash account would be a withdrawal, one out of it a deposit
+	_, err = testStore.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account.ID,
+		ToAccountID:   cashAccount.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrCashAccount)
+	_, err = testStore.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: cashAccount.ID,
+		ToAccountID:   account.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrCashAccount)
+
+	_, err = testStore.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParam{
+		FromAccountID: account.ID,
+		ToAccountID:   cashAccount.ID,
+		Amount:        10,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrCashAccount)
+	_, err = testStore.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParam{
+		FromAccountID: cashAccount.ID,
+		ToAccountID:   account.ID,
+		Amount:        10,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrCashAccount)
+
+	updated, err := testStore.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Account.Balance, updated.Balance)
+	require.Equal(t, result.Account.HeldAmount, updated.HeldAmount)
+}
@@ -0,0 +1,24 @@
+package db
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestQueries_ListCurrencies(t *testing.T) {
+	currencies, err := testStore.ListCurrencies(context.Background())
+	require.NoError(t, err)
+
+	// the built-in currencies are seeded by the migration
+	byCode := make(map[string]Currency)
+	for _, currency := range currencies {
+		byCode[currency.Code] = currency
+	}
+	for _, code := range []string{util.USD, util.EUR, util.CAD} {
+		require.Contains(t, byCode, code)
+		require.Equal(t, int32(2), byCode[code].MinorUnits)
+		require.True(t, byCode[code].Enabled)
+	}
+}
@@ -7,36 +7,42 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
     account_id,
-    amount
+    amount,
+    transfer_id
 ) VALUES (
- $1, $2
-) RETURNING id, account_id, amount, created_at
+ $1, $2, $3
+) RETURNING id, account_id, amount, created_at, transfer_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID  int64       `json:"account_id"`
+	Amount     int64       `json:"amount"`
+	TransferID pgtype.Int8 `json:"transfer_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRow(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createEntry, arg.AccountID, arg.Amount, arg.TransferID)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -48,26 +54,118 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
 	)
 	return i, err
 }
 
+const getOpeningBalance = `-- name: GetOpeningBalance :one
+SELECT (a.balance - COALESCE((
+    SELECT SUM(e.amount) FROM entries e
+    WHERE e.account_id = a.id
+      AND e.created_at >= $1
+), 0))::bigint AS opening_balance
+FROM accounts a
+WHERE a.id = $2
+`
+
+type GetOpeningBalanceParams struct {
+	Since     time.Time `json:"since"`
+	AccountID int64     `json:"account_id"`
+}
+
+func (q *Queries) GetOpeningBalance(ctx context.Context, arg GetOpeningBalanceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getOpeningBalance, arg.Since, arg.AccountID)
+	var opening_balance int64
+	err := row.Scan(&opening_balance)
+	return opening_balance, err
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
-WHERE account_id = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3
+SELECT e.id, e.account_id, e.amount, e.created_at, e.transfer_id FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE e.account_id = $1
+  AND ($2::text = 'both'
+    OR ($2::text = 'incoming' AND e.amount > 0)
+    OR ($2::text = 'outgoing' AND e.amount < 0))
+  AND ($3::bigint IS NULL
+    OR $3::bigint = CASE WHEN t.from_account_id = e.account_id THEN t.to_account_id ELSE t.from_account_id END)
+  AND ($4::timestamptz IS NULL OR e.created_at >= $4)
+  AND ($5::timestamptz IS NULL OR e.created_at < $5)
+  AND ($6::bigint IS NULL OR abs(e.amount) >= $6)
+  AND ($7::bigint IS NULL OR abs(e.amount) <= $7)
+  AND ($8::timestamptz IS NULL
+    OR (e.created_at, e.id) > ($8, $9::bigint))
+ORDER BY e.created_at, e.id
+LIMIT $10
 `
 
 type ListEntriesParams struct {
-	AccountID int64 `json:"account_id"`
-	Limit     int32 `json:"limit"`
-	Offset    int32 `json:"offset"`
+	AccountID      int64              `json:"account_id"`
+	Direction      string             `json:"direction"`
+	CounterpartyID pgtype.Int8        `json:"counterparty_id"`
+	CreatedFrom    pgtype.Timestamptz `json:"created_from"`
+	CreatedTo      pgtype.Timestamptz `json:"created_to"`
+	MinAmount      pgtype.Int8        `json:"min_amount"`
+	MaxAmount      pgtype.Int8        `json:"max_amount"`
+	AfterCreatedAt pgtype.Timestamptz `json:"after_created_at"`
+	AfterID        pgtype.Int8        `json:"after_id"`
+	Limit          int32              `json:"limit"`
 }
 
 func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
-	rows, err := q.db.Query(ctx, listEntries, arg.AccountID, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listEntries,
+		arg.AccountID,
+		arg.Direction,
+		arg.CounterpartyID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesBetween = `-- name: ListEntriesBetween :many
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
+WHERE account_id = $1
+  AND created_at >= $2
+  AND created_at < $3
+ORDER BY created_at, id
+`
+
+type ListEntriesBetweenParams struct {
+	AccountID int64     `json:"account_id"`
+	FromTime  time.Time `json:"from_time"`
+	ToTime    time.Time `json:"to_time"`
+}
+
+func (q *Queries) ListEntriesBetween(ctx context.Context, arg ListEntriesBetweenParams) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listEntriesBetween, arg.AccountID, arg.FromTime, arg.ToTime)
 	if err != nil {
 		return nil, err
 	}
@@ -80,6 +178,7 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
 		); err != nil {
 			return nil, err
 		}
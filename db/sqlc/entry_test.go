@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"github.com/fayca121/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 	"testing"
 	"time"
@@ -53,16 +54,109 @@ func TestListEntries(t *testing.T) {
 
 	arg := ListEntriesParams{
 		AccountID: account.ID,
+		Direction: DirectionBoth,
 		Limit:     5,
-		Offset:    5,
 	}
 
 	entries, err := testStore.ListEntries(context.Background(), arg)
 	require.NoError(t, err)
 	require.Len(t, entries, 5)
 
-	for _, entry := range entries {
+	// the next page resumes after the last entry of the first one
+	last := entries[len(entries)-1]
+	after := NewKeyset(last.CreatedAt, last.ID)
+	arg.AfterCreatedAt, arg.AfterID = after.CreatedAt, after.ID
+	next, err := testStore.ListEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, next, 5)
+
+	for _, entry := range next {
 		require.NotEmpty(t, entry)
 		require.Equal(t, arg.AccountID, entry.AccountID)
+		require.NotContains(t, entries, entry)
+	}
+}
+
+func TestListEntriesFilters(t *testing.T) {
+	account := createRandomAccount(t)
+	for _, amount := range []int64{-300, -100, 50, 200, 400} {
+		_, err := testStore.CreateEntry(context.Background(), CreateEntryParams{AccountID: account.ID, Amount: amount})
+		require.NoError(t, err)
+	}
+
+	arg := ListEntriesParams{
+		AccountID: account.ID,
+		Direction: DirectionIncoming,
+		Limit:     10,
+	}
+	entries, err := testStore.ListEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	// the amount range applies to the absolute value of the entries
+	arg.Direction = DirectionBoth
+	arg.MinAmount = pgtype.Int8{Int64: 100, Valid: true}
+	arg.MaxAmount = pgtype.Int8{Int64: 300, Valid: true}
+	entries, err = testStore.ListEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	arg.Direction = DirectionOutgoing
+	entries, err = testStore.ListEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	arg.CreatedFrom = pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true}
+	entries, err = testStore.ListEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestListEntriesBetween(t *testing.T) {
+	account := createRandomAccount(t)
+	from := time.Now()
+	entries := make([]Entry, 0, 5)
+	for i := 0; i < 5; i++ {
+		entries = append(entries, createRandomEntry(t, account))
+	}
+	to := time.Now().Add(time.Second)
+
+	listed, err := testStore.ListEntriesBetween(context.Background(), ListEntriesBetweenParams{
+		AccountID: account.ID,
+		FromTime:  from.Add(-time.Second),
+		ToTime:    to,
+	})
+	require.NoError(t, err)
+	require.Len(t, listed, len(entries))
+	for i, entry := range listed {
+		require.Equal(t, entries[i].ID, entry.ID)
 	}
+
+	listed, err = testStore.ListEntriesBetween(context.Background(), ListEntriesBetweenParams{
+		AccountID: account.ID,
+		FromTime:  to,
+		ToTime:    to.Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Empty(t, listed)
+}
+
+func TestGetOpeningBalance(t *testing.T) {
+	account := createRandomAccount(t)
+	entry := createRandomEntry(t, account)
+
+	// the balance of the account doesn't include the entries created since the start of the period
+	opening, err := testStore.GetOpeningBalance(context.Background(), GetOpeningBalanceParams{
+		Since:     entry.CreatedAt,
+		AccountID: account.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance-entry.Amount, opening)
+
+	opening, err = testStore.GetOpeningBalance(context.Background(), GetOpeningBalanceParams{
+		Since:     entry.CreatedAt.Add(time.Second),
+		AccountID: account.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, opening)
 }
@@ -8,8 +8,20 @@ import (
 const (
 	ForeignKeyViolation = "23503"
 	UniqueViolation     = "23505"
+	LockNotAvailable    = "55P03"
 )
 
+// ErrInsufficientFunds is returned by TransferTx when a debit would take the
+// source account below its overdraft limit.
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// ErrIdempotencyKeyConflict is returned when an idempotency key is reused with a different request.
+var ErrIdempotencyKeyConflict = errors.New("idempotency key already used with a different request")
+
+// ErrRefreshTokenReused is returned by RotateSessionTx when the refresh token of the session has
+// already been exchanged, the whole session family is revoked.
+var ErrRefreshTokenReused = errors.New("refresh token has already been used")
+
 func ErrCode(err error) string {
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) {
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
+
+// Directions of the entries and transfers listed for an account
+const (
+	DirectionBoth     = "both"
+	DirectionIncoming = "incoming"
+	DirectionOutgoing = "outgoing"
+)
+
+// ListFilter holds the optional filters of ListEntries and ListTransfers, nil fields don't filter
+// and an empty Direction lists both directions
+type ListFilter struct {
+	Direction      string
+	CounterpartyID *int64
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+	MinAmount      *int64
+	MaxAmount      *int64
+}
+
+// Keyset is the (created_at, id) of the last row of a page, a listing resumes after it.
+// The zero Keyset lists from the first row.
+type Keyset struct {
+	CreatedAt pgtype.Timestamptz
+	ID        pgtype.Int8
+}
+
+func NewKeyset(createdAt time.Time, id int64) Keyset {
+	return Keyset{
+		CreatedAt: pgtype.Timestamptz{Time: createdAt, Valid: true},
+		ID:        pgtype.Int8{Int64: id, Valid: true},
+	}
+}
+
+func (filter ListFilter) EntriesParams(accountID int64, after Keyset, limit int32) ListEntriesParams {
+	return ListEntriesParams{
+		AccountID:      accountID,
+		Direction:      filter.direction(),
+		CounterpartyID: int8Of(filter.CounterpartyID),
+		CreatedFrom:    timestamptzOf(filter.CreatedFrom),
+		CreatedTo:      timestamptzOf(filter.CreatedTo),
+		MinAmount:      int8Of(filter.MinAmount),
+		MaxAmount:      int8Of(filter.MaxAmount),
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+		Limit:          limit,
+	}
+}
+
+func (filter ListFilter) TransfersParams(accountID int64, after Keyset, limit int32) ListTransfersParams {
+	return ListTransfersParams{
+		AccountID:      accountID,
+		Direction:      filter.direction(),
+		CounterpartyID: int8Of(filter.CounterpartyID),
+		CreatedFrom:    timestamptzOf(filter.CreatedFrom),
+		CreatedTo:      timestamptzOf(filter.CreatedTo),
+		MinAmount:      int8Of(filter.MinAmount),
+		MaxAmount:      int8Of(filter.MaxAmount),
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+		Limit:          limit,
+	}
+}
+
+// AuditFilter holds the optional filters of ListAuditEvents, empty strings and nil times don't filter
+type AuditFilter struct {
+	Actor        string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+}
+
+func (filter AuditFilter) AuditEventsParams(after Keyset, limit int32) ListAuditEventsParams {
+	return ListAuditEventsParams{
+		Actor:          textOf(filter.Actor),
+		Action:         textOf(filter.Action),
+		ResourceType:   textOf(filter.ResourceType),
+		ResourceID:     textOf(filter.ResourceID),
+		CreatedFrom:    timestamptzOf(filter.CreatedFrom),
+		CreatedTo:      timestamptzOf(filter.CreatedTo),
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+		Limit:          limit,
+	}
+}
+
+func (filter ListFilter) direction() string {
+	if filter.Direction == "" {
+		return DirectionBoth
+	}
+	return filter.Direction
+}
+
+func int8Of(value *int64) pgtype.Int8 {
+	if value == nil {
+		return pgtype.Int8{}
+	}
+	return pgtype.Int8{Int64: *value, Valid: true}
+}
+
+func timestamptzOf(value *time.Time) pgtype.Timestamptz {
+	if value == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *value, Valid: true}
+}
+
+func textOf(value string) pgtype.Text {
+	if value == "" {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: value, Valid: true}
+}
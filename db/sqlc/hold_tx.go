@@ -0,0 +1,283 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fayca121/simplebank/fx"
+	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
+
+// Statuses of the transfers. A transfer is posted when its entries are written, an authorized
+// transfer stays pending, holding its amount on the source account, until it is captured, voided or expires.
+const (
+	TransferStatusPosted  = "posted"
+	TransferStatusPending = "pending"
+	TransferStatusVoided  = "voided"
+	TransferStatusExpired = "expired"
+)
+
+const (
+	// DefaultHoldDuration is how long an authorization holds the funds when no expiry is given
+	DefaultHoldDuration = 7 * 24 * time.Hour
+	// MaxHoldDuration bounds the expiry of an authorization
+	MaxHoldDuration = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrTransferNotPending is returned when capturing or voiding a transfer already posted, voided or expired
+	ErrTransferNotPending = errors.New("transfer is not pending")
+	// ErrHoldExpired is returned when capturing a transfer whose hold has expired
+	ErrHoldExpired = errors.New("hold has expired")
+	// ErrHoldNotExpired is returned by ExpireTransferTx before the expiry of the hold
+	ErrHoldNotExpired = errors.New("hold has not expired")
+)
+
+type AuthorizeTransferTxParam struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// Amount is held on the source account, both accounts hold the same currency
+	Amount    int64     `json:"amount"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type HoldTxResult struct {
+	Transfer    Transfer     `json:"transfer"`
+	Hold        TransferHold `json:"hold"`
+	FromAccount Account      `json:"from_account"`
+}
+
+// AuthorizeTransferTx creates a pending transfer and holds its amount on the source account: the available
+// balance of the account is reduced, its balance is left untouched until the transfer is captured.
+// It fails with ErrInsufficientFunds when the available balance would go below the overdraft limit, and
+// before holding anything when the destination account can't be credited.
+func (store *SQLStore) AuthorizeTransferTx(ctx context.Context, arg AuthorizeTransferTxParam) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// the accounts are locked in the same order as in transferTx, the destination stays locked
+		// so that it can't be closed before the transfer is created
+		var fromAccount, toAccount Account
+		var err error
+		if arg.FromAccountID < arg.ToAccountID {
+			fromAccount, toAccount, err = lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+		} else {
+			toAccount, fromAccount, err = lockAccounts(ctx, q, arg.ToAccountID, arg.FromAccountID)
+		}
+		if err != nil {
+			return err
+		}
+		if err = checkNotCash(fromAccount); err != nil {
+			return err
+		}
+		if err = checkNotCash(toAccount); err != nil {
+			return err
+		}
+		if err = checkCredit(toAccount); err != nil {
+			return err
+		}
+
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			ToAmount:      arg.Amount,
+			ExchangeRate:  fx.RateScale,
+			Kind:          TransferKindTransfer,
+			Status:        TransferStatusPending,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.CreateTransferHold(ctx, CreateTransferHoldParams{
+			TransferID: result.Transfer.ID,
+			AccountID:  arg.FromAccountID,
+			Amount:     arg.Amount,
+			ExpiresAt:  arg.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+			Amount: arg.Amount,
+			ID:     arg.FromAccountID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err = checkDebit(result.FromAccount); err != nil {
+			return err
+		}
+		if result.FromAccount.AvailableBalance < -result.FromAccount.OverdraftLimit {
+			return fmt.Errorf("%w: account [%d] cannot hold %d",
+				ErrInsufficientFunds, arg.FromAccountID, arg.Amount)
+		}
+		if err = enqueueTransferEvent(ctx, q, EventTransferAuthorized, result.Transfer); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditTransferAuthorize, AuditResourceTransfer, result.Transfer.ID, nil, result)
+	})
+
+	return result, err
+}
+
+// lockAccounts locks both accounts for update, in the given order
+func lockAccounts(ctx context.Context, q *Queries, accountID1, accountID2 int64) (account1 Account, account2 Account, err error) {
+	account1, err = q.GetAccountForUpdate(ctx, accountID1)
+	if err != nil {
+		return
+	}
+	account2, err = q.GetAccountForUpdate(ctx, accountID2)
+	return
+}
+
+// CaptureTransferTx posts a pending transfer: its hold is released and the amount is moved
+// like in TransferTx. It fails with ErrHoldExpired once the hold has expired.
+func (store *SQLStore) CaptureTransferTx(ctx context.Context, transferID int64) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		transfer, hold, err := pendingTransfer(ctx, q, transferID)
+		if err != nil {
+			return err
+		}
+		if !hold.ExpiresAt.After(time.Now()) {
+			return fmt.Errorf("%w: transfer [%d] expired at %s", ErrHoldExpired, transfer.ID, hold.ExpiresAt)
+		}
+
+		result.Transfer, err = q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{
+			ID:     transfer.ID,
+			Status: TransferStatusPosted,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  transfer.FromAccountID,
+			Amount:     -transfer.Amount,
+			TransferID: pgtype.Int8{Int64: transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  transfer.ToAccountID,
+			Amount:     transfer.ToAmount,
+			TransferID: pgtype.Int8{Int64: transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		// the accounts are locked in the same order as in transferTx, the source account is locked
+		// by then when its held amount is released
+		if transfer.FromAccountID < transfer.ToAccountID {
+			_, result.ToAccount, err = addMoney(ctx, q, transfer.FromAccountID, -transfer.Amount, transfer.ToAccountID, transfer.ToAmount)
+		} else {
+			result.ToAccount, _, err = addMoney(ctx, q, transfer.ToAccountID, transfer.ToAmount, transfer.FromAccountID, -transfer.Amount)
+		}
+		if err != nil {
+			return err
+		}
+		_, result.FromAccount, err = releaseHold(ctx, q, hold)
+		if err != nil {
+			return err
+		}
+
+		if err = checkDebit(result.FromAccount); err != nil {
+			return err
+		}
+		if err = checkCredit(result.ToAccount); err != nil {
+			return err
+		}
+		// the amount was held, the available balance only falls short if the overdraft limit was lowered since
+		if result.FromAccount.AvailableBalance < -result.FromAccount.OverdraftLimit {
+			return fmt.Errorf("%w: account [%d] cannot be debited by %d",
+				ErrInsufficientFunds, transfer.FromAccountID, transfer.Amount)
+		}
+		if err = enqueueTransferEvent(ctx, q, EventTransferPosted, result.Transfer); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditTransferCapture, AuditResourceTransfer, transfer.ID, transfer, result)
+	})
+
+	return result, err
+}
+
+// VoidTransferTx cancels a pending transfer and releases its hold, the money never moves
+func (store *SQLStore) VoidTransferTx(ctx context.Context, transferID int64) (HoldTxResult, error) {
+	return store.cancelTransferTx(ctx, transferID, TransferStatusVoided)
+}
+
+// ExpireTransferTx cancels a pending transfer whose hold has expired, it is run by the hold expiry job
+func (store *SQLStore) ExpireTransferTx(ctx context.Context, transferID int64) (HoldTxResult, error) {
+	return store.cancelTransferTx(ctx, transferID, TransferStatusExpired)
+}
+
+func (store *SQLStore) cancelTransferTx(ctx context.Context, transferID int64, status string) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		transfer, hold, err := pendingTransfer(ctx, q, transferID)
+		if err != nil {
+			return err
+		}
+		if status == TransferStatusExpired && hold.ExpiresAt.After(time.Now()) {
+			return fmt.Errorf("%w: transfer [%d] expires at %s", ErrHoldNotExpired, transfer.ID, hold.ExpiresAt)
+		}
+
+		result.Transfer, err = q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{
+			ID:     transfer.ID,
+			Status: status,
+		})
+		if err != nil {
+			return err
+		}
+		result.Hold, result.FromAccount, err = releaseHold(ctx, q, hold)
+		if err != nil {
+			return err
+		}
+
+		action, eventType := AuditTransferVoid, EventTransferVoided
+		if status == TransferStatusExpired {
+			action, eventType = AuditTransferExpire, EventTransferExpired
+		}
+		if err = enqueueTransferEvent(ctx, q, eventType, result.Transfer); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, action, AuditResourceTransfer, transfer.ID, transfer, result)
+	})
+
+	return result, err
+}
+
+// pendingTransfer locks the transfer, which serializes its capture, void and expiry, and checks that it is pending
+func pendingTransfer(ctx context.Context, q *Queries, transferID int64) (Transfer, TransferHold, error) {
+	transfer, err := q.GetTransferForUpdate(ctx, transferID)
+	if err != nil {
+		return transfer, TransferHold{}, err
+	}
+	if transfer.Status != TransferStatusPending {
+		return transfer, TransferHold{}, fmt.Errorf("%w: transfer [%d] is %s", ErrTransferNotPending, transfer.ID, transfer.Status)
+	}
+	hold, err := q.GetTransferHold(ctx, transfer.ID)
+	return transfer, hold, err
+}
+
+// releaseHold marks the hold released and gives its amount back to the available balance of the account
+func releaseHold(ctx context.Context, q *Queries, hold TransferHold) (TransferHold, Account, error) {
+	released, err := q.ReleaseTransferHold(ctx, hold.TransferID)
+	if err != nil {
+		return released, Account{}, err
+	}
+	account, err := q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+		Amount: -hold.Amount,
+		ID:     hold.AccountID,
+	})
+	return released, account, err
+}
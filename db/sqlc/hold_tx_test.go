@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func authorizeRandomTransfer(t *testing.T, from, to Account, amount int64, expiresAt time.Time) HoldTxResult {
+	result, err := testStore.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParam{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		ExpiresAt:     expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPending, result.Transfer.Status)
+	require.Equal(t, result.Transfer.ID, result.Hold.TransferID)
+	require.Equal(t, amount, result.Hold.Amount)
+	require.False(t, result.Hold.ReleasedAt.Valid)
+	return result
+}
+
+func TestAuthorizeAndCaptureTransferTx(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	authorized := authorizeRandomTransfer(t, account1, account2, 10, time.Now().Add(time.Hour))
+	require.Equal(t, account1.Balance, authorized.FromAccount.Balance)
+	require.Equal(t, account1.HeldAmount+10, authorized.FromAccount.HeldAmount)
+	require.Equal(t, account1.AvailableBalance-10, authorized.FromAccount.AvailableBalance)
+
+	captured, err := testStore.CaptureTransferTx(context.Background(), authorized.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPosted, captured.Transfer.Status)
+	require.Equal(t, int64(-10), captured.FromEntry.Amount)
+	require.Equal(t, int64(10), captured.ToEntry.Amount)
+	require.Equal(t, account1.Balance-10, captured.FromAccount.Balance)
+	require.Equal(t, account1.HeldAmount, captured.FromAccount.HeldAmount)
+	require.Equal(t, account2.Balance+10, captured.ToAccount.Balance)
+
+	hold, err := testStore.GetTransferHold(context.Background(), authorized.Transfer.ID)
+	require.NoError(t, err)
+	require.True(t, hold.ReleasedAt.Valid)
+
+	_, err = testStore.CaptureTransferTx(context.Background(), authorized.Transfer.ID)
+	require.ErrorIs(t, err, ErrTransferNotPending)
+	_, err = testStore.VoidTransferTx(context.Background(), authorized.Transfer.ID)
+	require.ErrorIs(t, err, ErrTransferNotPending)
+}
+
+func TestAuthorizeTransferTxInsufficientFunds(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	authorizeRandomTransfer(t, account1, account2, account1.AvailableBalance, time.Now().Add(time.Hour))
+
+	// the held amount can't be spent by another transfer
+	_, err := testStore.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.OverdraftLimit + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = testStore.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.OverdraftLimit + 1,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestAuthorizeTransferTxToClosedAccount(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	fundAccount(t, account2, -account2.Balance)
+	_, err := changeAccountStatus(t, account2, AccountStatusClosed)
+	require.NoError(t, err)
+
+	_, err = testStore.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	// nothing is held on the payer
+	updated, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.HeldAmount, updated.HeldAmount)
+	require.Equal(t, account1.AvailableBalance, updated.AvailableBalance)
+}
+
+func TestVoidTransferTx(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	authorized := authorizeRandomTransfer(t, account1, account2, 10, time.Now().Add(time.Hour))
+
+	voided, err := testStore.VoidTransferTx(context.Background(), authorized.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusVoided, voided.Transfer.Status)
+	require.True(t, voided.Hold.ReleasedAt.Valid)
+	require.Equal(t, account1.Balance, voided.FromAccount.Balance)
+	require.Equal(t, account1.AvailableBalance, voided.FromAccount.AvailableBalance)
+
+	_, err = testStore.CaptureTransferTx(context.Background(), authorized.Transfer.ID)
+	require.ErrorIs(t, err, ErrTransferNotPending)
+
+	_, err = testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParam{TransferID: authorized.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferNotReversible)
+}
+
+func TestExpireTransferTx(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	pending := authorizeRandomTransfer(t, account1, account2, 10, time.Now().Add(time.Hour))
+	_, err := testStore.ExpireTransferTx(context.Background(), pending.Transfer.ID)
+	require.ErrorIs(t, err, ErrHoldNotExpired)
+
+	expired := authorizeRandomTransfer(t, account1, account2, 10, time.Now().Add(-time.Minute))
+	holds, err := testStore.ListExpiredTransferHolds(context.Background(), ListExpiredTransferHoldsParams{
+		ExpiredAt: time.Now(),
+		Limit:     1000,
+	})
+	require.NoError(t, err)
+	require.Contains(t, holds, expired.Hold)
+	require.NotContains(t, holds, pending.Hold)
+
+	_, err = testStore.CaptureTransferTx(context.Background(), expired.Transfer.ID)
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	result, err := testStore.ExpireTransferTx(context.Background(), expired.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusExpired, result.Transfer.Status)
+	require.Equal(t, pending.FromAccount.HeldAmount, result.FromAccount.HeldAmount)
+}
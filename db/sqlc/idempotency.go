@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// transferRequestHash identifies the body of a transfer request, so that a key reused
+// with different parameters can be told apart from a genuine retry
+func transferRequestHash(arg TransferTxParam) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%d:%d", arg.FromAccountID, arg.ToAccountID, arg.Amount)))
+	return hex.EncodeToString(sum[:])
+}
+
+// reserveIdempotencyKey inserts the key inside the transfer transaction. A concurrent request
+// holding the same key blocks on the primary key until the first one commits or rolls back.
+// When the key already exists, result is filled with the stored response and replayed is true.
+func reserveIdempotencyKey(ctx context.Context, q *Queries, arg TransferTxParam, result *TransferTxResult) (replayed bool, err error) {
+	requestHash := transferRequestHash(arg)
+	_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Key:         arg.IdempotencyKey,
+		Username:    arg.Username,
+		RequestHash: requestHash,
+	})
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return false, err
+	}
+
+	// on conflict do nothing returns no row: the key has already been used
+	key, err := q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		Username: arg.Username,
+		Key:      arg.IdempotencyKey,
+	})
+	if err != nil {
+		return false, err
+	}
+	if key.RequestHash != requestHash {
+		return false, fmt.Errorf("%w: %s", ErrIdempotencyKeyConflict, arg.IdempotencyKey)
+	}
+	if err = json.Unmarshal(key.Response, result); err != nil {
+		return false, fmt.Errorf("cannot decode stored transfer result: %w", err)
+	}
+	return true, nil
+}
+
+func saveIdempotentResult(ctx context.Context, q *Queries, arg TransferTxParam, result TransferTxResult) error {
+	response, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("cannot encode transfer result: %w", err)
+	}
+	_, err = q.UpdateIdempotencyKeyResponse(ctx, UpdateIdempotencyKeyResponseParams{
+		Username: arg.Username,
+		Key:      arg.IdempotencyKey,
+		TransferID: pgtype.Int8{
+			Int64: result.Transfer.ID,
+			Valid: true,
+		},
+		Response: response,
+	})
+	return err
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Account struct {
@@ -16,6 +17,53 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// how far below zero the balance may go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// sum of the active holds of the pending transfers from the account
+	HeldAmount int64 `json:"held_amount"`
+	// balance minus held_amount, what the account can still spend
+	AvailableBalance int64 `json:"available_balance"`
+	// active, frozen or closed, frozen accounts can only be credited, closed accounts can't move money
+	Status string `json:"status"`
+}
+
+type AccountStatusChange struct {
+	ID         int64  `json:"id"`
+	AccountID  int64  `json:"account_id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	Reason     string `json:"reason"`
+	// banker who changed the status
+	ChangedBy string    `json:"changed_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// append-only, updates and deletes are rejected by a trigger
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// user whose access token authorized the mutation, anonymous for public requests such as a login, system for the background jobs
+	Actor     string `json:"actor"`
+	ActorRole string `json:"actor_role"`
+	ClientIp  string `json:"client_ip"`
+	UserAgent string `json:"user_agent"`
+	// such as account.create or transfer.capture
+	Action       string `json:"action"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+	// state of the resource before the mutation, null when it is created
+	Before []byte `json:"before"`
+	// state of the resource after the mutation, null when it is deleted
+	After     []byte    `json:"after"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Currency struct {
+	// ISO 4217 alphabetic code
+	Code string `json:"code"`
+	// number of decimal places of the minor unit, 2 for USD, 0 for JPY
+	MinorUnits int32     `json:"minor_units"`
+	Enabled    bool      `json:"enabled"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type Entry struct {
@@ -24,6 +72,99 @@ type Entry struct {
 	// can be negative of positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// transfer the entry belongs to
+	TransferID pgtype.Int8 `json:"transfer_id"`
+}
+
+type IdempotencyKey struct {
+	Key         string      `json:"key"`
+	Username    string      `json:"username"`
+	RequestHash string      `json:"request_hash"`
+	TransferID  pgtype.Int8 `json:"transfer_id"`
+	Response    []byte      `json:"response"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+type OutboxEvent struct {
+	ID int64 `json:"id"`
+	// such as transfer.posted or account.status_changed
+	EventType     string `json:"event_type"`
+	AggregateType string `json:"aggregate_type"`
+	AggregateID   string `json:"aggregate_id"`
+	Payload       []byte `json:"payload"`
+	// failed publications of the event
+	Attempts  int32       `json:"attempts"`
+	LastError pgtype.Text `json:"last_error"`
+	// the relay skips the event until then, while it is claimed or waits for its retry
+	AvailableAt time.Time `json:"available_at"`
+	// set once the relay has published the event
+	PublishedAt pgtype.Timestamptz `json:"published_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+type ReconciliationReport struct {
+	ID                int64     `json:"id"`
+	StartedAt         time.Time `json:"started_at"`
+	FinishedAt        time.Time `json:"finished_at"`
+	DriftedAccounts   int32     `json:"drifted_accounts"`
+	OrphanedEntries   int32     `json:"orphaned_entries"`
+	OrphanedTransfers int32     `json:"orphaned_transfers"`
+	// drifted accounts, orphaned entries and orphaned transfers found by the run
+	Findings  []byte    `json:"findings"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RevokedToken struct {
+	// id of the access token payload
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+type Role struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RolePermission struct {
+	Role string `json:"role"`
+	// action such as account:read, the :any suffix extends it to the resources of every user
+	Permission string `json:"permission"`
+}
+
+type ScheduledTransfer struct {
+	ID int64 `json:"id"`
+	// user who scheduled the transfer, the transfers are made on its behalf
+	Username      string `json:"username"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	// must be positive
+	Amount int64 `json:"amount"`
+	// cron expression of five fields, @hourly, @daily, @weekly, @monthly, @yearly or @every <duration>, evaluated in UTC
+	Schedule string `json:"schedule"`
+	// active or paused
+	Status string `json:"status"`
+	// occurrence of the schedule the next run executes
+	OccurrenceAt time.Time `json:"occurrence_at"`
+	// occurrence_at, pushed back while a run is in progress or a failed run waits for its retry
+	NextRunAt time.Time `json:"next_run_at"`
+	// failed attempts of the current occurrence
+	Attempts  int32     `json:"attempts"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64     `json:"id"`
+	ScheduledTransferID int64     `json:"scheduled_transfer_id"`
+	OccurrenceAt        time.Time `json:"occurrence_at"`
+	Attempt             int32     `json:"attempt"`
+	// transfer made by a successful run
+	TransferID pgtype.Int8 `json:"transfer_id"`
+	// failure of the run
+	Error     pgtype.Text `json:"error"`
+	CreatedAt time.Time   `json:"created_at"`
 }
 
 type Session struct {
@@ -35,6 +176,25 @@ type Session struct {
 	IsBlocked    bool      `json:"is_blocked"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	CreatedAt    time.Time `json:"created_at"`
+	// id of the login session, shared by every session created by rotating its refresh token
+	FamilyID uuid.UUID `json:"family_id"`
+	// set when the refresh token has been exchanged, presenting it again revokes the family
+	RotatedAt pgtype.Timestamptz `json:"rotated_at"`
+}
+
+type Task struct {
+	ID int64 `json:"id"`
+	// such as send_verify_email, selects the handler of the worker
+	Kind    string `json:"kind"`
+	Payload []byte `json:"payload"`
+	// pending, done, or failed once its attempts are exhausted
+	Status    string      `json:"status"`
+	Attempts  int32       `json:"attempts"`
+	LastError pgtype.Text `json:"last_error"`
+	// the worker skips the task until then, while it is claimed or waits for its retry
+	RunAt       time.Time          `json:"run_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+	CreatedAt   time.Time          `json:"created_at"`
 }
 
 type Transfer struct {
@@ -44,6 +204,28 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// amount credited in the currency of the destination account
+	ToAmount int64 `json:"to_amount"`
+	// rate applied to amount, scaled by 1e8
+	ExchangeRate int64 `json:"exchange_rate"`
+	// transfer, deposit, withdrawal or reversal, deposits and withdrawals move money from or to the cash account of the currency
+	Kind string `json:"kind"`
+	// transfer refunded by this reversal, the reversals of a transfer refund at most its amount
+	ReversedTransferID pgtype.Int8 `json:"reversed_transfer_id"`
+	// posted, or pending until captured, voided or expired, only posted transfers have entries
+	Status string `json:"status"`
+}
+
+type TransferHold struct {
+	ID         int64 `json:"id"`
+	TransferID int64 `json:"transfer_id"`
+	AccountID  int64 `json:"account_id"`
+	// amount held on the account, in its currency
+	Amount    int64     `json:"amount"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// set when the transfer is captured, voided or expired
+	ReleasedAt pgtype.Timestamptz `json:"released_at"`
+	CreatedAt  time.Time          `json:"created_at"`
 }
 
 type User struct {
@@ -54,4 +236,18 @@ type User struct {
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 	Role              string    `json:"role"`
+	// access tokens issued before this time are rejected
+	TokensRevokedAt time.Time `json:"tokens_revoked_at"`
+	IsEmailVerified bool      `json:"is_email_verified"`
+}
+
+type VerifyEmail struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// address the code was sent to, it verifies the user only while it is still the email of the user
+	Email      string    `json:"email"`
+	SecretCode string    `json:"secret_code"`
+	IsUsed     bool      `json:"is_used"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiredAt  time.Time `json:"expired_at"`
 }
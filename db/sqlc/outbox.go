@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Types of the aggregates the outbox events are about
+const (
+	EventAggregateTransfer = "transfer"
+	EventAggregateAccount  = "account"
+)
+
+// Types of the outbox events. A transfer is posted by TransferTx, the cash operations, the reversals and
+// the captures, its kind tells them apart. The payload of a transfer event is the transfer, the payload
+// of account.status_changed is the account.
+const (
+	EventTransferPosted       = "transfer.posted"
+	EventTransferAuthorized   = "transfer.authorized"
+	EventTransferVoided       = "transfer.voided"
+	EventTransferExpired      = "transfer.expired"
+	EventAccountStatusChanged = "account.status_changed"
+)
+
+// enqueueEvent writes an event to the outbox within the transaction of the change it announces,
+// the relay publishes it once the transaction is committed
+func enqueueEvent(ctx context.Context, q *Queries, eventType, aggregateType string, aggregateID any, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("cannot marshal the payload of %s: %w", eventType, err)
+	}
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   fmt.Sprint(aggregateID),
+		Payload:       data,
+	})
+	return err
+}
+
+func enqueueTransferEvent(ctx context.Context, q *Queries, eventType string, transfer Transfer) error {
+	return enqueueEvent(ctx, q, eventType, EventAggregateTransfer, transfer.ID, transfer)
+}
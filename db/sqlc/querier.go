@@ -12,23 +12,99 @@ import (
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountHeldAmount(ctx context.Context, arg AddAccountHeldAmountParams) (Account, error)
+	// the transfer only advances from the occurrence and lease it was claimed with, an update made
+	// during the run has moved it already and is kept
+	AdvanceScheduledTransfer(ctx context.Context, arg AdvanceScheduledTransferParams) (ScheduledTransfer, error)
+	BlockSession(ctx context.Context, id uuid.UUID) (Session, error)
+	BlockSessionFamily(ctx context.Context, familyID uuid.UUID) (int64, error)
+	BlockUserSessions(ctx context.Context, username string) (int64, error)
+	// the claimed transfers are pushed back to lease_until, so that the other workers skip them
+	// until the run is recorded, or the claim expires if the worker dies
+	ClaimDueScheduledTransfers(ctx context.Context, arg ClaimDueScheduledTransfersParams) ([]ScheduledTransfer, error)
+	// the claimed events are pushed back to lease_until, so that the other relays skip them
+	// until they are published, or the claim expires if the relay dies. The claim stops before the
+	// first unpublished event that isn't due, claimed or waiting for its retry, so that the events
+	// are published in order; it fails rather than wait while another relay claims.
+	ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]OutboxEvent, error)
+	// the claimed tasks are pushed back to lease_until, so that the other workers skip them
+	// until their outcome is recorded, or the claim expires if the worker dies
+	ClaimTasks(ctx context.Context, arg ClaimTasksParams) ([]Task, error)
+	CompleteTask(ctx context.Context, arg CompleteTaskParams) error
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountStatusChange(ctx context.Context, arg CreateAccountStatusChangeParams) (AccountStatusChange, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (OutboxEvent, error)
+	CreateReconciliationReport(ctx context.Context, arg CreateReconciliationReportParams) (ReconciliationReport, error)
+	CreateRevokedToken(ctx context.Context, arg CreateRevokedTokenParams) error
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferHold(ctx context.Context, arg CreateTransferHoldParams) (TransferHold, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailParams) (VerifyEmail, error)
+	DelayOutboxEvents(ctx context.Context, arg DelayOutboxEventsParams) error
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteExpiredRevokedTokens(ctx context.Context) (int64, error)
+	DeleteScheduledTransfer(ctx context.Context, id int64) error
 	GetAccount(ctx context.Context, id int64) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetCashAccount(ctx context.Context, currency string) (Account, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetLatestReconciliationReport(ctx context.Context) (ReconciliationReport, error)
+	GetOpeningBalance(ctx context.Context, arg GetOpeningBalanceParams) (int64, error)
+	GetReversedTotals(ctx context.Context, transferID int64) (GetReversedTotalsRow, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTransferHold(ctx context.Context, transferID int64) (TransferHold, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserForUpdate(ctx context.Context, username string) (User, error)
+	IsTokenRevoked(ctx context.Context, arg IsTokenRevokedParams) (bool, error)
+	// the latest changes first
+	ListAccountStatusChanges(ctx context.Context, arg ListAccountStatusChangesParams) ([]AccountStatusChange, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	// accounts whose balance is not the sum of their entries
+	// every filter is optional
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	ListBalanceDrifts(ctx context.Context) ([]ListBalanceDriftsRow, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEntriesBetween(ctx context.Context, arg ListEntriesBetweenParams) ([]Entry, error)
+	// holds still active past their expiry, the oldest first
+	ListExpiredTransferHolds(ctx context.Context, arg ListExpiredTransferHoldsParams) ([]TransferHold, error)
+	// entries that don't belong to a transfer of their account
+	ListOrphanedEntries(ctx context.Context) ([]Entry, error)
+	// posted transfers without exactly one debit entry on the source account and one credit entry on the destination account
+	ListOrphanedTransfers(ctx context.Context) ([]ListOrphanedTransfersRow, error)
+	ListRolePermissions(ctx context.Context) ([]ListRolePermissionsRow, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListSessions(ctx context.Context, arg ListSessionsParams) ([]Session, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
-	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	MarkOutboxEventPublished(ctx context.Context, arg MarkOutboxEventPublishedParams) error
+	RecordOutboxEventFailure(ctx context.Context, arg RecordOutboxEventFailureParams) error
+	// a task retried later stays pending until run_at, a failed one isn't run again
+	RecordTaskFailure(ctx context.Context, arg RecordTaskFailureParams) error
+	ReleaseTransferHold(ctx context.Context, transferID int64) (TransferHold, error)
+	RevokeUserTokens(ctx context.Context, arg RevokeUserTokensParams) error
+	RotateSession(ctx context.Context, id uuid.UUID) (Session, error)
+	UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateIdempotencyKeyResponse(ctx context.Context, arg UpdateIdempotencyKeyResponseParams) (IdempotencyKey, error)
+	// a new occurrence_at restarts the schedule, the retries of the previous occurrence are dropped
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	// a code is used once, before it expires
+	UseVerifyEmail(ctx context.Context, arg UseVerifyEmailParams) (VerifyEmail, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestListBalanceDrifts(t *testing.T) {
+	// the random accounts are created with a balance but without any entry
+	account := createRandomAccount(t)
+	entry := createRandomEntry(t, account)
+
+	drifts, err := testStore.ListBalanceDrifts(context.Background())
+	require.NoError(t, err)
+
+	var found bool
+	for _, drift := range drifts {
+		require.NotEqual(t, drift.Balance, drift.EntriesTotal)
+		if drift.AccountID == account.ID {
+			found = true
+		}
+	}
+	require.Equal(t, account.Balance != entry.Amount, found)
+}
+
+func TestListOrphanedEntriesAndTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	entry := createRandomEntry(t, account1)
+	transfer := createRandomTransfer(t, account1, account2)
+
+	entries, err := testStore.ListOrphanedEntries(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, entries, entry)
+
+	transfers, err := testStore.ListOrphanedTransfers(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, transfers, ListOrphanedTransfersRow{
+		ID:            transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		ToAmount:      transfer.ToAmount,
+	})
+
+	// the entries written by TransferTx reconcile
+	result, err := testStore.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	transfers, err = testStore.ListOrphanedTransfers(context.Background())
+	require.NoError(t, err)
+	for _, orphaned := range transfers {
+		require.NotEqual(t, result.Transfer.ID, orphaned.ID)
+	}
+}
+
+func TestCreateReconciliationReport(t *testing.T) {
+	startedAt := time.Now().Add(-time.Second)
+	arg := CreateReconciliationReportParams{
+		StartedAt:       startedAt,
+		FinishedAt:      time.Now(),
+		DriftedAccounts: 1,
+		Findings:        []byte(`{"drifts":[]}`),
+	}
+	report, err := testStore.CreateReconciliationReport(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.DriftedAccounts, report.DriftedAccounts)
+	require.WithinDuration(t, startedAt, report.StartedAt, time.Millisecond)
+
+	latest, err := testStore.GetLatestReconciliationReport(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, report.ID, latest.ID)
+}
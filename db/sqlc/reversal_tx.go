@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fayca121/simplebank/fx"
+	"github.com/jackc/pgx/v5/pgtype"
+	"math/big"
+)
+
+// TransferKindReversal is the kind of the transfers refunding another transfer
+const TransferKindReversal = "reversal"
+
+var (
+	// ErrTransferNotReversible is returned when reversing a reversal or a transfer not posted
+	ErrTransferNotReversible = errors.New("transfer can't be reversed")
+	// ErrTransferAlreadyReversed is returned when the whole amount of the transfer has already been refunded
+	ErrTransferAlreadyReversed = errors.New("transfer already reversed")
+	// ErrRefundTooLarge is returned when a refund exceeds the amount of the transfer not yet refunded
+	ErrRefundTooLarge = errors.New("refund exceeds the amount not yet refunded")
+)
+
+type ReverseTransferTxParam struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount is refunded in the currency of the destination account of the transfer,
+	// zero refunds all the amount not yet refunded
+	Amount int64 `json:"amount"`
+}
+
+type ReverseTransferTxResult struct {
+	TransferTxResult
+	OriginalTransfer Transfer `json:"original_transfer"`
+}
+
+// ReverseTransferTx refunds the transfer: a reversal transfer, linked to the original one, moves the amount
+// back from its destination account to its source account. A transfer may be refunded in several parts,
+// the amount credited back is converted with the rate of the transfer and the last part credits exactly
+// what remains of the original debit.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParam) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// the lock serializes the refunds of the transfer
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+		if original.Kind == TransferKindReversal {
+			return fmt.Errorf("%w: transfer [%d] is a reversal", ErrTransferNotReversible, original.ID)
+		}
+		if original.Status != TransferStatusPosted {
+			return fmt.Errorf("%w: transfer [%d] is %s", ErrTransferNotReversible, original.ID, original.Status)
+		}
+		result.OriginalTransfer = original
+
+		reversed, err := q.GetReversedTotals(ctx, original.ID)
+		if err != nil {
+			return err
+		}
+		remaining := original.ToAmount - reversed.Amount
+		if remaining <= 0 {
+			return fmt.Errorf("%w: transfer [%d]", ErrTransferAlreadyReversed, original.ID)
+		}
+		amount := arg.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount > remaining {
+			return fmt.Errorf("%w: transfer [%d] has %d left to refund", ErrRefundTooLarge, original.ID, remaining)
+		}
+
+		refunded := original.Amount - reversed.ToAmount
+		if amount < remaining {
+			refunded, err = refundedAmount(original, amount)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID:      original.ToAccountID,
+			ToAccountID:        original.FromAccountID,
+			Amount:             amount,
+			ToAmount:           refunded,
+			ExchangeRate:       fx.RateScale * fx.RateScale / original.ExchangeRate,
+			Kind:               TransferKindReversal,
+			ReversedTransferID: pgtype.Int8{Int64: original.ID, Valid: true},
+			Status:             TransferStatusPosted,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  original.ToAccountID,
+			Amount:     -amount,
+			TransferID: pgtype.Int8{Int64: result.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  original.FromAccountID,
+			Amount:     refunded,
+			TransferID: pgtype.Int8{Int64: result.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		fromAccountID, toAccountID := original.ToAccountID, original.FromAccountID
+		if fromAccountID < toAccountID {
+			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, fromAccountID, -amount, toAccountID, refunded)
+		} else {
+			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, toAccountID, refunded, fromAccountID, -amount)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = checkDebit(result.FromAccount); err != nil {
+			return err
+		}
+		if err = checkCredit(result.ToAccount); err != nil {
+			return err
+		}
+		// the cash accounts can go below zero, like in cashTx
+		if result.FromAccount.Owner != CashAccountOwner && result.FromAccount.AvailableBalance < -result.FromAccount.OverdraftLimit {
+			return fmt.Errorf("%w: account [%d] cannot be debited by %d",
+				ErrInsufficientFunds, fromAccountID, amount)
+		}
+		if err = enqueueTransferEvent(ctx, q, EventTransferPosted, result.Transfer); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditTransferReverse, AuditResourceTransfer, result.Transfer.ID, nil, result)
+	})
+
+	return result, err
+}
+
+// refundedAmount converts a partial refund, in the currency of the destination account, back into the
+// currency of the source account with the rate of the transfer. It is truncated like fx.Rate.Convert.
+func refundedAmount(original Transfer, amount int64) (int64, error) {
+	refunded := new(big.Int).Mul(big.NewInt(amount), big.NewInt(original.Amount))
+	refunded.Quo(refunded, big.NewInt(original.ToAmount))
+	if refunded.Sign() == 0 {
+		return 0, fmt.Errorf("%w: refund of %d on transfer [%d]", fx.ErrAmountTooSmall, amount, original.ID)
+	}
+	return refunded.Int64(), nil
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestReverseTransferTx(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	transferred, err := testStore.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// partial refund
+	result, err := testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParam{
+		TransferID: transferred.Transfer.ID,
+		Amount:     4,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferKindReversal, result.Transfer.Kind)
+	require.Equal(t, transferred.Transfer.ID, result.Transfer.ReversedTransferID.Int64)
+	require.Equal(t, account2.ID, result.Transfer.FromAccountID)
+	require.Equal(t, account1.ID, result.Transfer.ToAccountID)
+	require.Equal(t, int64(-4), result.FromEntry.Amount)
+	require.Equal(t, int64(4), result.ToEntry.Amount)
+	require.Equal(t, transferred.ToAccount.Balance-4, result.FromAccount.Balance)
+	require.Equal(t, transferred.FromAccount.Balance+4, result.ToAccount.Balance)
+
+	_, err = testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParam{
+		TransferID: transferred.Transfer.ID,
+		Amount:     7,
+	})
+	require.ErrorIs(t, err, ErrRefundTooLarge)
+
+	// the rest of the transfer
+	result, err = testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParam{
+		TransferID: transferred.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(6), result.Transfer.Amount)
+	require.Equal(t, account1.Balance, result.ToAccount.Balance)
+	require.Equal(t, account2.Balance, result.FromAccount.Balance)
+
+	_, err = testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParam{
+		TransferID: transferred.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrTransferAlreadyReversed)
+
+	_, err = testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParam{
+		TransferID: result.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrTransferNotReversible)
+}
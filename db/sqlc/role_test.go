@@ -0,0 +1,26 @@
+package db
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestQueries_ListRolePermissions(t *testing.T) {
+	rows, err := testStore.ListRolePermissions(context.Background())
+	require.NoError(t, err)
+
+	// the built-in roles are seeded by the migration
+	grants := make(map[string][]string)
+	for _, row := range rows {
+		if row.Permission.Valid {
+			grants[row.Role] = append(grants[row.Role], row.Permission.String)
+		}
+	}
+	for _, role := range []util.Role{util.DepositorRole, util.BankerRole, util.AuditorRole, util.SupportRole} {
+		require.Contains(t, grants, role.String())
+		require.Contains(t, grants[role.String()], "session:read")
+	}
+	require.Contains(t, grants[util.BankerRole.String()], "account:delete:any")
+}
@@ -0,0 +1,176 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createRandomScheduledTransfer(t *testing.T, from, to Account, occurrenceAt time.Time) ScheduledTransfer {
+	arg := CreateScheduledTransferParams{
+		Username:      from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		Schedule:      "0 9 1 * *",
+		OccurrenceAt:  occurrenceAt,
+		NextRunAt:     occurrenceAt,
+	}
+	scheduled, err := testStore.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, scheduled.ID)
+	require.Equal(t, ScheduledTransferActive, scheduled.Status)
+	require.Zero(t, scheduled.Attempts)
+	require.WithinDuration(t, occurrenceAt, scheduled.NextRunAt, time.Second)
+	return scheduled
+}
+
+func TestClaimDueScheduledTransfers(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createRandomAccount(t)
+	now := time.Now()
+	due := createRandomScheduledTransfer(t, from, to, now.Add(-time.Minute))
+	later := createRandomScheduledTransfer(t, from, to, now.Add(time.Hour))
+
+	claimed, err := testStore.ClaimDueScheduledTransfers(context.Background(), ClaimDueScheduledTransfersParams{
+		LeaseUntil: now.Add(5 * time.Minute),
+		DueAt:      now,
+		Limit:      1000,
+	})
+	require.NoError(t, err)
+	ids := make(map[int64]bool)
+	for _, scheduled := range claimed {
+		ids[scheduled.ID] = true
+	}
+	require.True(t, ids[due.ID])
+	require.False(t, ids[later.ID])
+
+	// the lease keeps the claimed transfer away from the next claim
+	claimed, err = testStore.ClaimDueScheduledTransfers(context.Background(), ClaimDueScheduledTransfersParams{
+		LeaseUntil: now.Add(5 * time.Minute),
+		DueAt:      now,
+		Limit:      1000,
+	})
+	require.NoError(t, err)
+	for _, scheduled := range claimed {
+		require.NotEqual(t, due.ID, scheduled.ID)
+	}
+}
+
+func TestRecordScheduledTransferRunTx(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createRandomAccount(t)
+	occurrenceAt := time.Now().Add(-time.Minute)
+	scheduled := createRandomScheduledTransfer(t, from, to, occurrenceAt)
+	retryAt := time.Now().Add(time.Minute)
+
+	result, err := testStore.RecordScheduledTransferRunTx(context.Background(), RecordScheduledTransferRunTxParam{
+		Run: CreateScheduledTransferRunParams{
+			ScheduledTransferID: scheduled.ID,
+			OccurrenceAt:        occurrenceAt,
+			Attempt:             1,
+			Error:               pgtype.Text{String: ErrInsufficientFunds.Error(), Valid: true},
+		},
+		Advance: AdvanceScheduledTransferParams{
+			ID:                  scheduled.ID,
+			OccurrenceAt:        occurrenceAt,
+			NextRunAt:           retryAt,
+			Attempts:            1,
+			ClaimedOccurrenceAt: scheduled.OccurrenceAt,
+			ClaimedNextRunAt:    scheduled.NextRunAt,
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, result.Run.TransferID.Valid)
+	require.Equal(t, int32(1), result.ScheduledTransfer.Attempts)
+	require.WithinDuration(t, retryAt, result.ScheduledTransfer.NextRunAt, time.Second)
+
+	runs, err := testStore.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		Limit:               10,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, result.Run, runs[0])
+}
+
+func TestRecordScheduledTransferRunTxAfterUpdate(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createRandomAccount(t)
+	occurrenceAt := time.Now().Add(-time.Minute)
+	scheduled := createRandomScheduledTransfer(t, from, to, occurrenceAt)
+
+	// the schedule is changed during the run, the new occurrence is kept
+	rescheduledAt := time.Now().Add(24 * time.Hour)
+	updated, err := testStore.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:           scheduled.ID,
+		OccurrenceAt: pgtype.Timestamptz{Time: rescheduledAt, Valid: true},
+	})
+	require.NoError(t, err)
+
+	result, err := testStore.RecordScheduledTransferRunTx(context.Background(), RecordScheduledTransferRunTxParam{
+		Run: CreateScheduledTransferRunParams{
+			ScheduledTransferID: scheduled.ID,
+			OccurrenceAt:        occurrenceAt,
+			Attempt:             1,
+		},
+		Advance: AdvanceScheduledTransferParams{
+			ID:                  scheduled.ID,
+			OccurrenceAt:        time.Now().Add(time.Hour),
+			NextRunAt:           time.Now().Add(time.Hour),
+			ClaimedOccurrenceAt: scheduled.OccurrenceAt,
+			ClaimedNextRunAt:    scheduled.NextRunAt,
+		},
+	})
+	require.NoError(t, err)
+	require.NotZero(t, result.Run.ID)
+	require.Equal(t, updated, result.ScheduledTransfer)
+
+	err = testStore.DeleteScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	_, err = testStore.RecordScheduledTransferRunTx(context.Background(), RecordScheduledTransferRunTxParam{
+		Run:     CreateScheduledTransferRunParams{ScheduledTransferID: scheduled.ID, OccurrenceAt: occurrenceAt, Attempt: 1},
+		Advance: AdvanceScheduledTransferParams{ID: scheduled.ID},
+	})
+	require.ErrorIs(t, err, ErrScheduledTransferDeleted)
+}
+
+func TestUpdateAndDeleteScheduledTransfer(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createRandomAccount(t)
+	scheduled := createRandomScheduledTransfer(t, from, to, time.Now().Add(time.Hour))
+
+	paused, err := testStore.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     scheduled.ID,
+		Status: pgtype.Text{String: ScheduledTransferPaused, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferPaused, paused.Status)
+	require.Equal(t, scheduled.Amount, paused.Amount)
+	require.WithinDuration(t, scheduled.OccurrenceAt, paused.OccurrenceAt, time.Second)
+
+	restartAt := time.Now().Add(24 * time.Hour)
+	restarted, err := testStore.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:           scheduled.ID,
+		Schedule:     pgtype.Text{String: "@every 24h", Valid: true},
+		OccurrenceAt: pgtype.Timestamptz{Time: restartAt, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "@every 24h", restarted.Schedule)
+	require.WithinDuration(t, restartAt, restarted.OccurrenceAt, time.Second)
+	require.WithinDuration(t, restartAt, restarted.NextRunAt, time.Second)
+
+	listed, err := testStore.ListScheduledTransfers(context.Background(), ListScheduledTransfersParams{
+		FromAccountID: from.ID,
+		Limit:         5,
+	})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+
+	require.NoError(t, testStore.DeleteScheduledTransfer(context.Background(), scheduled.ID))
+	_, err = testStore.GetScheduledTransfer(context.Background(), scheduled.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	ScheduledTransferActive = "active"
+	ScheduledTransferPaused = "paused"
+)
+
+// ErrScheduledTransferDeleted is returned by RecordScheduledTransferRunTx when the scheduled transfer
+// was deleted during the run, the run isn't recorded: the runs of a transfer are deleted with it
+var ErrScheduledTransferDeleted = errors.New("scheduled transfer was deleted during its run")
+
+type RecordScheduledTransferRunTxParam struct {
+	Run CreateScheduledTransferRunParams `json:"run"`
+	// Advance moves the scheduled transfer to its next run, either a retry or the next occurrence,
+	// from the occurrence and lease of its claim
+	Advance AdvanceScheduledTransferParams `json:"advance"`
+}
+
+type RecordScheduledTransferRunTxResult struct {
+	Run               ScheduledTransferRun `json:"run"`
+	ScheduledTransfer ScheduledTransfer    `json:"scheduled_transfer"`
+}
+
+// RecordScheduledTransferRunTx records the outcome of a run of a scheduled transfer and schedules its next run.
+// The scheduled transfer keeps the occurrence set by an update made during the run.
+func (store *SQLStore) RecordScheduledTransferRunTx(ctx context.Context, arg RecordScheduledTransferRunTxParam) (RecordScheduledTransferRunTxResult, error) {
+	var result RecordScheduledTransferRunTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		current, err := q.GetScheduledTransferForUpdate(ctx, arg.Advance.ID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrScheduledTransferDeleted
+			}
+			return err
+		}
+		result.Run, err = q.CreateScheduledTransferRun(ctx, arg.Run)
+		if err != nil {
+			return err
+		}
+		result.ScheduledTransfer, err = q.AdvanceScheduledTransfer(ctx, arg.Advance)
+		if errors.Is(err, pgx.ErrNoRows) {
+			result.ScheduledTransfer, err = current, nil
+		}
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditScheduledTransferRun, AuditResourceScheduledTransfer, result.ScheduledTransfer.ID, nil, result)
+	})
+
+	return result, err
+}
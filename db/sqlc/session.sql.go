@@ -10,15 +10,69 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const blockSession = `-- name: BlockSession :one
+update sessions
+set is_blocked = true
+where id = $1
+returning id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, rotated_at
+`
+
+func (q *Queries) BlockSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	row := q.db.QueryRow(ctx, blockSession, id)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.RefreshToken,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.IsBlocked,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.FamilyID,
+		&i.RotatedAt,
+	)
+	return i, err
+}
+
+const blockSessionFamily = `-- name: BlockSessionFamily :execrows
+update sessions
+set is_blocked = true
+where family_id = $1 and is_blocked = false
+`
+
+func (q *Queries) BlockSessionFamily(ctx context.Context, familyID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, blockSessionFamily, familyID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const blockUserSessions = `-- name: BlockUserSessions :execrows
+update sessions
+set is_blocked = true
+where username = $1 and is_blocked = false
+`
+
+func (q *Queries) BlockUserSessions(ctx context.Context, username string) (int64, error) {
+	result, err := q.db.Exec(ctx, blockUserSessions, username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const createSession = `-- name: CreateSession :one
 insert into sessions (
-    id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at
+    id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, family_id
 ) VALUES (
-             $1,$2,$3,$4,$5,$6,$7
+             $1,$2,$3,$4,$5,$6,$7,$8
          )
-returning id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at
+returning id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, rotated_at
 `
 
 type CreateSessionParams struct {
@@ -29,6 +83,7 @@ type CreateSessionParams struct {
 	ClientIp     string    `json:"client_ip"`
 	IsBlocked    bool      `json:"is_blocked"`
 	ExpiresAt    time.Time `json:"expires_at"`
+	FamilyID     uuid.UUID `json:"family_id"`
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
@@ -40,6 +95,7 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		arg.ClientIp,
 		arg.IsBlocked,
 		arg.ExpiresAt,
+		arg.FamilyID,
 	)
 	var i Session
 	err := row.Scan(
@@ -51,12 +107,14 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.IsBlocked,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.FamilyID,
+		&i.RotatedAt,
 	)
 	return i, err
 }
 
 const getSession = `-- name: GetSession :one
-select id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at from sessions
+select id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, rotated_at from sessions
 where id= $1 limit 1
 `
 
@@ -72,6 +130,85 @@ func (q *Queries) GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 		&i.IsBlocked,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.FamilyID,
+		&i.RotatedAt,
+	)
+	return i, err
+}
+
+const listSessions = `-- name: ListSessions :many
+select id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, rotated_at from sessions
+where username = $1 and rotated_at is null
+  and ($2::timestamptz is null
+    or (created_at, id) < ($2, $3::uuid))
+order by created_at desc, id desc
+LIMIT $4
+`
+
+type ListSessionsParams struct {
+	Username       string             `json:"username"`
+	AfterCreatedAt pgtype.Timestamptz `json:"after_created_at"`
+	AfterID        pgtype.UUID        `json:"after_id"`
+	Limit          int32              `json:"limit"`
+}
+
+func (q *Queries) ListSessions(ctx context.Context, arg ListSessionsParams) ([]Session, error) {
+	rows, err := q.db.Query(ctx, listSessions,
+		arg.Username,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.RefreshToken,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.IsBlocked,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.FamilyID,
+			&i.RotatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rotateSession = `-- name: RotateSession :one
+update sessions
+set rotated_at = now()
+where id = $1 and rotated_at is null
+returning id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, rotated_at
+`
+
+func (q *Queries) RotateSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	row := q.db.QueryRow(ctx, rotateSession, id)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.RefreshToken,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.IsBlocked,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.FamilyID,
+		&i.RotatedAt,
 	)
 	return i, err
 }
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createRandomSession(t *testing.T, username string) Session {
+	id := uuid.New()
+	arg := CreateSessionParams{
+		ID:           id,
+		Username:     username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    util.RandomString(10),
+		ClientIp:     "127.0.0.1",
+		ExpiresAt:    time.Now().Add(time.Hour),
+		FamilyID:     id,
+	}
+	session, err := testStore.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ID, session.ID)
+	require.Equal(t, arg.Username, session.Username)
+	require.Equal(t, arg.FamilyID, session.FamilyID)
+	require.False(t, session.IsBlocked)
+	require.False(t, session.RotatedAt.Valid)
+	return session
+}
+
+func TestQueries_ListSessions(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 3; i++ {
+		createRandomSession(t, user.Username)
+	}
+
+	sessions, err := testStore.ListSessions(context.Background(), ListSessionsParams{
+		Username: user.Username,
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+	for _, session := range sessions {
+		require.Equal(t, user.Username, session.Username)
+	}
+}
+
+func TestQueries_BlockSession(t *testing.T) {
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user.Username)
+
+	session2, err := testStore.BlockSession(context.Background(), session1.ID)
+	require.NoError(t, err)
+	require.Equal(t, session1.ID, session2.ID)
+	require.True(t, session2.IsBlocked)
+}
+
+func TestQueries_BlockUserSessions(t *testing.T) {
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	session1 := createRandomSession(t, user.Username)
+	createRandomSession(t, user.Username)
+	otherSession := createRandomSession(t, other.Username)
+
+	_, err := testStore.BlockSession(context.Background(), session1.ID)
+	require.NoError(t, err)
+
+	// sessions already blocked are not counted
+	revoked, err := testStore.BlockUserSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), revoked)
+
+	otherSession, err = testStore.GetSession(context.Background(), otherSession.ID)
+	require.NoError(t, err)
+	require.False(t, otherSession.IsBlocked)
+}
+
+func TestStore_RotateSessionTx(t *testing.T) {
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user.Username)
+
+	rotate := func(sessionID uuid.UUID) (Session, error) {
+		return testStore.RotateSessionTx(context.Background(), RotateSessionTxParam{
+			SessionID: sessionID,
+			NewSession: CreateSessionParams{
+				ID:           uuid.New(),
+				Username:     user.Username,
+				RefreshToken: util.RandomString(32),
+				ExpiresAt:    time.Now().Add(time.Hour),
+			},
+		})
+	}
+
+	session2, err := rotate(session1.ID)
+	require.NoError(t, err)
+	require.Equal(t, session1.FamilyID, session2.FamilyID)
+	require.False(t, session2.RotatedAt.Valid)
+
+	session1, err = testStore.GetSession(context.Background(), session1.ID)
+	require.NoError(t, err)
+	require.True(t, session1.RotatedAt.Valid)
+
+	// only the last session of the family is listed
+	sessions, err := testStore.ListSessions(context.Background(), ListSessionsParams{
+		Username: user.Username,
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, session2.ID, sessions[0].ID)
+
+	// presenting the first refresh token again revokes the whole family
+	_, err = rotate(session1.ID)
+	require.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	session2, err = testStore.GetSession(context.Background(), session2.ID)
+	require.NoError(t, err)
+	require.True(t, session2.IsBlocked)
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type RotateSessionTxParam struct {
+	// SessionID is the session of the refresh token being exchanged
+	SessionID uuid.UUID `json:"session_id"`
+	// NewSession holds the new refresh token, its FamilyID is taken from the rotated session
+	NewSession CreateSessionParams `json:"new_session"`
+}
+
+// RotateSessionTx marks the session as rotated and creates the session of the new refresh token.
+// A session can only be rotated once: when its refresh token is presented again every session of
+// the family is blocked and ErrRefreshTokenReused is returned.
+func (store *SQLStore) RotateSessionTx(ctx context.Context, arg RotateSessionTxParam) (Session, error) {
+	var session Session
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		rotated, err := q.RotateSession(ctx, arg.SessionID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrRefreshTokenReused
+			}
+			return err
+		}
+
+		newSession := arg.NewSession
+		newSession.FamilyID = rotated.FamilyID
+		session, err = q.CreateSession(ctx, newSession)
+		if err != nil {
+			return err
+		}
+		// the event of the rotated session records the session replacing it
+		return recordAudit(ctx, q, AuditSessionRotate, AuditResourceSession, rotated.ID, nil, redactedSession(session))
+	})
+
+	if errors.Is(err, ErrRefreshTokenReused) {
+		// the family is blocked outside of the rolled back transaction
+		if blockErr := store.blockSessionFamilyOf(ctx, arg.SessionID); blockErr != nil {
+			return session, fmt.Errorf("%w, failed to revoke the session family: %v", err, blockErr)
+		}
+	}
+	return session, err
+}
+
+func (store *SQLStore) blockSessionFamilyOf(ctx context.Context, sessionID uuid.UUID) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		session, err := q.GetSession(ctx, sessionID)
+		if err != nil {
+			return err
+		}
+		blocked, err := q.BlockSessionFamily(ctx, session.FamilyID)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditSessionFamilyBlock, AuditResourceSession, session.ID, nil, map[string]any{
+			"family_id":        session.FamilyID,
+			"blocked_sessions": blocked,
+		})
+	})
+}
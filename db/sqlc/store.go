@@ -3,12 +3,26 @@ package db
 import (
 	"context"
 	"fmt"
+	"github.com/fayca121/simplebank/fx"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParam) (TransferTxResult, error)
+	CrossCurrencyTransferTx(ctx context.Context, arg CrossCurrencyTransferTxParam) (TransferTxResult, error)
+	RotateSessionTx(ctx context.Context, arg RotateSessionTxParam) (Session, error)
+	DepositTx(ctx context.Context, arg CashTxParam) (CashTxResult, error)
+	WithdrawTx(ctx context.Context, arg CashTxParam) (CashTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParam) (ReverseTransferTxResult, error)
+	RecordScheduledTransferRunTx(ctx context.Context, arg RecordScheduledTransferRunTxParam) (RecordScheduledTransferRunTxResult, error)
+	AuthorizeTransferTx(ctx context.Context, arg AuthorizeTransferTxParam) (HoldTxResult, error)
+	CaptureTransferTx(ctx context.Context, transferID int64) (TransferTxResult, error)
+	VoidTransferTx(ctx context.Context, transferID int64) (HoldTxResult, error)
+	ExpireTransferTx(ctx context.Context, transferID int64) (HoldTxResult, error)
+	UpdateAccountStatusTx(ctx context.Context, arg UpdateAccountStatusTxParam) (UpdateAccountStatusTxResult, error)
+	VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParam) (VerifyEmailTxResult, error)
 }
 type SQLStore struct {
 	*Queries
@@ -31,8 +45,9 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(queries *Queries) err
 	err = fn(q)
 	if err != nil {
 		if rbErr := tx.Rollback(ctx); rbErr != nil {
-			return fmt.Errorf("tx err: %v", rbErr)
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
 		}
+		return err
 	}
 
 	return tx.Commit(ctx)
@@ -42,6 +57,10 @@ type TransferTxParam struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// Username is the user initiating the transfer, it scopes the idempotency key
+	Username string `json:"username"`
+	// IdempotencyKey is optional, when set a retry with the same key returns the original result
+	IdempotencyKey string `json:"idempotency_key"`
 }
 
 type TransferTxResult struct {
@@ -53,18 +72,56 @@ type TransferTxResult struct {
 }
 
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParam) (TransferTxResult, error) {
+	return store.transferTx(ctx, arg, nil)
+}
+
+type CrossCurrencyTransferTxParam struct {
+	TransferTxParam
+	// Rate converts Amount, in the currency of the source account, into the currency of the destination account
+	Rate fx.Rate `json:"rate"`
+}
+
+// CrossCurrencyTransferTx debits Amount from the source account and credits the converted amount
+// to the destination account, the rate used is recorded on the transfer
+func (store *SQLStore) CrossCurrencyTransferTx(ctx context.Context, arg CrossCurrencyTransferTxParam) (TransferTxResult, error) {
+	return store.transferTx(ctx, arg.TransferTxParam, &arg.Rate)
+}
+
+// transferTx credits the destination account with the amount converted by rate, or with the amount itself when rate is nil
+func (store *SQLStore) transferTx(ctx context.Context, arg TransferTxParam, rate *fx.Rate) (TransferTxResult, error) {
 
 	var result TransferTxResult
 
 	// function to be execute inside transaction
 	err := store.execTx(ctx, func(q *Queries) error {
+		// 0- a retried request is answered with the stored result of the first one
+		if arg.IdempotencyKey != "" {
+			replayed, err := reserveIdempotencyKey(ctx, q, arg, &result)
+			if err != nil || replayed {
+				return err
+			}
+		}
+
 		//All operation for transfer amount from account1 to account2 (6 ops)
 		var err error
+		toAmount, exchangeRate := arg.Amount, int64(fx.RateScale)
+		if rate != nil {
+			toAmount, err = convertTransferAmount(ctx, q, arg, *rate)
+			if err != nil {
+				return err
+			}
+			exchangeRate = rate.Value
+		}
+
 		// 1- create transfer record
 		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
 			FromAccountID: arg.FromAccountID,
 			ToAccountID:   arg.ToAccountID,
 			Amount:        arg.Amount,
+			ToAmount:      toAmount,
+			ExchangeRate:  exchangeRate,
+			Kind:          TransferKindTransfer,
+			Status:        TransferStatusPosted,
 		})
 
 		if err != nil {
@@ -73,8 +130,9 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParam) (Tra
 
 		// 2- create two entries records (from and to)
 		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
+			AccountID:  arg.FromAccountID,
+			Amount:     -arg.Amount,
+			TransferID: pgtype.Int8{Int64: result.Transfer.ID, Valid: true},
 		})
 
 		if err != nil {
@@ -82,8 +140,9 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParam) (Tra
 		}
 
 		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+			AccountID:  arg.ToAccountID,
+			Amount:     toAmount,
+			TransferID: pgtype.Int8{Int64: result.Transfer.ID, Valid: true},
 		})
 		if err != nil {
 			return err
@@ -96,12 +155,12 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParam) (Tra
 				arg.FromAccountID,
 				-arg.Amount,
 				arg.ToAccountID,
-				arg.Amount)
+				toAmount)
 		} else {
 			result.ToAccount, result.FromAccount, err = addMoney(ctx,
 				q,
 				arg.ToAccountID,
-				arg.Amount,
+				toAmount,
 				arg.FromAccountID,
 				-arg.Amount)
 		}
@@ -110,12 +169,58 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParam) (Tra
 			return err
 		}
 
+		// the rows are locked by the updates above, so the status and the balance we read back are the ones we commit
+		if err = checkNotCash(result.FromAccount); err != nil {
+			return err
+		}
+		if err = checkNotCash(result.ToAccount); err != nil {
+			return err
+		}
+		if err = checkDebit(result.FromAccount); err != nil {
+			return err
+		}
+		if err = checkCredit(result.ToAccount); err != nil {
+			return err
+		}
+		if result.FromAccount.AvailableBalance < -result.FromAccount.OverdraftLimit {
+			return fmt.Errorf("%w: account [%d] cannot be debited by %d",
+				ErrInsufficientFunds, arg.FromAccountID, arg.Amount)
+		}
+
+		if err = enqueueTransferEvent(ctx, q, EventTransferPosted, result.Transfer); err != nil {
+			return err
+		}
+		if err = recordAudit(ctx, q, AuditTransferCreate, AuditResourceTransfer, result.Transfer.ID, nil, result); err != nil {
+			return err
+		}
+
+		if arg.IdempotencyKey != "" {
+			return saveIdempotentResult(ctx, q, arg, result)
+		}
+
 		return nil
 	})
 
 	return result, err
 }
 
+// convertTransferAmount checks that the rate matches the currencies of both accounts and converts the amount
+func convertTransferAmount(ctx context.Context, q *Queries, arg TransferTxParam, rate fx.Rate) (int64, error) {
+	fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return 0, err
+	}
+	toAccount, err := q.GetAccount(ctx, arg.ToAccountID)
+	if err != nil {
+		return 0, err
+	}
+	if fromAccount.Currency != rate.From || toAccount.Currency != rate.To {
+		return 0, fmt.Errorf("rate %s/%s doesn't match accounts currencies %s/%s",
+			rate.From, rate.To, fromAccount.Currency, toAccount.Currency)
+	}
+	return rate.Convert(arg.Amount)
+}
+
 func addMoney(ctx context.Context,
 	q *Queries,
 	accountID1 int64,
@@ -2,19 +2,30 @@ package db
 
 import (
 	"context"
+	"github.com/fayca121/simplebank/fx"
+	"github.com/fayca121/simplebank/util"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
 
-func TestStore_TransferTx(t *testing.T) {
+// fundAccount credits the account so that transfers in the test cannot hit the overdraft limit
+func fundAccount(t *testing.T, account Account, amount int64) Account {
+	funded, err := testStore.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		Amount: amount,
+		ID:     account.ID,
+	})
+	require.NoError(t, err)
+	return funded
+}
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+func TestStore_TransferTx(t *testing.T) {
 	//run n concurrent transfer transactions
-
 	n := 5
 	amount := int64(10)
 
+	account1 := fundAccount(t, createRandomAccount(t), int64(n)*amount)
+	account2 := createRandomAccount(t)
+
 	errCh := make(chan error)
 	resultCh := make(chan TransferTxResult)
 
@@ -55,6 +66,7 @@ func TestStore_TransferTx(t *testing.T) {
 		require.NotEmpty(t, fromEntry)
 		require.Equal(t, account1.ID, fromEntry.AccountID)
 		require.Equal(t, -amount, fromEntry.Amount)
+		require.Equal(t, transfer.ID, fromEntry.TransferID.Int64)
 		require.NotZero(t, fromEntry.ID)
 		require.NotZero(t, fromEntry.CreatedAt)
 
@@ -65,6 +77,7 @@ func TestStore_TransferTx(t *testing.T) {
 		require.NotEmpty(t, toEntry)
 		require.Equal(t, account2.ID, toEntry.AccountID)
 		require.Equal(t, amount, toEntry.Amount)
+		require.Equal(t, transfer.ID, toEntry.TransferID.Int64)
 		require.NotZero(t, toEntry.ID)
 		require.NotZero(t, toEntry.CreatedAt)
 
@@ -107,14 +120,13 @@ func TestStore_TransferTx(t *testing.T) {
 }
 
 func TestStore_TransferTxDeadLock(t *testing.T) {
-
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
 	//run n concurrent transfer transactions
-
 	n := 10
 	amount := int64(10)
 
+	account1 := fundAccount(t, createRandomAccount(t), int64(n)*amount)
+	account2 := fundAccount(t, createRandomAccount(t), int64(n)*amount)
+
 	errCh := make(chan error)
 
 	for i := 0; i < n; i++ {
@@ -152,3 +164,105 @@ func TestStore_TransferTxDeadLock(t *testing.T) {
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 
 }
+
+func TestStore_TransferTxInsufficientFunds(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := testStore.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + account1.OverdraftLimit + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// nothing must have been written by the rolled back transaction
+	updatedAccount1, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	updatedAccount2, err := testStore.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+
+	entries, err := testStore.ListEntries(context.Background(), ListEntriesParams{
+		AccountID: account1.ID,
+		Direction: DirectionBoth,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestStore_TransferTxIdempotent(t *testing.T) {
+	amount := int64(10)
+	account1 := fundAccount(t, createRandomAccount(t), amount)
+	account2 := createRandomAccount(t)
+
+	arg := TransferTxParam{
+		FromAccountID:  account1.ID,
+		ToAccountID:    account2.ID,
+		Amount:         amount,
+		Username:       account1.Owner,
+		IdempotencyKey: util.RandomString(16),
+	}
+
+	result1, err := testStore.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// a retry returns the original transfer and does not move money again
+	result2, err := testStore.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, result1.Transfer.ID, result2.Transfer.ID)
+	require.Equal(t, result1.FromEntry.ID, result2.FromEntry.ID)
+	require.Equal(t, result1.ToEntry.ID, result2.ToEntry.ID)
+
+	updatedAccount1, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-amount, updatedAccount1.Balance)
+
+	// the same key with a different body is a conflict
+	arg.Amount = amount + 1
+	_, err = testStore.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyConflict)
+}
+
+func TestStore_CrossCurrencyTransferTx(t *testing.T) {
+	amount := int64(100)
+	account1 := fundAccount(t, createRandomAccount(t), amount)
+	account2 := createRandomAccount(t)
+	for account2.Currency == account1.Currency {
+		account2 = createRandomAccount(t)
+	}
+	rate := fx.Rate{From: account1.Currency, To: account2.Currency, Value: 150_000_000}
+
+	result, err := testStore.CrossCurrencyTransferTx(context.Background(), CrossCurrencyTransferTxParam{
+		TransferTxParam: TransferTxParam{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+			Username:      account1.Owner,
+		},
+		Rate: rate,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, amount, result.Transfer.Amount)
+	require.Equal(t, int64(150), result.Transfer.ToAmount)
+	require.Equal(t, rate.Value, result.Transfer.ExchangeRate)
+	require.Equal(t, -amount, result.FromEntry.Amount)
+	require.Equal(t, int64(150), result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-amount, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+150, result.ToAccount.Balance)
+
+	// a rate for another pair of currencies is refused
+	_, err = testStore.CrossCurrencyTransferTx(context.Background(), CrossCurrencyTransferTxParam{
+		TransferTxParam: TransferTxParam{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+		},
+		Rate: rate.Inverse(),
+	})
+	require.Error(t, err)
+}
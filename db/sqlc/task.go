@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Statuses of the tasks
+const (
+	TaskPending = "pending"
+	TaskDone    = "done"
+	TaskFailed  = "failed"
+)
+
+// Kinds of the tasks run by the worker
+const (
+	// TaskSendVerifyEmail sends the user a code verifying its email, its payload is a SendVerifyEmailPayload
+	TaskSendVerifyEmail = "send_verify_email"
+)
+
+type SendVerifyEmailPayload struct {
+	Username string `json:"username"`
+}
+
+// enqueueTask writes a task within the transaction of the change that requires it,
+// the worker runs it once the transaction is committed
+func enqueueTask(ctx context.Context, q *Queries, kind string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("cannot marshal the payload of %s: %w", kind, err)
+	}
+	_, err = q.CreateTask(ctx, CreateTaskParams{
+		Kind:    kind,
+		Payload: data,
+	})
+	return err
+}
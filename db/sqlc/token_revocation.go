@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/fayca121/simplebank/token"
+	"github.com/google/uuid"
+	"time"
+)
+
+// TokenRevocationStore is the Postgres implementation of token.RevocationStore. Besides the
+// revoked tokens, it rejects every token issued before the last password change of its user.
+type TokenRevocationStore struct {
+	q Querier
+}
+
+func NewTokenRevocationStore(q Querier) *TokenRevocationStore {
+	return &TokenRevocationStore{q: q}
+}
+
+func (store *TokenRevocationStore) RevokeToken(ctx context.Context, payload *token.Payload) error {
+	id, err := uuid.Parse(payload.ID)
+	if err != nil {
+		return fmt.Errorf("invalid token id: %w", err)
+	}
+	// expired tokens are rejected anyway, no need to remember them
+	if _, err = store.q.DeleteExpiredRevokedTokens(ctx); err != nil {
+		return err
+	}
+	return store.q.CreateRevokedToken(ctx, CreateRevokedTokenParams{
+		ID:        id,
+		Username:  payload.Username,
+		ExpiresAt: payload.ExpiredAt,
+	})
+}
+
+func (store *TokenRevocationStore) RevokeUserTokens(ctx context.Context, username string, issuedBefore time.Time) error {
+	return store.q.RevokeUserTokens(ctx, RevokeUserTokensParams{
+		IssuedBefore: issuedBefore,
+		Username:     username,
+	})
+}
+
+func (store *TokenRevocationStore) IsRevoked(ctx context.Context, payload *token.Payload) (bool, error) {
+	id, err := uuid.Parse(payload.ID)
+	if err != nil {
+		return false, fmt.Errorf("invalid token id: %w", err)
+	}
+	return store.q.IsTokenRevoked(ctx, IsTokenRevokedParams{
+		ID:       id,
+		Username: payload.Username,
+		IssuedAt: payload.IssuedAt,
+	})
+}
+
+// NewRevocationStore returns the revocation store named by kind: "memory" keeps the denylist
+// in the process, "postgres" (the default) shares it through the database
+func NewRevocationStore(kind string, q Querier) (token.RevocationStore, error) {
+	switch kind {
+	case "memory":
+		return token.NewMemoryRevocationStore(), nil
+	case "", "postgres":
+		return NewTokenRevocationStore(q), nil
+	}
+	return nil, fmt.Errorf("unknown token revocation store %q", kind)
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/token"
+	"github.com/fayca121/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestTokenRevocationStore(t *testing.T) {
+	user := createRandomUser(t)
+	revocations := NewTokenRevocationStore(testStore)
+	maker, err := token.NewPasetoMaker(token.GenerateKeyring())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, payload1, err := maker.CreateToken(user.Username, util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+	_, payload2, err := maker.CreateToken(user.Username, util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, token.CheckRevocation(ctx, revocations, payload1))
+
+	require.NoError(t, revocations.RevokeToken(ctx, payload1))
+	// revoking twice is not an error
+	require.NoError(t, revocations.RevokeToken(ctx, payload1))
+	require.ErrorIs(t, token.CheckRevocation(ctx, revocations, payload1), token.ErrRevokedToken)
+	require.NoError(t, token.CheckRevocation(ctx, revocations, payload2))
+
+	// tokens issued before the password change are rejected
+	_, err = testStore.UpdateUser(ctx, UpdateUserParams{
+		Username: user.Username,
+		PasswordChangedAt: pgtype.Timestamptz{
+			Time:  time.Now().Add(time.Second),
+			Valid: true,
+		},
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, token.CheckRevocation(ctx, revocations, payload2), token.ErrRevokedToken)
+}
+
+func TestTokenRevocationStore_RevokeUserTokens(t *testing.T) {
+	user := createRandomUser(t)
+	revocations := NewTokenRevocationStore(testStore)
+	maker, err := token.NewPasetoMaker(token.GenerateKeyring())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, payload, err := maker.CreateToken(user.Username, util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, revocations.RevokeUserTokens(ctx, user.Username, time.Now().Add(time.Second)))
+	require.ErrorIs(t, token.CheckRevocation(ctx, revocations, payload), token.ErrRevokedToken)
+
+	// an older cutoff doesn't move tokens_revoked_at backward
+	require.NoError(t, revocations.RevokeUserTokens(ctx, user.Username, time.Now().Add(-time.Hour)))
+	require.ErrorIs(t, token.CheckRevocation(ctx, revocations, payload), token.ErrRevokedToken)
+}
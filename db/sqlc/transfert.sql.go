@@ -7,26 +7,47 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
     from_account_id,
     to_account_id,
-    amount
+    amount,
+    to_amount,
+    exchange_rate,
+    kind,
+    reversed_transfer_id,
+    status
 ) VALUES (
- $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+ $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, kind, reversed_transfer_id, status
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID      int64       `json:"from_account_id"`
+	ToAccountID        int64       `json:"to_account_id"`
+	Amount             int64       `json:"amount"`
+	ToAmount           int64       `json:"to_amount"`
+	ExchangeRate       int64       `json:"exchange_rate"`
+	Kind               string      `json:"kind"`
+	ReversedTransferID pgtype.Int8 `json:"reversed_transfer_id"`
+	Status             string      `json:"status"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRow(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.Kind,
+		arg.ReversedTransferID,
+		arg.Status,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +55,36 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Kind,
+		&i.ReversedTransferID,
+		&i.Status,
 	)
 	return i, err
 }
 
+const getReversedTotals = `-- name: GetReversedTotals :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS amount,
+       COALESCE(SUM(to_amount), 0)::bigint AS to_amount
+FROM transfers
+WHERE reversed_transfer_id = $1::bigint
+`
+
+type GetReversedTotalsRow struct {
+	Amount   int64 `json:"amount"`
+	ToAmount int64 `json:"to_amount"`
+}
+
+func (q *Queries) GetReversedTotals(ctx context.Context, transferID int64) (GetReversedTotalsRow, error) {
+	row := q.db.QueryRow(ctx, getReversedTotals, transferID)
+	var i GetReversedTotalsRow
+	err := row.Scan(&i.Amount, &i.ToAmount)
+	return i, err
+}
+
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, kind, reversed_transfer_id, status FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -52,33 +97,82 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Kind,
+		&i.ReversedTransferID,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, kind, reversed_transfer_id, status FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRow(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Kind,
+		&i.ReversedTransferID,
+		&i.Status,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
-WHERE
-from_account_id = $1 OR
-to_account_id = $2
-ORDER BY id
-LIMIT $3
-OFFSET $4
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, kind, reversed_transfer_id, status FROM transfers
+WHERE ((from_account_id = $1 AND $2::text IN ('outgoing', 'both'))
+    OR (to_account_id = $1 AND $2::text IN ('incoming', 'both')))
+  AND ($3::bigint IS NULL
+    OR $3::bigint = CASE WHEN from_account_id = $1 THEN to_account_id ELSE from_account_id END)
+  AND ($4::timestamptz IS NULL OR created_at >= $4)
+  AND ($5::timestamptz IS NULL OR created_at < $5)
+  AND ($6::bigint IS NULL
+    OR CASE WHEN from_account_id = $1 THEN amount ELSE to_amount END >= $6)
+  AND ($7::bigint IS NULL
+    OR CASE WHEN from_account_id = $1 THEN amount ELSE to_amount END <= $7)
+  AND ($8::timestamptz IS NULL
+    OR (created_at, id) > ($8, $9::bigint))
+ORDER BY created_at, id
+LIMIT $10
 `
 
 type ListTransfersParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Limit         int32 `json:"limit"`
-	Offset        int32 `json:"offset"`
+	AccountID      int64              `json:"account_id"`
+	Direction      string             `json:"direction"`
+	CounterpartyID pgtype.Int8        `json:"counterparty_id"`
+	CreatedFrom    pgtype.Timestamptz `json:"created_from"`
+	CreatedTo      pgtype.Timestamptz `json:"created_to"`
+	MinAmount      pgtype.Int8        `json:"min_amount"`
+	MaxAmount      pgtype.Int8        `json:"max_amount"`
+	AfterCreatedAt pgtype.Timestamptz `json:"after_created_at"`
+	AfterID        pgtype.Int8        `json:"after_id"`
+	Limit          int32              `json:"limit"`
 }
 
 func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
 	rows, err := q.db.Query(ctx, listTransfers,
-		arg.FromAccountID,
-		arg.ToAccountID,
+		arg.AccountID,
+		arg.Direction,
+		arg.CounterpartyID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
 		arg.Limit,
-		arg.Offset,
 	)
 	if err != nil {
 		return nil, err
@@ -93,6 +187,11 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.Kind,
+			&i.ReversedTransferID,
+			&i.Status,
 		); err != nil {
 			return nil, err
 		}
@@ -103,3 +202,33 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 	}
 	return items, nil
 }
+
+const updateTransferStatus = `-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET status = $2
+WHERE id = $1
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, kind, reversed_transfer_id, status
+`
+
+type UpdateTransferStatusParams struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+func (q *Queries) UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, updateTransferStatus, arg.ID, arg.Status)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Kind,
+		&i.ReversedTransferID,
+		&i.Status,
+	)
+	return i, err
+}
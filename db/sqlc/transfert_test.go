@@ -2,17 +2,24 @@ package db
 
 import (
 	"context"
+	"github.com/fayca121/simplebank/fx"
 	"github.com/fayca121/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 	"testing"
 	"time"
 )
 
 func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
+	amount := util.RandomMoney()
 	arg := CreateTransferParams{
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
-		Amount:        util.RandomMoney(),
+		Amount:        amount,
+		ToAmount:      amount,
+		ExchangeRate:  fx.RateScale,
+		Kind:          TransferKindTransfer,
+		Status:        TransferStatusPosted,
 	}
 
 	transfer, err := testStore.CreateTransfer(context.Background(), arg)
@@ -22,6 +29,10 @@ func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
 	require.Equal(t, arg.FromAccountID, transfer.FromAccountID)
 	require.Equal(t, arg.ToAccountID, transfer.ToAccountID)
 	require.Equal(t, arg.Amount, transfer.Amount)
+	require.Equal(t, arg.Status, transfer.Status)
+	require.Equal(t, arg.ToAmount, transfer.ToAmount)
+	require.Equal(t, arg.Kind, transfer.Kind)
+	require.Equal(t, arg.ExchangeRate, transfer.ExchangeRate)
 
 	require.NotZero(t, transfer.ID)
 	require.NotZero(t, transfer.CreatedAt)
@@ -61,18 +72,64 @@ func TestListTransfer(t *testing.T) {
 	}
 
 	arg := ListTransfersParams{
-		FromAccountID: account1.ID,
-		ToAccountID:   account1.ID,
-		Limit:         5,
-		Offset:        5,
+		AccountID: account1.ID,
+		Direction: DirectionBoth,
+		Limit:     5,
 	}
 
 	transfers, err := testStore.ListTransfers(context.Background(), arg)
 	require.NoError(t, err)
 	require.Len(t, transfers, 5)
 
-	for _, transfer := range transfers {
+	last := transfers[len(transfers)-1]
+	after := NewKeyset(last.CreatedAt, last.ID)
+	arg.AfterCreatedAt, arg.AfterID = after.CreatedAt, after.ID
+	next, err := testStore.ListTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, next, 5)
+
+	for _, transfer := range next {
 		require.NotEmpty(t, transfer)
+		require.NotContains(t, transfers, transfer)
 		require.True(t, transfer.FromAccountID == account1.ID || transfer.ToAccountID == account1.ID)
 	}
 }
+
+func TestListTransferFilters(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account3 := createRandomAccount(t)
+
+	for i := 0; i < 3; i++ {
+		createRandomTransfer(t, account1, account2)
+		createRandomTransfer(t, account3, account1)
+	}
+	createRandomTransfer(t, account2, account3)
+
+	arg := ListTransfersParams{
+		AccountID: account1.ID,
+		Direction: DirectionOutgoing,
+		Limit:     10,
+	}
+	transfers, err := testStore.ListTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 3)
+	for _, transfer := range transfers {
+		require.Equal(t, account1.ID, transfer.FromAccountID)
+	}
+
+	arg.Direction = DirectionBoth
+	arg.CounterpartyID = pgtype.Int8{Int64: account3.ID, Valid: true}
+	transfers, err = testStore.ListTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 3)
+	for _, transfer := range transfers {
+		require.Equal(t, account3.ID, transfer.FromAccountID)
+		require.Equal(t, account1.ID, transfer.ToAccountID)
+	}
+
+	arg.CreatedTo = pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true}
+	transfers, err = testStore.ListTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
@@ -17,7 +17,7 @@ insert into users (
 ) VALUES (
              $1,$2,$3,$4
          )
-returning username, hashed_password, full_name, email, password_changed_at, created_at, role
+returning username, hashed_password, full_name, email, password_changed_at, created_at, role, tokens_revoked_at, is_email_verified
 `
 
 type CreateUserParams struct {
@@ -43,12 +43,14 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
 		&i.Role,
+		&i.TokensRevokedAt,
+		&i.IsEmailVerified,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-select username, hashed_password, full_name, email, password_changed_at, created_at, role from users
+select username, hashed_password, full_name, email, password_changed_at, created_at, role, tokens_revoked_at, is_email_verified from users
 where username= $1 limit 1
 `
 
@@ -63,6 +65,31 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
 		&i.Role,
+		&i.TokensRevokedAt,
+		&i.IsEmailVerified,
+	)
+	return i, err
+}
+
+const getUserForUpdate = `-- name: GetUserForUpdate :one
+select username, hashed_password, full_name, email, password_changed_at, created_at, role, tokens_revoked_at, is_email_verified from users
+where username= $1 limit 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetUserForUpdate(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserForUpdate, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.TokensRevokedAt,
+		&i.IsEmailVerified,
 	)
 	return i, err
 }
@@ -72,9 +99,10 @@ update users
 set hashed_password = coalesce($1,hashed_password),
     password_changed_at = coalesce($2,password_changed_at),
     full_name = coalesce($3,full_name),
-    email = coalesce($4,email)
-where username = $5
-returning username, hashed_password, full_name, email, password_changed_at, created_at, role
+    email = coalesce($4,email),
+    is_email_verified = coalesce($5,is_email_verified)
+where username = $6
+returning username, hashed_password, full_name, email, password_changed_at, created_at, role, tokens_revoked_at, is_email_verified
 `
 
 type UpdateUserParams struct {
@@ -82,6 +110,7 @@ type UpdateUserParams struct {
 	PasswordChangedAt pgtype.Timestamptz `json:"password_changed_at"`
 	FullName          pgtype.Text        `json:"full_name"`
 	Email             pgtype.Text        `json:"email"`
+	IsEmailVerified   pgtype.Bool        `json:"is_email_verified"`
 	Username          string             `json:"username"`
 }
 
@@ -91,6 +120,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.PasswordChangedAt,
 		arg.FullName,
 		arg.Email,
+		arg.IsEmailVerified,
 		arg.Username,
 	)
 	var i User
@@ -102,6 +132,8 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
 		&i.Role,
+		&i.TokensRevokedAt,
+		&i.IsEmailVerified,
 	)
 	return i, err
 }
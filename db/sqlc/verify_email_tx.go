@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrInvalidVerifyEmail is returned by VerifyEmailTx for an unknown, used or expired code,
+// or a code sent to an email the user no longer has
+var ErrInvalidVerifyEmail = errors.New("invalid or expired email verification code")
+
+type VerifyEmailTxParam struct {
+	EmailID    int64  `json:"email_id"`
+	SecretCode string `json:"secret_code"`
+}
+
+type VerifyEmailTxResult struct {
+	User        User        `json:"user"`
+	VerifyEmail VerifyEmail `json:"verify_email"`
+}
+
+// VerifyEmailTx uses the code sent to the user and marks its email as verified
+func (store *SQLStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParam) (VerifyEmailTxResult, error) {
+	var result VerifyEmailTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.VerifyEmail, err = q.UseVerifyEmail(ctx, UseVerifyEmailParams{
+			ID:         arg.EmailID,
+			SecretCode: arg.SecretCode,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%w: email [%d]", ErrInvalidVerifyEmail, arg.EmailID)
+			}
+			return err
+		}
+
+		before, err := q.GetUserForUpdate(ctx, result.VerifyEmail.Username)
+		if err != nil {
+			return err
+		}
+		if before.Email != result.VerifyEmail.Email {
+			return fmt.Errorf("%w: email [%d] was sent to a former address", ErrInvalidVerifyEmail, arg.EmailID)
+		}
+
+		result.User, err = q.UpdateUser(ctx, UpdateUserParams{
+			IsEmailVerified: pgtype.Bool{Bool: true, Valid: true},
+			Username:        before.Username,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditUserEmailVerify, AuditResourceUser, before.Username, redactedUser(before), redactedUser(result.User))
+	})
+
+	return result, err
+}
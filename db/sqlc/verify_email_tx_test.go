@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func createRandomVerifyEmail(t *testing.T, user User) VerifyEmail {
+	arg := CreateVerifyEmailParams{
+		Username:   user.Username,
+		Email:      user.Email,
+		SecretCode: util.RandomString(32),
+	}
+	verifyEmail, err := testStore.CreateVerifyEmail(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Email, verifyEmail.Email)
+	require.False(t, verifyEmail.IsUsed)
+	require.True(t, verifyEmail.ExpiredAt.After(verifyEmail.CreatedAt))
+	return verifyEmail
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	user := createRandomUser(t)
+	verifyEmail := createRandomVerifyEmail(t, user)
+
+	_, err := testStore.VerifyEmailTx(context.Background(), VerifyEmailTxParam{
+		EmailID:    verifyEmail.ID,
+		SecretCode: util.RandomString(32),
+	})
+	require.ErrorIs(t, err, ErrInvalidVerifyEmail)
+
+	arg := VerifyEmailTxParam{EmailID: verifyEmail.ID, SecretCode: verifyEmail.SecretCode}
+	result, err := testStore.VerifyEmailTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, result.VerifyEmail.IsUsed)
+	require.True(t, result.User.IsEmailVerified)
+
+	// a code is used once
+	_, err = testStore.VerifyEmailTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInvalidVerifyEmail)
+}
+
+func TestVerifyEmailTxChangedEmail(t *testing.T) {
+	user := createRandomUser(t)
+	verifyEmail := createRandomVerifyEmail(t, user)
+
+	// a new email has to be verified again, the code sent to the former one no longer verifies it
+	updated, err := testStore.UpdateUser(context.Background(), UpdateUserParams{
+		Email:    pgtype.Text{String: util.RandomEmail(), Valid: true},
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.False(t, updated.IsEmailVerified)
+
+	_, err = testStore.VerifyEmailTx(context.Background(), VerifyEmailTxParam{
+		EmailID:    verifyEmail.ID,
+		SecretCode: verifyEmail.SecretCode,
+	})
+	require.ErrorIs(t, err, ErrInvalidVerifyEmail)
+}
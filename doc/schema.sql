@@ -10,6 +10,26 @@ CREATE TABLE "users" (
   "email" varchar UNIQUE NOT NULL,
   "is_email_verified" bool NOT NULL DEFAULT false,
   "password_changed_at" timestamptz NOT NULL DEFAULT '0001-01-01',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "tokens_revoked_at" timestamptz NOT NULL DEFAULT '0001-01-01'
+);
+
+CREATE TABLE "roles" (
+  "name" varchar PRIMARY KEY,
+  "description" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "role_permissions" (
+  "role" varchar NOT NULL,
+  "permission" varchar NOT NULL,
+  PRIMARY KEY ("role", "permission")
+);
+
+CREATE TABLE "currencies" (
+  "code" varchar(3) PRIMARY KEY,
+  "minor_units" int NOT NULL,
+  "enabled" boolean NOT NULL DEFAULT true,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -18,6 +38,10 @@ CREATE TABLE "accounts" (
   "owner" varchar NOT NULL,
   "balance" bigint NOT NULL,
   "currency" varchar NOT NULL,
+  "overdraft_limit" bigint NOT NULL DEFAULT 0,
+  "held_amount" bigint NOT NULL DEFAULT 0,
+  "available_balance" bigint GENERATED ALWAYS AS ("balance" - "held_amount") STORED,
+  "status" varchar NOT NULL DEFAULT 'active',
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -25,6 +49,17 @@ CREATE TABLE "entries" (
   "id" bigserial PRIMARY KEY,
   "account_id" bigint NOT NULL,
   "amount" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "transfer_id" bigint
+);
+
+CREATE TABLE "account_status_changes" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "from_status" varchar NOT NULL,
+  "to_status" varchar NOT NULL,
+  "reason" varchar NOT NULL,
+  "changed_by" varchar NOT NULL,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -33,6 +68,11 @@ CREATE TABLE "transfers" (
   "from_account_id" bigint NOT NULL,
   "to_account_id" bigint NOT NULL,
   "amount" bigint NOT NULL,
+  "to_amount" bigint NOT NULL,
+  "exchange_rate" bigint NOT NULL DEFAULT 100000000,
+  "kind" varchar NOT NULL DEFAULT 'transfer',
+  "reversed_transfer_id" bigint,
+  "status" varchar NOT NULL DEFAULT 'posted',
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -44,6 +84,119 @@ CREATE TABLE "sessions" (
   "client_ip" varchar NOT NULL,
   "is_blocked" boolean NOT NULL DEFAULT false,
   "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "family_id" uuid NOT NULL,
+  "rotated_at" timestamptz
+);
+
+CREATE TABLE "idempotency_keys" (
+  "key" varchar NOT NULL,
+  "username" varchar NOT NULL,
+  "request_hash" varchar NOT NULL,
+  "transfer_id" bigint,
+  "response" jsonb,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("username", "key")
+);
+
+CREATE TABLE "revoked_tokens" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "revoked_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "reconciliation_reports" (
+  "id" bigserial PRIMARY KEY,
+  "started_at" timestamptz NOT NULL,
+  "finished_at" timestamptz NOT NULL,
+  "drifted_accounts" int NOT NULL,
+  "orphaned_entries" int NOT NULL,
+  "orphaned_transfers" int NOT NULL,
+  "findings" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "schedule" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "occurrence_at" timestamptz NOT NULL,
+  "next_run_at" timestamptz NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "scheduled_transfer_runs" (
+  "id" bigserial PRIMARY KEY,
+  "scheduled_transfer_id" bigint NOT NULL,
+  "occurrence_at" timestamptz NOT NULL,
+  "attempt" int NOT NULL,
+  "transfer_id" bigint,
+  "error" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_holds" (
+  "id" bigserial PRIMARY KEY,
+  "transfer_id" bigint UNIQUE NOT NULL,
+  "account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "released_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "audit_events" (
+  "id" bigserial PRIMARY KEY,
+  "actor" varchar NOT NULL,
+  "actor_role" varchar NOT NULL DEFAULT '',
+  "client_ip" varchar NOT NULL DEFAULT '',
+  "user_agent" varchar NOT NULL DEFAULT '',
+  "action" varchar NOT NULL,
+  "resource_type" varchar NOT NULL,
+  "resource_id" varchar NOT NULL,
+  "before" jsonb,
+  "after" jsonb,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "outbox_events" (
+  "id" bigserial PRIMARY KEY,
+  "event_type" varchar NOT NULL,
+  "aggregate_type" varchar NOT NULL,
+  "aggregate_id" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" varchar,
+  "available_at" timestamptz NOT NULL DEFAULT (now()),
+  "published_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "verify_emails" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "email" varchar NOT NULL,
+  "secret_code" varchar NOT NULL,
+  "is_used" bool NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expired_at" timestamptz NOT NULL DEFAULT (now() + interval '15 minutes')
+);
+
+CREATE TABLE "tasks" (
+  "id" bigserial PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" varchar,
+  "run_at" timestamptz NOT NULL DEFAULT (now()),
+  "completed_at" timestamptz,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -51,24 +204,188 @@ CREATE INDEX ON "accounts" ("owner");
 
 CREATE UNIQUE INDEX ON "accounts" ("owner", "currency");
 
+CREATE INDEX ON "accounts" ("owner", "created_at", "id");
+
 CREATE INDEX ON "entries" ("account_id");
 
+CREATE INDEX ON "entries" ("account_id", "created_at", "id");
+
+CREATE INDEX ON "account_status_changes" ("account_id", "created_at");
+
 CREATE INDEX ON "transfers" ("from_account_id");
 
 CREATE INDEX ON "transfers" ("to_account_id");
 
 CREATE INDEX ON "transfers" ("from_account_id", "to_account_id");
 
+CREATE INDEX ON "transfers" ("created_at");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at", "id");
+
+CREATE INDEX ON "transfers" ("to_account_id", "created_at", "id");
+
+CREATE INDEX ON "transfers" ("reversed_transfer_id");
+
+CREATE INDEX ON "sessions" ("family_id");
+
+CREATE INDEX ON "sessions" ("username", "created_at", "id");
+
+CREATE INDEX ON "reconciliation_reports" ("created_at");
+
+CREATE INDEX ON "scheduled_transfers" ("status", "next_run_at");
+
+CREATE INDEX ON "scheduled_transfers" ("from_account_id", "created_at", "id");
+
+CREATE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id", "created_at");
+
+CREATE INDEX ON "transfer_holds" ("account_id");
+
+CREATE INDEX ON "transfer_holds" ("expires_at");
+
+CREATE INDEX ON "audit_events" ("created_at", "id");
+
+CREATE INDEX ON "audit_events" ("actor", "created_at");
+
+CREATE INDEX ON "audit_events" ("resource_type", "resource_id", "created_at");
+
+CREATE INDEX ON "outbox_events" ("published_at", "id");
+
+CREATE INDEX ON "verify_emails" ("username");
+
+CREATE INDEX ON "tasks" ("status", "run_at");
+
+COMMENT ON COLUMN "role_permissions"."permission" IS 'action such as account:read, the :any suffix extends it to the resources of every user';
+
+COMMENT ON COLUMN "currencies"."code" IS 'ISO 4217 alphabetic code';
+
+COMMENT ON COLUMN "currencies"."minor_units" IS 'number of decimal places of the minor unit, 2 for USD, 0 for JPY';
+
+COMMENT ON COLUMN "accounts"."overdraft_limit" IS 'how far below zero the balance may go';
+
+COMMENT ON COLUMN "accounts"."held_amount" IS 'sum of the active holds of the pending transfers from the account';
+
+COMMENT ON COLUMN "accounts"."available_balance" IS 'balance minus held_amount, what the account can still spend';
+
+COMMENT ON COLUMN "accounts"."status" IS 'active, frozen or closed, frozen accounts can only be credited, closed accounts can''t move money';
+
+COMMENT ON COLUMN "account_status_changes"."changed_by" IS 'banker who changed the status';
+
 COMMENT ON COLUMN "entries"."amount" IS 'can be negative or positive';
 
+COMMENT ON COLUMN "entries"."transfer_id" IS 'transfer the entry belongs to';
+
 COMMENT ON COLUMN "transfers"."amount" IS 'must be positive';
 
+COMMENT ON COLUMN "transfers"."to_amount" IS 'amount credited in the currency of the destination account';
+
+COMMENT ON COLUMN "transfers"."exchange_rate" IS 'rate applied to amount, scaled by 1e8';
+
+COMMENT ON COLUMN "transfers"."kind" IS 'transfer, deposit, withdrawal or reversal, deposits and withdrawals move money from or to the cash account of the currency';
+
+COMMENT ON COLUMN "transfers"."reversed_transfer_id" IS 'transfer refunded by this reversal, the reversals of a transfer refund at most its amount';
+
+COMMENT ON COLUMN "transfers"."status" IS 'posted, or pending until captured, voided or expired, only posted transfers have entries';
+
+COMMENT ON COLUMN "sessions"."family_id" IS 'id of the login session, shared by every session created by rotating its refresh token';
+
+COMMENT ON COLUMN "sessions"."rotated_at" IS 'set when the refresh token has been exchanged, presenting it again revokes the family';
+
+COMMENT ON COLUMN "users"."tokens_revoked_at" IS 'access tokens issued before this time are rejected';
+
+COMMENT ON COLUMN "revoked_tokens"."id" IS 'id of the access token payload';
+
+COMMENT ON COLUMN "reconciliation_reports"."findings" IS 'drifted accounts, orphaned entries and orphaned transfers found by the run';
+
+COMMENT ON COLUMN "scheduled_transfers"."username" IS 'user who scheduled the transfer, the transfers are made on its behalf';
+
+COMMENT ON COLUMN "scheduled_transfers"."amount" IS 'must be positive';
+
+COMMENT ON COLUMN "scheduled_transfers"."schedule" IS 'cron expression of five fields, @hourly, @daily, @weekly, @monthly, @yearly or @every <duration>, evaluated in UTC';
+
+COMMENT ON COLUMN "scheduled_transfers"."status" IS 'active or paused';
+
+COMMENT ON COLUMN "scheduled_transfers"."occurrence_at" IS 'occurrence of the schedule the next run executes';
+
+COMMENT ON COLUMN "scheduled_transfers"."next_run_at" IS 'occurrence_at, pushed back while a run is in progress or a failed run waits for its retry';
+
+COMMENT ON COLUMN "scheduled_transfers"."attempts" IS 'failed attempts of the current occurrence';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."transfer_id" IS 'transfer made by a successful run';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."error" IS 'failure of the run';
+
+COMMENT ON COLUMN "transfer_holds"."amount" IS 'amount held on the account, in its currency';
+
+COMMENT ON COLUMN "transfer_holds"."released_at" IS 'set when the transfer is captured, voided or expired';
+
+COMMENT ON TABLE "audit_events" IS 'append-only, updates and deletes are rejected by a trigger';
+
+COMMENT ON COLUMN "audit_events"."actor" IS 'user whose access token authorized the mutation, anonymous for public requests such as a login, system for the background jobs';
+
+COMMENT ON COLUMN "audit_events"."action" IS 'such as account.create or transfer.capture';
+
+COMMENT ON COLUMN "audit_events"."before" IS 'state of the resource before the mutation, null when it is created';
+
+COMMENT ON COLUMN "audit_events"."after" IS 'state of the resource after the mutation, null when it is deleted';
+
+COMMENT ON COLUMN "outbox_events"."event_type" IS 'such as transfer.posted or account.status_changed';
+
+COMMENT ON COLUMN "outbox_events"."attempts" IS 'failed publications of the event';
+
+COMMENT ON COLUMN "outbox_events"."available_at" IS 'the relay skips the event until then, while it is claimed or waits for its retry';
+
+COMMENT ON COLUMN "outbox_events"."published_at" IS 'set once the relay has published the event';
+
+COMMENT ON COLUMN "verify_emails"."email" IS 'address the code was sent to, it verifies the user only while it is still the email of the user';
+
+COMMENT ON COLUMN "tasks"."kind" IS 'such as send_verify_email, selects the handler of the worker';
+
+COMMENT ON COLUMN "tasks"."status" IS 'pending, done, or failed once its attempts are exhausted';
+
+COMMENT ON COLUMN "tasks"."run_at" IS 'the worker skips the task until then, while it is claimed or waits for its retry';
+
+ALTER TABLE "users" ADD FOREIGN KEY ("role") REFERENCES "roles" ("name");
+
+ALTER TABLE "role_permissions" ADD FOREIGN KEY ("role") REFERENCES "roles" ("name") ON DELETE CASCADE;
+
 ALTER TABLE "accounts" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
 
+ALTER TABLE "accounts" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
+
 ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
 
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "account_status_changes" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_status_changes" ADD FOREIGN KEY ("changed_by") REFERENCES "users" ("username");
+
 ALTER TABLE "transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
 
 ALTER TABLE "transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
 
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reversed_transfer_id") REFERENCES "transfers" ("id");
+
 ALTER TABLE "sessions" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "revoked_tokens" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_holds" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "verify_emails" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
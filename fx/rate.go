@@ -0,0 +1,107 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fayca121/simplebank/util"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// RateScale is the fixed point scale of Rate.Value, rates keep 8 decimal places
+const RateScale = 100_000_000
+
+const rateDecimals = 8
+
+var (
+	ErrRateNotFound   = errors.New("exchange rate not found")
+	ErrAmountTooSmall = errors.New("amount is too small to be converted")
+)
+
+// Rate is the price of one unit of From expressed in To, scaled by RateScale
+type Rate struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Value int64  `json:"value"`
+}
+
+type RateProvider interface {
+	Rate(ctx context.Context, from string, to string) (Rate, error)
+}
+
+// Convert converts an amount of From minor units into To minor units, using the
+// currency registry to account for currencies with a different number of decimals.
+// The result is truncated so that the bank never credits more than the debited amount is worth.
+func (rate Rate) Convert(amount int64) (int64, error) {
+	numerator := new(big.Int).Mul(big.NewInt(amount), big.NewInt(rate.Value))
+	denominator := big.NewInt(RateScale)
+	if shift := minorUnitsShift(rate.From, rate.To); shift > 0 {
+		numerator.Mul(numerator, pow10(shift))
+	} else if shift < 0 {
+		denominator.Mul(denominator, pow10(-shift))
+	}
+	converted := numerator.Quo(numerator, denominator)
+	if !converted.IsInt64() {
+		return 0, fmt.Errorf("converted amount overflows: %d %s to %s", amount, rate.From, rate.To)
+	}
+	if converted.Sign() == 0 && amount != 0 {
+		return 0, fmt.Errorf("%w: %d %s to %s", ErrAmountTooSmall, amount, rate.From, rate.To)
+	}
+	return converted.Int64(), nil
+}
+
+// minorUnitsShift is the difference of decimals between the two currencies, 0 when one is unknown
+func minorUnitsShift(from string, to string) int32 {
+	fromCurrency, ok := util.LookupCurrency(from)
+	if !ok {
+		return 0
+	}
+	toCurrency, ok := util.LookupCurrency(to)
+	if !ok {
+		return 0
+	}
+	return toCurrency.MinorUnits - fromCurrency.MinorUnits
+}
+
+func pow10(exponent int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
+
+// Inverse returns the rate from To to From
+func (rate Rate) Inverse() Rate {
+	return Rate{
+		From:  rate.To,
+		To:    rate.From,
+		Value: RateScale * RateScale / rate.Value,
+	}
+}
+
+func (rate Rate) String() string {
+	return FormatRate(rate.Value)
+}
+
+// ParseRate parses a decimal such as "0.92" into a value scaled by RateScale
+func ParseRate(value string) (int64, error) {
+	integer, fraction, _ := strings.Cut(strings.TrimSpace(value), ".")
+	if len(fraction) > rateDecimals {
+		return 0, fmt.Errorf("rate %s has more than %d decimals", value, rateDecimals)
+	}
+	fraction += strings.Repeat("0", rateDecimals-len(fraction))
+	scaled, err := strconv.ParseInt(integer+fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %s: %w", value, err)
+	}
+	if scaled <= 0 {
+		return 0, fmt.Errorf("rate %s must be positive", value)
+	}
+	return scaled, nil
+}
+
+// FormatRate is the inverse of ParseRate
+func FormatRate(value int64) string {
+	formatted := fmt.Sprintf("%d.%0*d", value/RateScale, rateDecimals, value%RateScale)
+	formatted = strings.TrimRight(formatted, "0")
+	return strings.TrimSuffix(formatted, ".")
+}
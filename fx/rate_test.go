@@ -0,0 +1,64 @@
+package fx
+
+import (
+	"github.com/fayca121/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseRate(t *testing.T) {
+	value, err := ParseRate("0.92")
+	require.NoError(t, err)
+	require.Equal(t, int64(92_000_000), value)
+	require.Equal(t, "0.92", FormatRate(value))
+
+	value, err = ParseRate("135")
+	require.NoError(t, err)
+	require.Equal(t, int64(135*RateScale), value)
+	require.Equal(t, "135", FormatRate(value))
+
+	_, err = ParseRate("0.123456789")
+	require.Error(t, err)
+
+	_, err = ParseRate("0")
+	require.Error(t, err)
+
+	_, err = ParseRate("abc")
+	require.Error(t, err)
+}
+
+func TestConvert(t *testing.T) {
+	rate := Rate{From: "USD", To: "EUR", Value: 92_000_000}
+
+	converted, err := rate.Convert(1000)
+	require.NoError(t, err)
+	require.Equal(t, int64(920), converted)
+
+	// truncated, never rounded up
+	converted, err = rate.Convert(999)
+	require.NoError(t, err)
+	require.Equal(t, int64(919), converted)
+
+	_, err = rate.Convert(1)
+	require.ErrorIs(t, err, ErrAmountTooSmall)
+}
+
+func TestConvertMinorUnits(t *testing.T) {
+	registry := util.Currencies
+	util.Currencies = util.NewCurrencyRegistry(
+		util.Currency{Code: util.USD, MinorUnits: 2, Enabled: true},
+		util.Currency{Code: "JPY", MinorUnits: 0, Enabled: true},
+	)
+	defer func() { util.Currencies = registry }()
+
+	// 10.00 USD at 150 JPY per USD is 1500 JPY
+	rate := Rate{From: util.USD, To: "JPY", Value: 150 * RateScale}
+	converted, err := rate.Convert(1000)
+	require.NoError(t, err)
+	require.Equal(t, int64(1500), converted)
+
+	// 1500 JPY back is 10.00 USD
+	converted, err = Rate{From: "JPY", To: util.USD, Value: 666_667}.Convert(1500)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), converted)
+}
@@ -0,0 +1,85 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type currencyPair struct {
+	from string
+	to   string
+}
+
+// StaticProvider serves a fixed set of rates, the inverse of every rate is derived
+// when it is not given explicitly
+type StaticProvider struct {
+	rates map[currencyPair]Rate
+}
+
+func NewStaticProvider(rates ...Rate) *StaticProvider {
+	provider := &StaticProvider{
+		rates: make(map[currencyPair]Rate),
+	}
+	for _, rate := range rates {
+		inverse := rate.Inverse()
+		if _, ok := provider.rates[currencyPair{inverse.From, inverse.To}]; !ok {
+			provider.rates[currencyPair{inverse.From, inverse.To}] = inverse
+		}
+		provider.rates[currencyPair{rate.From, rate.To}] = rate
+	}
+	return provider
+}
+
+type rateFileEntry struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Rate string `json:"rate"`
+}
+
+// NewFileProvider loads a static provider from a JSON file such as
+// [{"from": "USD", "to": "EUR", "rate": "0.92"}]
+func NewFileProvider(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read rates file: %w", err)
+	}
+	var entries []rateFileEntry
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("cannot decode rates file: %w", err)
+	}
+	rates := make([]Rate, 0, len(entries))
+	for _, entry := range entries {
+		value, err := ParseRate(entry.Rate)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, Rate{
+			From:  entry.From,
+			To:    entry.To,
+			Value: value,
+		})
+	}
+	return NewStaticProvider(rates...), nil
+}
+
+func (provider *StaticProvider) Rate(_ context.Context, from string, to string) (Rate, error) {
+	if from == to {
+		return Rate{From: from, To: to, Value: RateScale}, nil
+	}
+	rate, ok := provider.rates[currencyPair{from, to}]
+	if !ok {
+		return Rate{}, fmt.Errorf("%w: %s to %s", ErrRateNotFound, from, to)
+	}
+	return rate, nil
+}
+
+// NewRateProvider returns the file backed provider when path is set, otherwise a provider
+// that only converts a currency into itself
+func NewRateProvider(path string) (RateProvider, error) {
+	if path == "" {
+		return NewStaticProvider(), nil
+	}
+	return NewFileProvider(path)
+}
@@ -0,0 +1,33 @@
+package fx
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestFileProvider(t *testing.T) {
+	provider, err := NewFileProvider("testdata/rates.json")
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, "0.92", rate.String())
+
+	// derived from USD -> CAD
+	rate, err = provider.Rate(context.Background(), "CAD", "USD")
+	require.NoError(t, err)
+	require.Equal(t, "CAD", rate.From)
+	require.Equal(t, "USD", rate.To)
+	require.Equal(t, "0.74074074", rate.String())
+
+	rate, err = provider.Rate(context.Background(), "EUR", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, int64(RateScale), rate.Value)
+
+	_, err = provider.Rate(context.Background(), "EUR", "CAD")
+	require.ErrorIs(t, err, ErrRateNotFound)
+
+	_, err = NewFileProvider("testdata/missing.json")
+	require.Error(t, err)
+}
@@ -0,0 +1,29 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/token"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// accessibleAccount loads the account and checks that the authenticated user holds the permission
+// on it. Returned errors are gRPC status errors.
+func (server *Server) accessibleAccount(ctx context.Context, payload *token.Payload, permission authz.Permission, accountID int64) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return account, status.Errorf(codes.NotFound, "account [%d] not found", accountID)
+		}
+		return account, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	if err := authz.Authorize(payload, permission, account.Owner); err != nil {
+		return account, status.Errorf(codes.PermissionDenied, "account [%d]: %s", accountID, err)
+	}
+	return account, nil
+}
@@ -2,9 +2,12 @@ package gapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/fayca121/simplebank/token"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"strings"
 )
 
@@ -13,6 +16,8 @@ const (
 	authorizationTypeBearer = "bearer"
 )
 
+// authorizeUser returns the payload of the access token of the request. The credential errors are
+// plain errors, a failure to check the token is returned as a codes.Internal status.
 func (server *Server) authorizeUser(ctx context.Context) (*token.Payload, error) {
 	//1- get access token from header
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
@@ -35,6 +40,13 @@ func (server *Server) authorizeUser(ctx context.Context) (*token.Payload, error)
 		if err != nil {
 			return nil, fmt.Errorf("invalid token: %s", err)
 		}
+		if err = token.CheckRevocation(ctx, server.revocations, payload); err != nil {
+			if errors.Is(err, token.ErrRevokedToken) {
+				return nil, fmt.Errorf("invalid token: %w", err)
+			}
+			// the token may be valid, the revocation store failed
+			return nil, status.Errorf(codes.Internal, "cannot check token revocation: %s", err)
+		}
 		return payload, nil
 
 	}
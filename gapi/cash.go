@@ -0,0 +1,71 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// cashRequest is implemented by the Deposit and Withdraw requests
+type cashRequest interface {
+	GetAccountId() int64
+	GetAmount() int64
+	GetCurrency() string
+}
+
+// cashOperation moves the amount of the request between the account and the cash account of its currency
+func (server *Server) cashOperation(ctx context.Context, req cashRequest, permission authz.Permission,
+	operation func(context.Context, db.CashTxParam) (db.CashTxResult, error)) (db.CashTxResult, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return db.CashTxResult{}, err
+	}
+
+	violations := validateCashRequest(req)
+	if violations != nil {
+		return db.CashTxResult{}, invalidArgumentError(violations)
+	}
+
+	account, err := server.accessibleAccount(ctx, payload, permission, req.GetAccountId())
+	if err != nil {
+		return db.CashTxResult{}, err
+	}
+	if account.Currency != req.GetCurrency() {
+		return db.CashTxResult{}, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s",
+			account.ID, account.Currency, req.GetCurrency())
+	}
+
+	result, err := operation(ctx, db.CashTxParam{
+		AccountID: account.ID,
+		Amount:    req.GetAmount(),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrCashAccount) {
+			return result, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountFrozen) ||
+			errors.Is(err, db.ErrAccountClosed) {
+			return result, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return result, status.Errorf(codes.Internal, "failed to move cash: %s", err)
+	}
+	return result, nil
+}
+
+func validateCashRequest(req cashRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	if err := val.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+	return
+}
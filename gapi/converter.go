@@ -0,0 +1,152 @@
+package gapi
+
+import (
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/fx"
+	"github.com/fayca121/simplebank/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:               account.ID,
+		Owner:            account.Owner,
+		Balance:          account.Balance,
+		Currency:         account.Currency,
+		CreatedAt:        timestamppb.New(account.CreatedAt),
+		OverdraftLimit:   account.OverdraftLimit,
+		HeldAmount:       account.HeldAmount,
+		AvailableBalance: account.AvailableBalance,
+		Status:           account.Status,
+	}
+}
+
+func convertAccountStatusChange(change db.AccountStatusChange) *pb.AccountStatusChange {
+	return &pb.AccountStatusChange{
+		Id:         change.ID,
+		AccountId:  change.AccountID,
+		FromStatus: change.FromStatus,
+		ToStatus:   change.ToStatus,
+		Reason:     change.Reason,
+		ChangedBy:  change.ChangedBy,
+		CreatedAt:  timestamppb.New(change.CreatedAt),
+	}
+}
+
+func convertAuditEvent(event db.AuditEvent) *pb.AuditEvent {
+	return &pb.AuditEvent{
+		Id:           event.ID,
+		Actor:        event.Actor,
+		ActorRole:    event.ActorRole,
+		ClientIp:     event.ClientIp,
+		UserAgent:    event.UserAgent,
+		Action:       event.Action,
+		ResourceType: event.ResourceType,
+		ResourceId:   event.ResourceID,
+		Before:       string(event.Before),
+		After:        string(event.After),
+		CreatedAt:    timestamppb.New(event.CreatedAt),
+	}
+}
+
+func convertEntry(entry db.Entry) *pb.Entry {
+	pbEntry := &pb.Entry{
+		Id:        entry.ID,
+		AccountId: entry.AccountID,
+		Amount:    entry.Amount,
+		CreatedAt: timestamppb.New(entry.CreatedAt),
+	}
+	if entry.TransferID.Valid {
+		pbEntry.TransferId = &entry.TransferID.Int64
+	}
+	return pbEntry
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	pbTransfer := &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+		ToAmount:      transfer.ToAmount,
+		ExchangeRate:  fx.FormatRate(transfer.ExchangeRate),
+		Kind:          transfer.Kind,
+		Status:        transfer.Status,
+	}
+	if transfer.ReversedTransferID.Valid {
+		pbTransfer.ReversedTransferId = &transfer.ReversedTransferID.Int64
+	}
+	return pbTransfer
+}
+
+func convertTransferHold(hold db.TransferHold) *pb.TransferHold {
+	pbHold := &pb.TransferHold{
+		Id:         hold.ID,
+		TransferId: hold.TransferID,
+		AccountId:  hold.AccountID,
+		Amount:     hold.Amount,
+		ExpiresAt:  timestamppb.New(hold.ExpiresAt),
+		CreatedAt:  timestamppb.New(hold.CreatedAt),
+	}
+	if hold.ReleasedAt.Valid {
+		pbHold.ReleasedAt = timestamppb.New(hold.ReleasedAt.Time)
+	}
+	return pbHold
+}
+
+func convertUser(user db.User) *pb.User {
+	return &pb.User{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
+		CreatedAt:         timestamppb.New(user.CreatedAt),
+		IsEmailVerified:   user.IsEmailVerified,
+	}
+}
+
+func convertSession(session db.Session) *pb.Session {
+	return &pb.Session{
+		Id:        session.ID.String(),
+		Username:  session.Username,
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		IsBlocked: session.IsBlocked,
+		ExpiresAt: timestamppb.New(session.ExpiresAt),
+		CreatedAt: timestamppb.New(session.CreatedAt),
+	}
+}
+
+func convertScheduledTransfer(scheduled db.ScheduledTransfer) *pb.ScheduledTransfer {
+	return &pb.ScheduledTransfer{
+		Id:            scheduled.ID,
+		Username:      scheduled.Username,
+		FromAccountId: scheduled.FromAccountID,
+		ToAccountId:   scheduled.ToAccountID,
+		Amount:        scheduled.Amount,
+		Schedule:      scheduled.Schedule,
+		Status:        scheduled.Status,
+		OccurrenceAt:  timestamppb.New(scheduled.OccurrenceAt),
+		NextRunAt:     timestamppb.New(scheduled.NextRunAt),
+		Attempts:      scheduled.Attempts,
+		CreatedAt:     timestamppb.New(scheduled.CreatedAt),
+	}
+}
+
+func convertScheduledTransferRun(run db.ScheduledTransferRun) *pb.ScheduledTransferRun {
+	pbRun := &pb.ScheduledTransferRun{
+		Id:                  run.ID,
+		ScheduledTransferId: run.ScheduledTransferID,
+		OccurrenceAt:        timestamppb.New(run.OccurrenceAt),
+		Attempt:             run.Attempt,
+		CreatedAt:           timestamppb.New(run.CreatedAt),
+	}
+	if run.TransferID.Valid {
+		pbRun.TransferId = &run.TransferID.Int64
+	}
+	if run.Error.Valid {
+		pbRun.Error = &run.Error.String
+	}
+	return pbRun
+}
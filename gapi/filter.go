@@ -0,0 +1,55 @@
+package gapi
+
+import (
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// listFilterRequest is implemented by the requests listing the entries or the transfers of an account
+type listFilterRequest interface {
+	GetCreatedFrom() *timestamppb.Timestamp
+	GetCreatedTo() *timestamppb.Timestamp
+	GetDirection() string
+}
+
+// newListFilter converts the optional filters of the request, the optional scalar fields are passed
+// as is since only the generated structs know whether they are set
+func newListFilter(req listFilterRequest, minAmount *int64, maxAmount *int64, counterpartyID *int64) db.ListFilter {
+	return db.ListFilter{
+		Direction:      req.GetDirection(),
+		CounterpartyID: counterpartyID,
+		CreatedFrom:    timeOf(req.GetCreatedFrom()),
+		CreatedTo:      timeOf(req.GetCreatedTo()),
+		MinAmount:      minAmount,
+		MaxAmount:      maxAmount,
+	}
+}
+
+func timeOf(timestamp *timestamppb.Timestamp) *time.Time {
+	if timestamp == nil {
+		return nil
+	}
+	value := timestamp.AsTime()
+	return &value
+}
+
+func validateListFilter(filter db.ListFilter) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateTimeRange(filter.CreatedFrom, filter.CreatedTo); err != nil {
+		violations = append(violations, fieldViolation("created_to", err))
+	}
+	if err := val.ValidateAmountRange(filter.MinAmount, filter.MaxAmount); err != nil {
+		violations = append(violations, fieldViolation("max_amount", err))
+	}
+	if err := val.ValidateDirection(filter.Direction); err != nil {
+		violations = append(violations, fieldViolation("direction", err))
+	}
+	if filter.CounterpartyID != nil {
+		if err := val.ValidateID(*filter.CounterpartyID); err != nil {
+			violations = append(violations, fieldViolation("counterparty_account_id", err))
+		}
+	}
+	return
+}
@@ -0,0 +1,136 @@
+package gapi
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+// methodPolicy tells the auth interceptors how to guard an RPC: public RPCs are served without
+// an access token, the others need a valid one and, when permission is set, a role holding the
+// permission or its :any variant. The handler checks the owner of the resource.
+type methodPolicy struct {
+	public     bool
+	permission authz.Permission
+}
+
+// methodPolicies holds the policy of each RPC, an RPC missing from the table needs a valid
+// access token with any role
+var methodPolicies = map[string]methodPolicy{
+	pb.SimpleBank_CreateUser_FullMethodName:       {public: true},
+	pb.SimpleBank_LoginUser_FullMethodName:        {public: true},
+	pb.SimpleBank_RenewAccessToken_FullMethodName: {public: true},
+	// the secret code of the request proves the ownership of the email
+	pb.SimpleBank_VerifyEmail_FullMethodName: {public: true},
+	// the refresh token of the request authenticates the caller
+	pb.SimpleBank_LogoutUser_FullMethodName:              {public: true},
+	pb.SimpleBank_CreateAccount_FullMethodName:           {permission: authz.AccountCreate},
+	pb.SimpleBank_CreateTransfer_FullMethodName:          {permission: authz.TransferCreate},
+	pb.SimpleBank_Deposit_FullMethodName:                 {permission: authz.AccountDeposit},
+	pb.SimpleBank_UpdateAccountStatus_FullMethodName:     {permission: authz.AccountStatus},
+	pb.SimpleBank_UpdateOverdraftLimit_FullMethodName:    {permission: authz.AccountOverdraft},
+	pb.SimpleBank_Withdraw_FullMethodName:                {permission: authz.AccountWithdraw},
+	pb.SimpleBank_ReverseTransfer_FullMethodName:         {permission: authz.TransferReverse},
+	pb.SimpleBank_AuthorizeTransfer_FullMethodName:       {permission: authz.TransferCreate},
+	pb.SimpleBank_CaptureTransfer_FullMethodName:         {permission: authz.TransferCapture},
+	pb.SimpleBank_VoidTransfer_FullMethodName:            {permission: authz.TransferCapture},
+	pb.SimpleBank_CreateScheduledTransfer_FullMethodName: {permission: authz.TransferCreate},
+	pb.SimpleBank_UpdateScheduledTransfer_FullMethodName: {permission: authz.TransferCreate},
+	pb.SimpleBank_DeleteScheduledTransfer_FullMethodName: {permission: authz.TransferCreate},
+	pb.SimpleBank_ListAuditEvents_FullMethodName:         {permission: authz.AuditRead},
+}
+
+// publicServices are the services whose every RPC is public
+var publicServices = []string{
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+func policyOf(fullMethod string) methodPolicy {
+	if policy, ok := methodPolicies[fullMethod]; ok {
+		return policy
+	}
+	for _, service := range publicServices {
+		if strings.HasPrefix(fullMethod, service) {
+			return methodPolicy{public: true}
+		}
+	}
+	return methodPolicy{}
+}
+
+type payloadKey struct{}
+
+// authenticate applies the policy of the RPC and returns the context carrying the payload
+// of the access token, and the actor the mutations of the RPC are audited as
+func (server *Server) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	policy := policyOf(fullMethod)
+	mtdt := server.extractMetadata(ctx)
+	if policy.public {
+		return db.WithActor(ctx, db.Actor{
+			Username:  db.ActorAnonymous,
+			ClientIP:  mtdt.ClientIP,
+			UserAgent: mtdt.UserAgent,
+		}), nil
+	}
+
+	payload, err := server.authorizeUser(ctx)
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.Unauthenticated, "unauthorized: %s", err)
+	}
+	if policy.permission != "" && !authz.Holds(payload.Role, policy.permission) {
+		return nil, status.Errorf(codes.PermissionDenied, "role %s cannot call %s", payload.Role, fullMethod)
+	}
+	ctx = db.WithActor(ctx, db.Actor{
+		Username:  payload.Username,
+		Role:      payload.Role,
+		ClientIP:  mtdt.ClientIP,
+		UserAgent: mtdt.UserAgent,
+	})
+	return context.WithValue(ctx, payloadKey{}, payload), nil
+}
+
+// authenticatedUser returns the payload the auth interceptors put in the context
+func authenticatedUser(ctx context.Context) (*token.Payload, error) {
+	payload, ok := ctx.Value(payloadKey{}).(*token.Payload)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "unauthorized: missing access token")
+	}
+	return payload, nil
+}
+
+func (server *Server) UnaryAuthInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (any, error) {
+	ctx, err := server.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authenticatedStream overrides the context of the stream with the authenticated one
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *authenticatedStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (server *Server) StreamAuthInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	ctx, err := server.authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
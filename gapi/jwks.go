@@ -0,0 +1,35 @@
+package gapi
+
+import (
+	"encoding/json"
+	"github.com/fayca121/simplebank/token"
+	"net/http"
+)
+
+// JWKSPath is where the gateway publishes the keys verifying the access tokens
+const JWKSPath = "/.well-known/jwks.json"
+
+// JWKSHandler publishes the public keys of the token maker, so other services can verify
+// our tokens offline. It answers 404 when the tokens are symmetric.
+func (server *Server) JWKSHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		publisher, ok := server.tokenMaker.(token.KeySetPublisher)
+		if !ok {
+			http.Error(w, "tokens are not signed with public keys", http.StatusNotFound)
+			return
+		}
+		keySet, err := publisher.KeySet()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		_ = json.NewEncoder(w).Encode(keySet)
+	})
+}
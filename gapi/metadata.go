@@ -2,37 +2,84 @@ package gapi
 
 import (
 	"context"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+	"net"
+	"strings"
 )
 
 const (
 	grpcGatewayUserAgentHeader = "grpcgateway-user-agent"
 	userAgentHeader            = "user-agent"
 	xForwardedForHeader        = "x-forwarded-for"
+	idempotencyKeyHeader       = "idempotency-key"
 )
 
 type Metadata struct {
-	UserAgent string
-	ClientIP  string
+	UserAgent      string
+	ClientIP       string
+	IdempotencyKey string
+}
+
+// IncomingHeaderMatcher forwards the Idempotency-Key HTTP header to the gRPC metadata,
+// on top of the headers forwarded by default by the gateway
+func IncomingHeaderMatcher(key string) (string, bool) {
+	if strings.ToLower(key) == idempotencyKeyHeader {
+		return idempotencyKeyHeader, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
 }
 
 func (server *Server) extractMetadata(ctx context.Context) *Metadata {
 	mtdt := &Metadata{}
 
+	p, hasPeer := peer.FromContext(ctx)
+	fromGateway := hasPeer && server.isGateway(p.Addr)
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
-		if userAgents := md.Get(grpcGatewayUserAgentHeader); len(userAgents) > 0 {
+		// the gateway calls the server with its own user agent and passes the one of the client aside,
+		// like x-forwarded-for it is only trusted from the gateway
+		if userAgents := md.Get(userAgentHeader); len(userAgents) > 0 {
 			mtdt.UserAgent = userAgents[0]
 		}
-		if userAgents := md.Get(userAgentHeader); len(userAgents) > 0 {
+		if userAgents := md.Get(grpcGatewayUserAgentHeader); fromGateway && len(userAgents) > 0 {
 			mtdt.UserAgent = userAgents[0]
 		}
-		if clientIPs := md.Get(xForwardedForHeader); len(clientIPs) > 0 {
-			mtdt.ClientIP = clientIPs[0]
+		if clientIPs := md.Get(xForwardedForHeader); fromGateway && len(clientIPs) > 0 {
+			mtdt.ClientIP = lastForwardedFor(clientIPs)
+		}
+		if keys := md.Get(idempotencyKeyHeader); len(keys) > 0 {
+			mtdt.IdempotencyKey = keys[0]
 		}
 	}
-	if p, ok := peer.FromContext(ctx); ok {
+	if hasPeer && mtdt.ClientIP == "" {
 		mtdt.ClientIP = p.Addr.String()
 	}
 	return mtdt
 }
+
+// isGateway reports whether the peer is the gateway relaying the HTTP requests: a loopback address,
+// the gateway runs in the process of the server, or GATEWAY_ADDRESS when it runs apart.
+// The x-forwarded-for and grpcgateway-user-agent of any other peer are set by the client and can't be trusted.
+func (server *Server) isGateway(addr net.Addr) bool {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		return true
+	}
+	gateway := net.ParseIP(server.config.GatewayAddress)
+	return gateway != nil && gateway.Equal(ip)
+}
+
+// lastForwardedFor returns the address appended by the gateway, the ones before it come from the
+// X-Forwarded-For header sent by the client
+func lastForwardedFor(values []string) string {
+	addresses := strings.Split(values[len(values)-1], ",")
+	return strings.TrimSpace(addresses[len(addresses)-1])
+}
@@ -0,0 +1,102 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+func (server *Server) AuthorizeTransfer(ctx context.Context, req *pb.AuthorizeTransferRequest) (*pb.AuthorizeTransferResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	violations := validateAuthorizeTransferRequest(req, now)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	fromAccount, err := server.accessibleAccount(ctx, payload, authz.TransferCreate, req.GetFromAccountId())
+	if err != nil {
+		return nil, err
+	}
+	toAccount, err := server.store.GetAccount(ctx, req.GetToAccountId())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account [%d] not found", req.GetToAccountId())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+	// the amount is captured later, without conversion, both accounts hold the same currency
+	for _, account := range []db.Account{fromAccount, toAccount} {
+		if account.Currency != req.GetCurrency() {
+			return nil, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s",
+				account.ID, account.Currency, req.GetCurrency())
+		}
+	}
+
+	expiresAt := now.Add(db.DefaultHoldDuration)
+	if req.ExpiresAt != nil {
+		expiresAt = req.GetExpiresAt().AsTime()
+	}
+	result, err := server.store.AuthorizeTransferTx(ctx, db.AuthorizeTransferTxParam{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+		ExpiresAt:     expiresAt,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrCashAccount) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountFrozen) ||
+			errors.Is(err, db.ErrAccountClosed) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to authorize transfer: %s", err)
+	}
+
+	resp := &pb.AuthorizeTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		Hold:        convertTransferHold(result.Hold),
+		FromAccount: convertAccount(result.FromAccount),
+	}
+	return resp, nil
+}
+
+func validateAuthorizeTransferRequest(req *pb.AuthorizeTransferRequest, now time.Time) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+	if err := val.ValidateID(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	}
+	if req.GetFromAccountId() == req.GetToAccountId() {
+		violations = append(violations, fieldViolation("to_account_id",
+			errors.New("must be different from from_account_id")))
+	}
+	if err := val.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+	if req.ExpiresAt != nil {
+		expiresAt := req.GetExpiresAt().AsTime()
+		if !expiresAt.After(now) || expiresAt.After(now.Add(db.MaxHoldDuration)) {
+			violations = append(violations, fieldViolation("expires_at", fmt.Errorf("must be within %s", db.MaxHoldDuration)))
+		}
+	}
+	return
+}
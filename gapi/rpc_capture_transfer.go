@@ -0,0 +1,47 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CaptureTransfer(ctx context.Context, req *pb.CaptureTransferRequest) (*pb.CaptureTransferResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := val.ValidateID(req.GetTransferId()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("transfer_id", err)})
+	}
+
+	transfer, err := server.pendingTransfer(ctx, payload, req.GetTransferId())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.CaptureTransferTx(ctx, transfer.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrTransferNotPending) || errors.Is(err, db.ErrHoldExpired) ||
+			errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountFrozen) ||
+			errors.Is(err, db.ErrAccountClosed) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to capture transfer: %s", err)
+	}
+
+	resp := &pb.CaptureTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+	}
+	return resp, nil
+}
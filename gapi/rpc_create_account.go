@@ -0,0 +1,60 @@
+package gapi
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateCreateAccountRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if err := authz.Authorize(payload, authz.AccountCreate, req.GetOwner()); err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+	}
+
+	arg := db.CreateAccountParams{
+		Owner:    req.GetOwner(),
+		Balance:  0,
+		Currency: req.GetCurrency(),
+	}
+
+	account, err := server.store.CreateAccount(ctx, arg)
+	if err != nil {
+		switch db.ErrCode(err) {
+		case db.UniqueViolation:
+			return nil, status.Errorf(codes.AlreadyExists, "account already exists: %s", err)
+		case db.ForeignKeyViolation:
+			return nil, status.Errorf(codes.FailedPrecondition, "owner does not exist: %s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create account: %s", err)
+	}
+
+	resp := &pb.CreateAccountResponse{
+		Account: convertAccount(account),
+	}
+	return resp, nil
+}
+
+func validateCreateAccountRequest(req *pb.CreateAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateUsername(req.GetOwner()); err != nil {
+		violations = append(violations, fieldViolation("owner", err))
+	}
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+	return
+}
@@ -0,0 +1,102 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/schedule"
+	"github.com/fayca121/simplebank/val"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+func (server *Server) CreateScheduledTransfer(ctx context.Context, req *pb.CreateScheduledTransferRequest) (*pb.CreateScheduledTransferResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	violations := validateCreateScheduledTransferRequest(req, now)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	fromAccount, err := server.accessibleAccount(ctx, payload, authz.TransferCreate, req.GetFromAccountId())
+	if err != nil {
+		return nil, err
+	}
+	toAccount, err := server.store.GetAccount(ctx, req.GetToAccountId())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account [%d] not found", req.GetToAccountId())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+	// the transfers are made without conversion, both accounts hold the same currency
+	for _, account := range []db.Account{fromAccount, toAccount} {
+		if account.Currency != req.GetCurrency() {
+			return nil, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s",
+				account.ID, account.Currency, req.GetCurrency())
+		}
+	}
+
+	start := now
+	if req.StartAt != nil {
+		start = req.GetStartAt().AsTime()
+	}
+	sched, err := schedule.Parse(req.GetSchedule())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+	first := schedule.First(sched, start)
+
+	scheduled, err := server.store.CreateScheduledTransfer(ctx, db.CreateScheduledTransferParams{
+		Username:      payload.Username,
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+		Schedule:      req.GetSchedule(),
+		OccurrenceAt:  first,
+		NextRunAt:     first,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create scheduled transfer: %s", err)
+	}
+
+	resp := &pb.CreateScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(scheduled),
+	}
+	return resp, nil
+}
+
+func validateCreateScheduledTransferRequest(req *pb.CreateScheduledTransferRequest, now time.Time) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+	if err := val.ValidateID(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	}
+	if req.GetFromAccountId() == req.GetToAccountId() {
+		violations = append(violations, fieldViolation("to_account_id",
+			errors.New("must be different from from_account_id")))
+	}
+	if err := val.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+	if err := val.ValidateSchedule(req.GetSchedule()); err != nil {
+		violations = append(violations, fieldViolation("schedule", err))
+	}
+	if req.StartAt != nil && req.GetStartAt().AsTime().Before(now) {
+		violations = append(violations, fieldViolation("start_at", errors.New("must not be in the past")))
+	}
+	return
+}
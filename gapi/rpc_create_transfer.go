@@ -0,0 +1,119 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/fx"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateCreateTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	fromAccount, err := server.accessibleAccount(ctx, payload, authz.TransferCreate, req.GetFromAccountId())
+	if err != nil {
+		return nil, err
+	}
+	if fromAccount.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s",
+			fromAccount.ID, fromAccount.Currency, req.GetCurrency())
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, req.GetToAccountId())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account [%d] not found", req.GetToAccountId())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	mtdt := server.extractMetadata(ctx)
+	if err = val.ValidateIdempotencyKey(mtdt.IdempotencyKey); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+			fieldViolation(idempotencyKeyHeader, err),
+		})
+	}
+
+	arg := db.TransferTxParam{
+		FromAccountID:  req.GetFromAccountId(),
+		ToAccountID:    req.GetToAccountId(),
+		Amount:         req.GetAmount(),
+		Username:       payload.Username,
+		IdempotencyKey: mtdt.IdempotencyKey,
+	}
+
+	var result db.TransferTxResult
+	if toAccount.Currency == fromAccount.Currency {
+		result, err = server.store.TransferTx(ctx, arg)
+	} else {
+		// the destination account holds another currency, the amount is converted
+		rate, rateErr := server.rates.Rate(ctx, fromAccount.Currency, toAccount.Currency)
+		if rateErr != nil {
+			if errors.Is(rateErr, fx.ErrRateNotFound) {
+				return nil, status.Errorf(codes.InvalidArgument, "%s", rateErr)
+			}
+			return nil, status.Errorf(codes.Internal, "failed to get exchange rate: %s", rateErr)
+		}
+		result, err = server.store.CrossCurrencyTransferTx(ctx, db.CrossCurrencyTransferTxParam{
+			TransferTxParam: arg,
+			Rate:            rate,
+		})
+	}
+	if err != nil {
+		if errors.Is(err, fx.ErrAmountTooSmall) || errors.Is(err, db.ErrCashAccount) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountFrozen) ||
+			errors.Is(err, db.ErrAccountClosed) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		if errors.Is(err, db.ErrIdempotencyKeyConflict) {
+			return nil, status.Errorf(codes.AlreadyExists, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create transfer: %s", err)
+	}
+
+	resp := &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+	}
+	return resp, nil
+}
+
+func validateCreateTransferRequest(req *pb.CreateTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+	if err := val.ValidateID(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	}
+	if req.GetFromAccountId() == req.GetToAccountId() {
+		violations = append(violations, fieldViolation("to_account_id",
+			errors.New("must be different from from_account_id")))
+	}
+	if err := val.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+	return
+}
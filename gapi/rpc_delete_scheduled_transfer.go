@@ -0,0 +1,32 @@
+package gapi
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/authz"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) DeleteScheduledTransfer(ctx context.Context, req *pb.DeleteScheduledTransferRequest) (*pb.DeleteScheduledTransferResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := val.ValidateID(req.GetId()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("id", err)})
+	}
+
+	scheduled, err := server.accessibleScheduledTransfer(ctx, payload, authz.TransferCreate, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = server.store.DeleteScheduledTransfer(ctx, scheduled.ID); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete scheduled transfer: %s", err)
+	}
+	return &pb.DeleteScheduledTransferResponse{}, nil
+}
@@ -0,0 +1,21 @@
+package gapi
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/authz"
+	"github.com/fayca121/simplebank/pb"
+)
+
+func (server *Server) Deposit(ctx context.Context, req *pb.DepositRequest) (*pb.DepositResponse, error) {
+	result, err := server.cashOperation(ctx, req, authz.AccountDeposit, server.store.DepositTx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.DepositResponse{
+		Transfer: convertTransfer(result.Transfer),
+		Account:  convertAccount(result.Account),
+		Entry:    convertEntry(result.Entry),
+	}
+	return resp, nil
+}
@@ -0,0 +1,85 @@
+package gapi
+
+import (
+	"bufio"
+	"github.com/fayca121/simplebank/authz"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/statement"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statementChunkSize is the largest chunk of the statement sent in a message
+const statementChunkSize = 32 * 1024
+
+func (server *Server) GenerateStatement(req *pb.GenerateStatementRequest, stream pb.SimpleBank_GenerateStatementServer) error {
+	ctx := stream.Context()
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	violations := validateGenerateStatementRequest(req)
+	if violations != nil {
+		return invalidArgumentError(violations)
+	}
+	format, _ := statement.ParseFormat(req.GetFormat())
+
+	account, err := server.accessibleAccount(ctx, payload, authz.AccountRead, req.GetAccountId())
+	if err != nil {
+		return err
+	}
+
+	generated, err := statement.Generate(ctx, server.store, account, req.GetFrom().AsTime(), req.GetTo().AsTime())
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to generate statement: %s", err)
+	}
+
+	writer := bufio.NewWriterSize(&statementWriter{stream: stream, contentType: format.ContentType()}, statementChunkSize)
+	if err = statement.Write(writer, generated, format); err != nil {
+		return status.Errorf(codes.Internal, "failed to write statement: %s", err)
+	}
+	if err = writer.Flush(); err != nil {
+		return status.Errorf(codes.Internal, "failed to send statement: %s", err)
+	}
+	return nil
+}
+
+// statementWriter sends each write as a chunk of the statement
+type statementWriter struct {
+	stream      pb.SimpleBank_GenerateStatementServer
+	contentType string
+}
+
+func (writer *statementWriter) Write(data []byte) (int, error) {
+	chunk := &httpbody.HttpBody{
+		ContentType: writer.contentType,
+		Data:        append([]byte(nil), data...),
+	}
+	if err := writer.stream.Send(chunk); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func validateGenerateStatementRequest(req *pb.GenerateStatementRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	if err := req.GetFrom().CheckValid(); err != nil {
+		violations = append(violations, fieldViolation("from", err))
+	}
+	if err := req.GetTo().CheckValid(); err != nil {
+		violations = append(violations, fieldViolation("to", err))
+	}
+	if err := val.ValidateStatementPeriod(req.GetFrom().AsTime(), req.GetTo().AsTime()); err != nil {
+		violations = append(violations, fieldViolation("to", err))
+	}
+	if _, err := statement.ParseFormat(req.GetFormat()); err != nil {
+		violations = append(violations, fieldViolation("format", err))
+	}
+	return
+}
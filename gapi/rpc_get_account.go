@@ -0,0 +1,38 @@
+package gapi
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/authz"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateGetAccountRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.accessibleAccount(ctx, payload, authz.AccountRead, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.GetAccountResponse{
+		Account: convertAccount(account),
+	}
+	return resp, nil
+}
+
+func validateGetAccountRequest(req *pb.GetAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+	return
+}
@@ -0,0 +1,45 @@
+package gapi
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) GetScheduledTransfer(ctx context.Context, req *pb.GetScheduledTransferRequest) (*pb.GetScheduledTransferResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := val.ValidateID(req.GetId()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("id", err)})
+	}
+
+	scheduled, err := server.accessibleScheduledTransfer(ctx, payload, authz.AccountRead, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(ctx, db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		Limit:               recentRuns,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list scheduled transfer runs: %s", err)
+	}
+
+	resp := &pb.GetScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(scheduled),
+		Runs:              make([]*pb.ScheduledTransferRun, 0, len(runs)),
+	}
+	for _, run := range runs {
+		resp.Runs = append(resp.Runs, convertScheduledTransferRun(run))
+	}
+	return resp, nil
+}
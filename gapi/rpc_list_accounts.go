@@ -0,0 +1,62 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pagination"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateListAccountsRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	scope := pagination.AccountsScope(payload.Username)
+	after, err := server.pages.Keyset(scope, req.GetPageToken())
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", err)})
+	}
+
+	arg := db.ListAccountsParams{
+		Owner:          payload.Username,
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+		Limit:          req.GetPageSize() + 1,
+	}
+
+	accounts, err := server.store.ListAccounts(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list accounts: %s", err)
+	}
+
+	accounts, next := pagination.NextPage(server.pages, scope, accounts, req.GetPageSize(), func(account db.Account) pagination.Cursor {
+		return pagination.NewCursor(account.CreatedAt, account.ID)
+	})
+
+	resp := &pb.ListAccountsResponse{
+		Accounts:      make([]*pb.Account, 0, len(accounts)),
+		NextPageToken: next,
+	}
+	for _, account := range accounts {
+		resp.Accounts = append(resp.Accounts, convertAccount(account))
+	}
+	return resp, nil
+}
+
+func validateListAccountsRequest(req *pb.ListAccountsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	return
+}
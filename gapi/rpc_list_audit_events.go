@@ -0,0 +1,77 @@
+package gapi
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pagination"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListAuditEvents(ctx context.Context, req *pb.ListAuditEventsRequest) (*pb.ListAuditEventsResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateListAuditEventsRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	// the events belong to no one, only the :any variant of the permission lets them be read
+	if err = authz.Authorize(payload, authz.AuditRead, ""); err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+	}
+
+	scope := pagination.AuditEventsScope()
+	after, err := server.pages.Keyset(scope, req.GetPageToken())
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", err)})
+	}
+	filter := newAuditFilter(req)
+
+	events, err := server.store.ListAuditEvents(ctx, filter.AuditEventsParams(after, req.GetPageSize()+1))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list audit events: %s", err)
+	}
+
+	events, next := pagination.NextPage(server.pages, scope, events, req.GetPageSize(), func(event db.AuditEvent) pagination.Cursor {
+		return pagination.NewCursor(event.CreatedAt, event.ID)
+	})
+
+	resp := &pb.ListAuditEventsResponse{
+		Events:        make([]*pb.AuditEvent, 0, len(events)),
+		NextPageToken: next,
+	}
+	for _, event := range events {
+		resp.Events = append(resp.Events, convertAuditEvent(event))
+	}
+	return resp, nil
+}
+
+func newAuditFilter(req *pb.ListAuditEventsRequest) db.AuditFilter {
+	return db.AuditFilter{
+		Actor:        req.GetActor(),
+		Action:       req.GetAction(),
+		ResourceType: req.GetResourceType(),
+		ResourceID:   req.GetResourceId(),
+		CreatedFrom:  timeOf(req.GetCreatedFrom()),
+		CreatedTo:    timeOf(req.GetCreatedTo()),
+	}
+}
+
+func validateListAuditEventsRequest(req *pb.ListAuditEventsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	filter := newAuditFilter(req)
+	if err := val.ValidateTimeRange(filter.CreatedFrom, filter.CreatedTo); err != nil {
+		violations = append(violations, fieldViolation("created_to", err))
+	}
+	return
+}
@@ -0,0 +1,67 @@
+package gapi
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pagination"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListEntries(ctx context.Context, req *pb.ListEntriesRequest) (*pb.ListEntriesResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateListEntriesRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if _, err = server.accessibleAccount(ctx, payload, authz.AccountRead, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	filter := newListFilter(req, req.MinAmount, req.MaxAmount, req.CounterpartyAccountId)
+	scope := pagination.EntriesScope(req.GetAccountId())
+	after, err := server.pages.Keyset(scope, req.GetPageToken())
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", err)})
+	}
+	arg := filter.EntriesParams(req.GetAccountId(), after, req.GetPageSize()+1)
+
+	entries, err := server.store.ListEntries(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list entries: %s", err)
+	}
+
+	entries, next := pagination.NextPage(server.pages, scope, entries, req.GetPageSize(), func(entry db.Entry) pagination.Cursor {
+		return pagination.NewCursor(entry.CreatedAt, entry.ID)
+	})
+
+	resp := &pb.ListEntriesResponse{
+		Entries:       make([]*pb.Entry, 0, len(entries)),
+		NextPageToken: next,
+	}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, convertEntry(entry))
+	}
+	return resp, nil
+}
+
+func validateListEntriesRequest(req *pb.ListEntriesRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	filter := newListFilter(req, req.MinAmount, req.MaxAmount, req.CounterpartyAccountId)
+	violations = append(violations, validateListFilter(filter)...)
+	return
+}
@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pagination"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListScheduledTransfers(ctx context.Context, req *pb.ListScheduledTransfersRequest) (*pb.ListScheduledTransfersResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateListScheduledTransfersRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if _, err = server.accessibleAccount(ctx, payload, authz.AccountRead, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	scope := pagination.ScheduledTransfersScope(req.GetAccountId())
+	after, err := server.pages.Keyset(scope, req.GetPageToken())
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", err)})
+	}
+
+	scheduled, err := server.store.ListScheduledTransfers(ctx, db.ListScheduledTransfersParams{
+		FromAccountID:  req.GetAccountId(),
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+		Limit:          req.GetPageSize() + 1,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list scheduled transfers: %s", err)
+	}
+
+	scheduled, next := pagination.NextPage(server.pages, scope, scheduled, req.GetPageSize(), func(scheduled db.ScheduledTransfer) pagination.Cursor {
+		return pagination.NewCursor(scheduled.CreatedAt, scheduled.ID)
+	})
+
+	resp := &pb.ListScheduledTransfersResponse{
+		ScheduledTransfers: make([]*pb.ScheduledTransfer, 0, len(scheduled)),
+		NextPageToken:      next,
+	}
+	for _, item := range scheduled {
+		resp.ScheduledTransfers = append(resp.ScheduledTransfers, convertScheduledTransfer(item))
+	}
+	return resp, nil
+}
+
+func validateListScheduledTransfersRequest(req *pb.ListScheduledTransfersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	return
+}
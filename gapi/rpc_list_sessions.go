@@ -0,0 +1,76 @@
+package gapi
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pagination"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateListSessionsRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	username := payload.Username
+	if req.Username != nil {
+		username = req.GetUsername()
+	}
+	if err := authz.Authorize(payload, authz.SessionRead, username); err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+	}
+
+	scope := pagination.SessionsScope(username)
+	afterCreatedAt, afterID, err := server.pages.UUIDKeyset(scope, req.GetPageToken())
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", err)})
+	}
+
+	arg := db.ListSessionsParams{
+		Username:       username,
+		AfterCreatedAt: afterCreatedAt,
+		AfterID:        afterID,
+		Limit:          req.GetPageSize() + 1,
+	}
+
+	sessions, err := server.store.ListSessions(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list sessions: %s", err)
+	}
+
+	sessions, next := pagination.NextPage(server.pages, scope, sessions, req.GetPageSize(), func(session db.Session) pagination.Cursor {
+		return pagination.NewUUIDCursor(session.CreatedAt, session.ID)
+	})
+
+	resp := &pb.ListSessionsResponse{
+		Sessions:      make([]*pb.Session, 0, len(sessions)),
+		NextPageToken: next,
+	}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, convertSession(session))
+	}
+	return resp, nil
+}
+
+func validateListSessionsRequest(req *pb.ListSessionsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.Username != nil {
+		if err := val.ValidateUsername(req.GetUsername()); err != nil {
+			violations = append(violations, fieldViolation("username", err))
+		}
+	}
+	if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	return
+}
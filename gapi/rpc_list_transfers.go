@@ -0,0 +1,67 @@
+package gapi
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pagination"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListTransfers(ctx context.Context, req *pb.ListTransfersRequest) (*pb.ListTransfersResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateListTransfersRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if _, err = server.accessibleAccount(ctx, payload, authz.AccountRead, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	filter := newListFilter(req, req.MinAmount, req.MaxAmount, req.CounterpartyAccountId)
+	scope := pagination.TransfersScope(req.GetAccountId())
+	after, err := server.pages.Keyset(scope, req.GetPageToken())
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", err)})
+	}
+	arg := filter.TransfersParams(req.GetAccountId(), after, req.GetPageSize()+1)
+
+	transfers, err := server.store.ListTransfers(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list transfers: %s", err)
+	}
+
+	transfers, next := pagination.NextPage(server.pages, scope, transfers, req.GetPageSize(), func(transfer db.Transfer) pagination.Cursor {
+		return pagination.NewCursor(transfer.CreatedAt, transfer.ID)
+	})
+
+	resp := &pb.ListTransfersResponse{
+		Transfers:     make([]*pb.Transfer, 0, len(transfers)),
+		NextPageToken: next,
+	}
+	for _, transfer := range transfers {
+		resp.Transfers = append(resp.Transfers, convertTransfer(transfer))
+	}
+	return resp, nil
+}
+
+func validateListTransfersRequest(req *pb.ListTransfersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	filter := newListFilter(req, req.MinAmount, req.MaxAmount, req.CounterpartyAccountId)
+	violations = append(violations, validateListFilter(filter)...)
+	return
+}
@@ -0,0 +1,65 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LogoutUser blocks the session of the refresh token, the token itself is the proof of ownership.
+// The access token sent along the request, if any, is revoked as well.
+func (server *Server) LogoutUser(ctx context.Context, req *pb.LogoutUserRequest) (*pb.LogoutUserResponse, error) {
+	violations := validateLogoutUserRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.GetRefreshToken())
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token: %s", err)
+	}
+
+	id, err := uuid.Parse(refreshPayload.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to parse session id: %s", err)
+	}
+
+	session, err := server.store.GetSession(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "session not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get session: %s", err)
+	}
+
+	if session.Username != refreshPayload.Username || session.RefreshToken != req.GetRefreshToken() {
+		return nil, status.Errorf(codes.Unauthenticated, "mismatched session token")
+	}
+
+	if !session.IsBlocked {
+		if _, err = server.store.BlockSession(ctx, session.ID); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to block session: %s", err)
+		}
+	}
+
+	// the access token of the request, if any, is denied too
+	if payload, err := server.authorizeUser(ctx); err == nil && payload.Username == session.Username {
+		if err = server.revocations.RevokeToken(ctx, payload); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to revoke access token: %s", err)
+		}
+	}
+
+	return &pb.LogoutUserResponse{}, nil
+}
+
+func validateLogoutUserRequest(req *pb.LogoutUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetRefreshToken() == "" {
+		violations = append(violations, fieldViolation("refresh_token", errors.New("must not be empty")))
+	}
+	return
+}
@@ -0,0 +1,110 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// RenewAccessToken rotates the refresh token, presenting a rotated token revokes every session of its family
+func (server *Server) RenewAccessToken(ctx context.Context, req *pb.RenewAccessTokenRequest) (*pb.RenewAccessTokenResponse, error) {
+	violations := validateRenewAccessTokenRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.GetRefreshToken())
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token: %s", err)
+	}
+
+	id, err := uuid.Parse(refreshPayload.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to parse session id: %s", err)
+	}
+
+	session, err := server.store.GetSession(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "session not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get session: %s", err)
+	}
+
+	if session.IsBlocked {
+		return nil, status.Errorf(codes.Unauthenticated, "the refresh token is blocked")
+	}
+	if session.Username != refreshPayload.Username {
+		return nil, status.Errorf(codes.Unauthenticated, "incorrect session user")
+	}
+	if session.RefreshToken != req.GetRefreshToken() {
+		return nil, status.Errorf(codes.Unauthenticated, "mismatched session token")
+	}
+	if session.ExpiresAt.Before(time.Now()) {
+		return nil, status.Errorf(codes.Unauthenticated, "the refresh token has expired")
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(session.Username,
+		util.Role(refreshPayload.Role),
+		server.config.AccessTokenDuration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create access token")
+	}
+
+	refreshToken, newRefreshPayload, err := server.tokenMaker.CreateToken(session.Username,
+		util.Role(refreshPayload.Role),
+		server.config.RefreshTokenDuration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create refresh token")
+	}
+
+	newID, err := uuid.Parse(newRefreshPayload.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error when converting id : %s", err)
+	}
+
+	mtdt := server.extractMetadata(ctx)
+	newSession, err := server.store.RotateSessionTx(ctx, db.RotateSessionTxParam{
+		SessionID: session.ID,
+		NewSession: db.CreateSessionParams{
+			ID:           newID,
+			Username:     session.Username,
+			RefreshToken: refreshToken,
+			UserAgent:    mtdt.UserAgent,
+			ClientIp:     mtdt.ClientIP,
+			IsBlocked:    false,
+			ExpiresAt:    newRefreshPayload.ExpiredAt,
+		},
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRefreshTokenReused) {
+			return nil, status.Errorf(codes.Unauthenticated, "%s, every session of the login has been revoked", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to rotate session: %s", err)
+	}
+
+	resp := &pb.RenewAccessTokenResponse{
+		SessionId:             newSession.ID.String(),
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  timestamppb.New(accessPayload.ExpiredAt),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(newRefreshPayload.ExpiredAt),
+	}
+	return resp, nil
+}
+
+func validateRenewAccessTokenRequest(req *pb.RenewAccessTokenRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetRefreshToken() == "" {
+		violations = append(violations, fieldViolation("refresh_token", errors.New("must not be empty")))
+	}
+	return
+}
@@ -0,0 +1,80 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/fx"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ReverseTransfer(ctx context.Context, req *pb.ReverseTransferRequest) (*pb.ReverseTransferResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateReverseTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, req.GetTransferId())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "transfer [%d] not found", req.GetTransferId())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get transfer: %s", err)
+	}
+	// the money goes back from the destination account, its owner may refund the transfer
+	if _, err = server.accessibleAccount(ctx, payload, authz.TransferReverse, transfer.ToAccountID); err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.ReverseTransferTx(ctx, db.ReverseTransferTxParam{
+		TransferID: transfer.ID,
+		Amount:     req.GetAmount(),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrTransferNotReversible) || errors.Is(err, db.ErrRefundTooLarge) ||
+			errors.Is(err, fx.ErrAmountTooSmall) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		if errors.Is(err, db.ErrTransferAlreadyReversed) {
+			return nil, status.Errorf(codes.AlreadyExists, "%s", err)
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountFrozen) ||
+			errors.Is(err, db.ErrAccountClosed) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to reverse transfer: %s", err)
+	}
+
+	resp := &pb.ReverseTransferResponse{
+		Transfer:         convertTransfer(result.Transfer),
+		OriginalTransfer: convertTransfer(result.OriginalTransfer),
+		FromAccount:      convertAccount(result.FromAccount),
+		ToAccount:        convertAccount(result.ToAccount),
+		FromEntry:        convertEntry(result.FromEntry),
+		ToEntry:          convertEntry(result.ToEntry),
+	}
+	return resp, nil
+}
+
+func validateReverseTransferRequest(req *pb.ReverseTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetTransferId()); err != nil {
+		violations = append(violations, fieldViolation("transfer_id", err))
+	}
+	if req.Amount != nil {
+		if err := val.ValidateAmount(req.GetAmount()); err != nil {
+			violations = append(violations, fieldViolation("amount", err))
+		}
+	}
+	return
+}
@@ -0,0 +1,55 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/fayca121/simplebank/authz"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateRevokeSessionRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	id := uuid.MustParse(req.GetSessionId())
+	session, err := server.store.GetSession(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "session not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get session: %s", err)
+	}
+
+	if err := authz.Authorize(payload, authz.SessionRevoke, session.Username); err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+	}
+
+	session, err = server.store.BlockSession(ctx, id)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to block session: %s", err)
+	}
+
+	resp := &pb.RevokeSessionResponse{
+		Session: convertSession(session),
+	}
+	return resp, nil
+}
+
+func validateRevokeSessionRequest(req *pb.RevokeSessionRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if _, err := uuid.Parse(req.GetSessionId()); err != nil {
+		violations = append(violations, fieldViolation("session_id", errors.New("must be a valid uuid")))
+	}
+	return
+}
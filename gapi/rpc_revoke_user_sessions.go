@@ -0,0 +1,52 @@
+package gapi
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/authz"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// RevokeUserSessions blocks every active session of the user and denies its access tokens,
+// a banker can revoke the sessions of any user
+func (server *Server) RevokeUserSessions(ctx context.Context, req *pb.RevokeUserSessionsRequest) (*pb.RevokeUserSessionsResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateRevokeUserSessionsRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if err := authz.Authorize(payload, authz.SessionRevoke, req.GetUsername()); err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+	}
+
+	revoked, err := server.store.BlockUserSessions(ctx, req.GetUsername())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to block sessions: %s", err)
+	}
+
+	// the access tokens already handed out by these sessions are denied as well
+	if err = server.revocations.RevokeUserTokens(ctx, req.GetUsername(), time.Now()); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke access tokens: %s", err)
+	}
+
+	resp := &pb.RevokeUserSessionsResponse{
+		RevokedSessions: revoked,
+	}
+	return resp, nil
+}
+
+func validateRevokeUserSessionsRequest(req *pb.RevokeUserSessionsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateUsername(req.GetUsername()); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+	return
+}
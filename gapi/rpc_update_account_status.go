@@ -0,0 +1,65 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) UpdateAccountStatus(ctx context.Context, req *pb.UpdateAccountStatusRequest) (*pb.UpdateAccountStatusResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateUpdateAccountStatusRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.accessibleAccount(ctx, payload, authz.AccountStatus, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.UpdateAccountStatusTx(ctx, db.UpdateAccountStatusTxParam{
+		AccountID: account.ID,
+		Status:    req.GetStatus(),
+		Reason:    req.GetReason(),
+		ChangedBy: payload.Username,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrCashAccount) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		if errors.Is(err, db.ErrInvalidStatusChange) || errors.Is(err, db.ErrAccountNotEmpty) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update account status: %s", err)
+	}
+
+	resp := &pb.UpdateAccountStatusResponse{
+		Account: convertAccount(result.Account),
+		Change:  convertAccountStatusChange(result.Change),
+	}
+	return resp, nil
+}
+
+func validateUpdateAccountStatusRequest(req *pb.UpdateAccountStatusRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	if err := val.ValidateAccountStatus(req.GetStatus()); err != nil {
+		violations = append(violations, fieldViolation("status", err))
+	}
+	if err := val.ValidateStatusReason(req.GetReason()); err != nil {
+		violations = append(violations, fieldViolation("reason", err))
+	}
+	return
+}
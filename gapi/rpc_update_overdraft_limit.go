@@ -0,0 +1,56 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) UpdateOverdraftLimit(ctx context.Context, req *pb.UpdateOverdraftLimitRequest) (*pb.UpdateOverdraftLimitResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateUpdateOverdraftLimitRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.accessibleAccount(ctx, payload, authz.AccountOverdraft, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	account, err = server.store.UpdateAccountOverdraftLimit(ctx, db.UpdateAccountOverdraftLimitParams{
+		OverdraftLimit: req.GetOverdraftLimit(),
+		ID:             account.ID,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrCashAccount) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update overdraft limit: %s", err)
+	}
+
+	resp := &pb.UpdateOverdraftLimitResponse{
+		Account: convertAccount(account),
+	}
+	return resp, nil
+}
+
+func validateUpdateOverdraftLimitRequest(req *pb.UpdateOverdraftLimitRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	if err := val.ValidateOverdraftLimit(req.GetOverdraftLimit()); err != nil {
+		violations = append(violations, fieldViolation("overdraft_limit", err))
+	}
+	return
+}
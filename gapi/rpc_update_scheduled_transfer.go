@@ -0,0 +1,83 @@
+package gapi
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/schedule"
+	"github.com/fayca121/simplebank/val"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+func (server *Server) UpdateScheduledTransfer(ctx context.Context, req *pb.UpdateScheduledTransferRequest) (*pb.UpdateScheduledTransferResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateUpdateScheduledTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	scheduled, err := server.accessibleScheduledTransfer(ctx, payload, authz.TransferCreate, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	arg := db.UpdateScheduledTransferParams{
+		Amount: pgtype.Int8{
+			Int64: req.GetAmount(),
+			Valid: req.Amount != nil,
+		},
+		Schedule: pgtype.Text{
+			String: req.GetSchedule(),
+			Valid:  req.Schedule != nil,
+		},
+		Status: pgtype.Text{
+			String: req.GetStatus(),
+			Valid:  req.Status != nil,
+		},
+		ID: scheduled.ID,
+	}
+	if err = schedule.Reschedule(scheduled, &arg, time.Now()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	scheduled, err = server.store.UpdateScheduledTransfer(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update scheduled transfer: %s", err)
+	}
+
+	resp := &pb.UpdateScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(scheduled),
+	}
+	return resp, nil
+}
+
+func validateUpdateScheduledTransferRequest(req *pb.UpdateScheduledTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+	if req.Amount != nil {
+		if err := val.ValidateAmount(req.GetAmount()); err != nil {
+			violations = append(violations, fieldViolation("amount", err))
+		}
+	}
+	if req.Schedule != nil {
+		if err := val.ValidateSchedule(req.GetSchedule()); err != nil {
+			violations = append(violations, fieldViolation("schedule", err))
+		}
+	}
+	if req.Status != nil {
+		if err := val.ValidateScheduledTransferStatus(req.GetStatus()); err != nil {
+			violations = append(violations, fieldViolation("status", err))
+		}
+	}
+	return
+}
@@ -3,6 +3,7 @@ package gapi
 import (
 	"context"
 	"errors"
+	"github.com/fayca121/simplebank/authz"
 	db "github.com/fayca121/simplebank/db/sqlc"
 	"github.com/fayca121/simplebank/pb"
 	"github.com/fayca121/simplebank/util"
@@ -12,18 +13,17 @@ import (
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"google.golang.org/protobuf/types/known/timestamppb"
 	"time"
 )
 
 func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
 	//2- check authorization access token
-	payload, err := server.authorizeUser(ctx)
+	payload, err := authenticatedUser(ctx)
 	if err != nil {
-		return nil, status.Errorf(codes.PermissionDenied, "unauthorized: %s", err)
+		return nil, err
 	}
-	if payload.Username != req.Username && payload.Role != util.BankerRole.String() {
-		return nil, status.Errorf(codes.PermissionDenied, "you cannot update other user's info")
+	if err := authz.Authorize(payload, authz.UserUpdate, req.GetUsername()); err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "%s", err)
 	}
 	//1- validate request
 	violations := validateUpdateUserRequest(req)
@@ -68,15 +68,17 @@ func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 		return nil, status.Errorf(codes.Internal, "failed to update user: %s", err)
 	}
 
+	// access tokens issued with the old password are no longer accepted
+	if req.Password != nil {
+		err = server.revocations.RevokeUserTokens(ctx, updatedUser.Username, updatedUser.PasswordChangedAt)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to revoke access tokens: %s", err)
+		}
+	}
+
 	//6- create response
 	resp := &pb.UpdateUserResponse{
-		User: &pb.User{
-			Username:          req.Username,
-			FullName:          updatedUser.FullName,
-			Email:             updatedUser.Email,
-			PasswordChangedAt: timestamppb.New(updatedUser.PasswordChangedAt),
-			CreatedAt:         timestamppb.New(updatedUser.CreatedAt),
-		},
+		User: convertUser(updatedUser),
 	}
 
 	return resp, nil
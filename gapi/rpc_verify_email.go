@@ -0,0 +1,46 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// VerifyEmail uses the code of the link sent to the user to verify its email
+func (server *Server) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	violations := validateVerifyEmailRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	result, err := server.store.VerifyEmailTx(ctx, db.VerifyEmailTxParam{
+		EmailID:    req.GetEmailId(),
+		SecretCode: req.GetSecretCode(),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidVerifyEmail) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", db.ErrInvalidVerifyEmail)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to verify email: %s", err)
+	}
+
+	resp := &pb.VerifyEmailResponse{
+		User: convertUser(result.User),
+	}
+	return resp, nil
+}
+
+func validateVerifyEmailRequest(req *pb.VerifyEmailRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetEmailId()); err != nil {
+		violations = append(violations, fieldViolation("email_id", err))
+	}
+	if err := val.ValidateSecretCode(req.GetSecretCode()); err != nil {
+		violations = append(violations, fieldViolation("secret_code", err))
+	}
+	return
+}
@@ -0,0 +1,43 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) VoidTransfer(ctx context.Context, req *pb.VoidTransferRequest) (*pb.VoidTransferResponse, error) {
+	payload, err := authenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := val.ValidateID(req.GetTransferId()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("transfer_id", err)})
+	}
+
+	transfer, err := server.pendingTransfer(ctx, payload, req.GetTransferId())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.VoidTransferTx(ctx, transfer.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrTransferNotPending) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to void transfer: %s", err)
+	}
+
+	resp := &pb.VoidTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		Hold:        convertTransferHold(result.Hold),
+		FromAccount: convertAccount(result.FromAccount),
+	}
+	return resp, nil
+}
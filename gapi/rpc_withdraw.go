@@ -0,0 +1,21 @@
+package gapi
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/authz"
+	"github.com/fayca121/simplebank/pb"
+)
+
+func (server *Server) Withdraw(ctx context.Context, req *pb.WithdrawRequest) (*pb.WithdrawResponse, error) {
+	result, err := server.cashOperation(ctx, req, authz.AccountWithdraw, server.store.WithdrawTx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.WithdrawResponse{
+		Transfer: convertTransfer(result.Transfer),
+		Account:  convertAccount(result.Account),
+		Entry:    convertEntry(result.Entry),
+	}
+	return resp, nil
+}
@@ -0,0 +1,30 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/token"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recentRuns is the number of runs returned along a scheduled transfer
+const recentRuns = 10
+
+// accessibleScheduledTransfer loads the scheduled transfer and checks the permission against the owner of its source account
+func (server *Server) accessibleScheduledTransfer(ctx context.Context, payload *token.Payload, permission authz.Permission, id int64) (db.ScheduledTransfer, error) {
+	scheduled, err := server.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return scheduled, status.Errorf(codes.NotFound, "scheduled transfer [%d] not found", id)
+		}
+		return scheduled, status.Errorf(codes.Internal, "failed to get scheduled transfer: %s", err)
+	}
+	if _, err = server.accessibleAccount(ctx, payload, permission, scheduled.FromAccountID); err != nil {
+		return scheduled, err
+	}
+	return scheduled, nil
+}
@@ -3,6 +3,8 @@ package gapi
 import (
 	"fmt"
 	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/fx"
+	"github.com/fayca121/simplebank/pagination"
 	"github.com/fayca121/simplebank/pb"
 	"github.com/fayca121/simplebank/token"
 	"github.com/fayca121/simplebank/util"
@@ -10,23 +12,66 @@ import (
 
 type Server struct {
 	pb.UnimplementedSimpleBankServer
-	config     util.Config
-	store      db.Store
-	tokenMaker token.Maker
+	config      util.Config
+	store       db.Store
+	tokenMaker  token.Maker
+	rates       fx.RateProvider
+	revocations token.RevocationStore
+	pages       *pagination.Signer
 }
 
 func NewServer(config util.Config, store db.Store) (*Server, error) {
-	tokenMaker, err := token.NewPasetoMaker() // or .NewJWTMaker(config.TokenSymmetricKey)
+	tokenMaker, err := newTokenMaker(config)
 
 	if err != nil {
-		return nil, fmt.Errorf("cannot create token maker")
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	rates, err := fx.NewRateProvider(config.ExchangeRatesFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create rate provider: %w", err)
+	}
+
+	revocations, err := db.NewRevocationStore(config.TokenRevocationStore, store)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token revocation store: %w", err)
+	}
+	pages, err := pagination.NewSigner(config.PageTokenKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create page token signer: %w", err)
 	}
 
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
+		config:      config,
+		store:       store,
+		tokenMaker:  tokenMaker,
+		rates:       rates,
+		revocations: revocations,
+		pages:       pages,
 	}
 
 	return server, nil
 }
+
+// newTokenMaker creates the maker of TOKEN_ALGORITHM: v4.local (the default) encrypts the tokens
+// with the PASETO keys, v4.public, EdDSA and RS256 sign them with the private key files
+func newTokenMaker(config util.Config) (token.Maker, error) {
+	switch config.TokenAlgorithm {
+	case "", "v4.local":
+		keyring, err := token.LoadKeyring(config.PasetoKeys, config.PasetoCurrentKeyID, config.TokenSymmetricKey)
+		if err != nil {
+			return nil, err
+		}
+		return token.NewPasetoMaker(keyring)
+	case "v4.public", "EdDSA", "RS256":
+		keyring, err := token.LoadSigningKeyring(config.TokenPrivateKeyFiles, config.TokenCurrentKeyID)
+		if err != nil {
+			return nil, err
+		}
+		if config.TokenAlgorithm == "v4.public" {
+			return token.NewPasetoPublicMaker(keyring)
+		}
+		return token.NewJWTPublicMaker(keyring, config.TokenAlgorithm)
+	}
+	return nil, fmt.Errorf("unsupported token algorithm %q", config.TokenAlgorithm)
+}
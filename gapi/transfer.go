@@ -0,0 +1,27 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/fayca121/simplebank/authz"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/token"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// pendingTransfer loads the transfer, it may be captured or voided by the owner of its destination account
+func (server *Server) pendingTransfer(ctx context.Context, payload *token.Payload, id int64) (db.Transfer, error) {
+	transfer, err := server.store.GetTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return transfer, status.Errorf(codes.NotFound, "transfer [%d] not found", id)
+		}
+		return transfer, status.Errorf(codes.Internal, "failed to get transfer: %s", err)
+	}
+	if _, err = server.accessibleAccount(ctx, payload, authz.TransferCapture, transfer.ToAccountID); err != nil {
+		return transfer, err
+	}
+	return transfer, nil
+}
@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileSender writes each message to an .eml file of its directory, which mail clients open as is
+type FileSender struct {
+	dir  string
+	from string
+}
+
+func NewFileSender(dir string, from string) *FileSender {
+	return &FileSender{dir: dir, from: from}
+}
+
+func (sender *FileSender) Send(ctx context.Context, msg Message) error {
+	if msg.From == "" {
+		msg.From = sender.from
+	}
+	if _, err := msg.Recipients(); err != nil {
+		return err
+	}
+	now := time.Now()
+	data, err := msg.Bytes(now)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(sender.dir, 0o755); err != nil {
+		return fmt.Errorf("cannot create mail directory: %w", err)
+	}
+	// the names sort in the order the messages were sent
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+	path := filepath.Join(sender.dir, name)
+
+	// the file is renamed once complete, so that a reader never sees a partial message
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("cannot write message: %w", err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("cannot write message: %w", err)
+	}
+	return nil
+}
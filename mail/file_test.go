@@ -0,0 +1,33 @@
+package mail
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	sender := NewFileSender(dir, "Simple Bank <no-reply@simplebank.local>")
+
+	msg := Message{To: []string{"alice@example.com"}, Subject: "Hello", Text: "Hello Alice"}
+	require.NoError(t, sender.Send(context.Background(), msg))
+	require.NoError(t, sender.Send(context.Background(), msg))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	file, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer file.Close()
+	parsed, err := mail.ReadMessage(file)
+	require.NoError(t, err)
+	require.Equal(t, "\"Simple Bank\" <no-reply@simplebank.local>", parsed.Header.Get("From"))
+
+	// a message without recipient isn't written
+	require.ErrorIs(t, sender.Send(context.Background(), Message{Text: "Hello"}), ErrNoRecipient)
+}
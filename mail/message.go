@@ -0,0 +1,229 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+var ErrNoRecipient = errors.New("message has no recipient")
+
+// Attachment is a file sent along a message
+type Attachment struct {
+	Filename string
+	// ContentType defaults to the type of the extension of Filename
+	ContentType string
+	Data        []byte
+}
+
+// Message is an email. The Text and HTML bodies are alternatives of each other, at least one is required.
+type Message struct {
+	// From defaults to the address of the sender
+	From        string
+	To          []string
+	Cc          []string
+	Bcc         []string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Recipients returns the addresses the message is delivered to, Bcc included
+func (msg Message) Recipients() ([]string, error) {
+	var recipients []string
+	for _, list := range [][]string{msg.To, msg.Cc, msg.Bcc} {
+		for _, value := range list {
+			address, err := mail.ParseAddress(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid recipient %q: %w", value, err)
+			}
+			recipients = append(recipients, address.Address)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil, ErrNoRecipient
+	}
+	return recipients, nil
+}
+
+// Bytes encodes the message in the MIME format, without its Bcc header: both bodies make a
+// multipart/alternative part, which is wrapped in a multipart/mixed one with the attachments
+func (msg Message) Bytes(now time.Time) ([]byte, error) {
+	if msg.Text == "" && msg.HTML == "" {
+		return nil, errors.New("message has no body")
+	}
+	from, err := mail.ParseAddress(msg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender %q: %w", msg.From, err)
+	}
+
+	var buf bytes.Buffer
+	header := textproto.MIMEHeader{}
+	header.Set("From", from.String())
+	if len(msg.To) > 0 {
+		header.Set("To", strings.Join(msg.To, ", "))
+	}
+	if len(msg.Cc) > 0 {
+		header.Set("Cc", strings.Join(msg.Cc, ", "))
+	}
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header.Set("Date", now.Format(time.RFC1123Z))
+	header.Set("Message-Id", messageID(from.Address))
+	header.Set("Mime-Version", "1.0")
+
+	bodyHeader, body, err := bodyPart(msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(msg.Attachments) == 0 {
+		for key, values := range bodyHeader {
+			header[key] = values
+		}
+		if err = writeHeader(&buf, header); err != nil {
+			return nil, err
+		}
+		buf.Write(body)
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	header.Set("Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	if err = writeHeader(&buf, header); err != nil {
+		return nil, err
+	}
+	part, err := mixed.CreatePart(bodyHeader)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = part.Write(body); err != nil {
+		return nil, err
+	}
+	for _, attachment := range msg.Attachments {
+		if err = writeAttachment(mixed, attachment); err != nil {
+			return nil, err
+		}
+	}
+	if err = mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// bodyPart returns the header and content of the bodies: a single text part,
+// or a multipart/alternative part holding the text and HTML ones
+func bodyPart(msg Message) (textproto.MIMEHeader, []byte, error) {
+	var buf bytes.Buffer
+	if msg.Text == "" || msg.HTML == "" {
+		contentType, content := "text/plain; charset=utf-8", msg.Text
+		if msg.Text == "" {
+			contentType, content = "text/html; charset=utf-8", msg.HTML
+		}
+		header := textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		}
+		err := writeQuotedPrintable(&buf, content)
+		return header, buf.Bytes(), err
+	}
+
+	alternative := multipart.NewWriter(&buf)
+	header := textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + alternative.Boundary()},
+	}
+	for _, body := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		part, err := alternative.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {body.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if err = writeQuotedPrintable(part, body.content); err != nil {
+			return nil, nil, err
+		}
+	}
+	err := alternative.Close()
+	return header, buf.Bytes(), err
+}
+
+func writeAttachment(mixed *multipart.Writer, attachment Attachment) error {
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(extension(attachment.Filename))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	part, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+	})
+	if err != nil {
+		return err
+	}
+
+	// the lines of a base64 body are at most 76 characters long
+	encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+	for len(encoded) > 76 {
+		if _, err = part.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err = part.Write([]byte(encoded + "\r\n"))
+	return err
+}
+
+func writeHeader(w io.Writer, header textproto.MIMEHeader) error {
+	var buf bytes.Buffer
+	// a stable order keeps the messages comparable
+	for _, key := range []string{"From", "To", "Cc", "Subject", "Date", "Message-Id", "Mime-Version", "Content-Type", "Content-Transfer-Encoding"} {
+		for _, value := range header.Values(key) {
+			fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+		}
+	}
+	buf.WriteString("\r\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func extension(filename string) string {
+	if i := strings.LastIndexByte(filename, '.'); i >= 0 {
+		return filename[i:]
+	}
+	return ""
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if i := strings.LastIndexByte(from, '@'); i >= 0 {
+		domain = from[i+1:]
+	}
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return "<" + hex.EncodeToString(id) + "@" + domain + ">"
+}
@@ -0,0 +1,117 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"github.com/stretchr/testify/require"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readPart returns the decoded content of a part, multipart decodes the quoted-printable ones itself
+func readPart(t *testing.T, part *multipart.Part) string {
+	data, err := io.ReadAll(part)
+	require.NoError(t, err)
+	if part.Header.Get("Content-Transfer-Encoding") == "base64" {
+		data, err = base64.StdEncoding.DecodeString(strings.ReplaceAll(string(data), "\r\n", ""))
+		require.NoError(t, err)
+	}
+	return string(data)
+}
+
+func TestMessageBytes(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	attachment := bytes.Repeat([]byte("%PDF-1.7 statement "), 20)
+	msg := Message{
+		From:    "Simple Bank <no-reply@simplebank.local>",
+		To:      []string{"alice@example.com"},
+		Bcc:     []string{"audit@simplebank.local"},
+		Subject: "Relevé de compte",
+		Text:    "Your statement is attached.",
+		HTML:    "<p>Your statement is attached.</p>",
+		Attachments: []Attachment{
+			{Filename: "statement.pdf", Data: attachment},
+		},
+	}
+
+	data, err := msg.Bytes(now)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "audit@simplebank.local")
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", parsed.Header.Get("To"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	require.Equal(t, msg.Subject, subject)
+	date, err := parsed.Header.Date()
+	require.NoError(t, err)
+	require.True(t, now.Equal(date))
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+	mixed := multipart.NewReader(parsed.Body, params["boundary"])
+
+	body, err := mixed.NextPart()
+	require.NoError(t, err)
+	mediaType, params, err = mime.ParseMediaType(body.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+	alternative := multipart.NewReader(body, params["boundary"])
+	for _, want := range []string{msg.Text, msg.HTML} {
+		part, err := alternative.NextPart()
+		require.NoError(t, err)
+		require.Equal(t, want, readPart(t, part))
+	}
+
+	file, err := mixed.NextPart()
+	require.NoError(t, err)
+	require.Equal(t, "statement.pdf", file.FileName())
+	require.Equal(t, "application/pdf", file.Header.Get("Content-Type"))
+	require.Equal(t, string(attachment), readPart(t, file))
+
+	_, err = mixed.NextPart()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestMessageBytesSingleBody(t *testing.T) {
+	msg := Message{
+		From:    "no-reply@simplebank.local",
+		To:      []string{"alice@example.com"},
+		Subject: "Hello",
+		HTML:    "<p>Hello</p>",
+	}
+	data, err := msg.Bytes(time.Now())
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, "text/html; charset=utf-8", parsed.Header.Get("Content-Type"))
+
+	msg.HTML = ""
+	_, err = msg.Bytes(time.Now())
+	require.Error(t, err)
+}
+
+func TestMessageRecipients(t *testing.T) {
+	msg := Message{
+		To:  []string{"Alice <alice@example.com>"},
+		Cc:  []string{"bob@example.com"},
+		Bcc: []string{"audit@simplebank.local"},
+	}
+	recipients, err := msg.Recipients()
+	require.NoError(t, err)
+	require.Equal(t, []string{"alice@example.com", "bob@example.com", "audit@simplebank.local"}, recipients)
+
+	_, err = Message{}.Recipients()
+	require.ErrorIs(t, err, ErrNoRecipient)
+
+	_, err = Message{To: []string{"not an address"}}.Recipients()
+	require.Error(t, err)
+}
@@ -0,0 +1,30 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"github.com/fayca121/simplebank/util"
+)
+
+// Sender delivers the outbound emails
+type Sender interface {
+	// Send returns once the message is accepted for delivery
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewSender returns the sender named by MAIL_SENDER: "file" (the default) writes the messages to
+// MAIL_DIRECTORY for the development environment and the tests, "smtp" sends them through SMTP_HOST
+func NewSender(config util.Config) (Sender, error) {
+	switch config.MailSender {
+	case "", "file":
+		return NewFileSender(config.MailDirectory, config.MailFrom), nil
+	case "smtp":
+		return NewSMTPSender(SMTPConfig{
+			Host:     config.SMTPHost,
+			Port:     config.SMTPPort,
+			Username: config.SMTPUsername,
+			Password: config.SMTPPassword,
+		}, config.MailFrom), nil
+	}
+	return nil, fmt.Errorf("unknown mail sender %q", config.MailSender)
+}
@@ -0,0 +1,121 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// smtpTimeout bounds a delivery when the context has no deadline
+const smtpTimeout = 30 * time.Second
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// SMTPSender delivers the messages to an SMTP server, one connection per message. The connection is
+// upgraded with STARTTLS when the server offers it, and authenticated when a username is configured.
+type SMTPSender struct {
+	config SMTPConfig
+	from   string
+}
+
+func NewSMTPSender(config SMTPConfig, from string) *SMTPSender {
+	return &SMTPSender{config: config, from: from}
+}
+
+func (sender *SMTPSender) Send(ctx context.Context, msg Message) error {
+	if msg.From == "" {
+		msg.From = sender.from
+	}
+	recipients, err := msg.Recipients()
+	if err != nil {
+		return err
+	}
+	data, err := msg.Bytes(time.Now())
+	if err != nil {
+		return err
+	}
+
+	address := net.JoinHostPort(sender.config.Host, strconv.Itoa(sender.config.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("cannot connect to smtp server: %w", err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	if err = conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, sender.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("cannot connect to smtp server: %w", err)
+	}
+	defer client.Close()
+
+	if err = sender.send(client, msg, recipients, data); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (sender *SMTPSender) send(client *smtp.Client, msg Message, recipients []string, data []byte) error {
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: sender.config.Host}); err != nil {
+			return fmt.Errorf("cannot start tls: %w", err)
+		}
+	}
+	if sender.config.Username != "" {
+		// PlainAuth refuses to send the password over a connection that isn't encrypted, unless to localhost
+		auth := smtp.PlainAuth("", sender.config.Username, sender.config.Password, sender.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("cannot authenticate: %w", err)
+		}
+	}
+
+	from, err := envelopeFrom(msg.From)
+	if err != nil {
+		return err
+	}
+	if err = client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		if err = client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("recipient %s rejected: %w", recipient, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// envelopeFrom returns the bare address of the sender, the MAIL command takes no display name
+func envelopeFrom(from string) (string, error) {
+	address, err := mail.ParseAddress(from)
+	if err != nil {
+		return "", fmt.Errorf("invalid sender %q: %w", from, err)
+	}
+	return address.Address, nil
+}
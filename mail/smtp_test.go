@@ -0,0 +1,111 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"github.com/stretchr/testify/require"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// smtpSession is what a fakeSMTPServer received from a client
+type smtpSession struct {
+	from       string
+	recipients []string
+	data       string
+}
+
+// fakeSMTPServer accepts one client and answers its commands without TLS nor authentication
+func fakeSMTPServer(t *testing.T) (SMTPConfig, <-chan smtpSession) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	sessions := make(chan smtpSession, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		text := textproto.NewConn(conn)
+		var session smtpSession
+
+		_ = text.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.Fields(line)[0])
+			switch command {
+			case "EHLO", "HELO":
+				_ = text.PrintfLine("250 localhost")
+			case "MAIL":
+				session.from = strings.TrimSuffix(strings.TrimPrefix(line, "MAIL FROM:<"), ">")
+				_ = text.PrintfLine("250 OK")
+			case "RCPT":
+				session.recipients = append(session.recipients, strings.TrimSuffix(strings.TrimPrefix(line, "RCPT TO:<"), ">"))
+				_ = text.PrintfLine("250 OK")
+			case "DATA":
+				_ = text.PrintfLine("354 end with <CRLF>.<CRLF>")
+				data, err := text.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				session.data = string(data)
+				_ = text.PrintfLine("250 OK")
+			case "QUIT":
+				_ = text.PrintfLine("221 bye")
+				sessions <- session
+				return
+			default:
+				_ = text.PrintfLine("502 unknown command")
+			}
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return SMTPConfig{Host: host, Port: portNumber}, sessions
+}
+
+func TestSMTPSender(t *testing.T) {
+	config, sessions := fakeSMTPServer(t)
+	sender := NewSMTPSender(config, "Simple Bank <no-reply@simplebank.local>")
+
+	err := sender.Send(context.Background(), Message{
+		To:      []string{"Alice <alice@example.com>"},
+		Bcc:     []string{"audit@simplebank.local"},
+		Subject: "Hello",
+		Text:    "Hello Alice\n.\nA line with a single dot",
+	})
+	require.NoError(t, err)
+
+	session := <-sessions
+	require.Equal(t, "no-reply@simplebank.local", session.from)
+	require.Equal(t, []string{"alice@example.com", "audit@simplebank.local"}, session.recipients)
+	require.Contains(t, session.data, "Subject: Hello\n")
+	require.NotContains(t, session.data, "audit@simplebank.local")
+
+	reader := bufio.NewReader(strings.NewReader(session.data))
+	header, err := textproto.NewReader(reader).ReadMIMEHeader()
+	require.NoError(t, err)
+	require.Equal(t, "Alice <alice@example.com>", header.Get("To"))
+}
+
+func TestSMTPSenderUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	sender := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: port}, "no-reply@simplebank.local")
+	err = sender.Send(context.Background(), Message{To: []string{"alice@example.com"}, Text: "Hello"})
+	require.ErrorContains(t, err, "cannot connect to smtp server")
+}
@@ -0,0 +1,77 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Template renders the subject and bodies of a message. The templates of the message name are
+// templates/<name>.txt, which defines the subject template and renders the text body, and the
+// optional templates/<name>.html, rendering the HTML body.
+type Template struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// Templates of the messages
+const (
+	TemplateVerifyEmail = "verify_email"
+)
+
+// LoadTemplate parses the embedded templates of the message name
+func LoadTemplate(name string) (*Template, error) {
+	return ParseTemplate(templateFS, "templates/"+name)
+}
+
+// ParseTemplate parses the templates <path>.txt and <path>.html of fsys
+func ParseTemplate(fsys fs.FS, path string) (*Template, error) {
+	text, err := texttemplate.ParseFS(fsys, path+".txt")
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse template: %w", err)
+	}
+	if text.Lookup("subject") == nil {
+		return nil, fmt.Errorf("template %s.txt doesn't define the subject", path)
+	}
+
+	tmpl := &Template{text: text}
+	if _, err = fs.Stat(fsys, path+".html"); err == nil {
+		if tmpl.html, err = htmltemplate.ParseFS(fsys, path+".html"); err != nil {
+			return nil, fmt.Errorf("cannot parse template: %w", err)
+		}
+	}
+	return tmpl, nil
+}
+
+// Message renders the templates with data into a message, whose recipients are to be set
+func (tmpl *Template) Message(data any) (Message, error) {
+	var msg Message
+	var buf bytes.Buffer
+
+	if err := tmpl.text.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return msg, fmt.Errorf("cannot render subject: %w", err)
+	}
+	msg.Subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := tmpl.text.Execute(&buf, data); err != nil {
+		return msg, fmt.Errorf("cannot render text body: %w", err)
+	}
+	msg.Text = strings.TrimLeft(buf.String(), "\n")
+
+	if tmpl.html != nil {
+		buf.Reset()
+		if err := tmpl.html.Execute(&buf, data); err != nil {
+			return msg, fmt.Errorf("cannot render html body: %w", err)
+		}
+		msg.HTML = buf.String()
+	}
+	return msg, nil
+}
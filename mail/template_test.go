@@ -0,0 +1,43 @@
+package mail
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadTemplate(t *testing.T) {
+	tmpl, err := LoadTemplate(TemplateVerifyEmail)
+	require.NoError(t, err)
+
+	msg, err := tmpl.Message(map[string]string{
+		"FullName": "Alice <Admin>",
+		"Link":     "http://localhost:8080/v1/verify_email?email_id=1&secret_code=abc",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Verify your email address", msg.Subject)
+	require.Contains(t, msg.Text, "Hello Alice <Admin>,")
+	require.Contains(t, msg.Text, "email_id=1&secret_code=abc")
+	// the HTML body escapes the data
+	require.Contains(t, msg.HTML, "Hello Alice &lt;Admin&gt;,")
+	require.Contains(t, msg.HTML, `href="http://localhost:8080/v1/verify_email?email_id=1&amp;secret_code=abc"`)
+}
+
+func TestParseTemplate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"text_only.txt":  {Data: []byte(`{{define "subject"}}Hi {{.}}{{end}}Hello {{.}}`)},
+		"no_subject.txt": {Data: []byte(`Hello {{.}}`)},
+	}
+
+	tmpl, err := ParseTemplate(fsys, "text_only")
+	require.NoError(t, err)
+	msg, err := tmpl.Message("Bob")
+	require.NoError(t, err)
+	require.Equal(t, Message{Subject: "Hi Bob", Text: "Hello Bob"}, msg)
+
+	_, err = ParseTemplate(fsys, "no_subject")
+	require.ErrorContains(t, err, "subject")
+
+	_, err = ParseTemplate(fsys, "missing")
+	require.Error(t, err)
+}
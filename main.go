@@ -3,12 +3,20 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
+	"fmt"
 	"github.com/fayca121/simplebank/api"
+	"github.com/fayca121/simplebank/authz"
 	db "github.com/fayca121/simplebank/db/sqlc"
 	_ "github.com/fayca121/simplebank/doc/statik"
 	"github.com/fayca121/simplebank/gapi"
+	"github.com/fayca121/simplebank/mail"
+	"github.com/fayca121/simplebank/outbox"
 	"github.com/fayca121/simplebank/pb"
+	"github.com/fayca121/simplebank/reconcile"
+	"github.com/fayca121/simplebank/schedule"
 	"github.com/fayca121/simplebank/util"
+	"github.com/fayca121/simplebank/worker"
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
@@ -18,11 +26,13 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/protobuf/encoding/protojson"
 	"net"
 	"net/http"
 	"os"
+	"time"
 )
 
 func main() {
@@ -45,11 +55,199 @@ func main() {
 	runDBMigration(config.MigrationUrl, config.DBSource)
 
 	store := db.NewStore(connPool)
+	loadCurrencies(store)
+	loadRoles(store)
+
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		runReconciliation(store, os.Args[2:])
+		return
+	}
+
+	go runBackgroundJob("reconciliation", config.ReconciliationInterval, config, store, reconciliationJob)
+	go runBackgroundJob("scheduled transfers", config.SchedulerInterval, config, store, scheduledTransfersJob)
+	go runBackgroundJob("hold expiry", config.HoldExpiryInterval, config, store, holdExpiryJob)
+	go runBackgroundJob("outbox relay", config.OutboxRelayInterval, config, store, outboxRelayJob)
+	go runBackgroundJob("task worker", config.TaskWorkerInterval, config, store, taskWorkerJob)
 	//runGinServer(config, store)
 	go runGatewayServer(config, store)
 	runGrpcServer(config, store)
 }
 
+// loadCurrencies fills the registry used by the currency validators from the currencies table
+func loadCurrencies(store db.Store) {
+	rows, err := store.ListCurrencies(context.Background())
+	if err != nil {
+		log.Fatal().Msgf("cannot load currencies: %s", err)
+	}
+	currencies := make([]util.Currency, 0, len(rows))
+	for _, row := range rows {
+		currencies = append(currencies, util.Currency{
+			Code:       row.Code,
+			MinorUnits: row.MinorUnits,
+			Enabled:    row.Enabled,
+		})
+	}
+	util.LoadCurrencies(currencies)
+	log.Info().Msgf("%d currencies loaded", len(currencies))
+}
+
+// loadRoles fills the registry used to authorize the requests from the roles table
+func loadRoles(store db.Store) {
+	rows, err := store.ListRolePermissions(context.Background())
+	if err != nil {
+		log.Fatal().Msgf("cannot load roles: %s", err)
+	}
+	grants := make(map[util.Role][]authz.Permission)
+	for _, row := range rows {
+		role := util.Role(row.Role)
+		if !row.Permission.Valid {
+			// a role without any permission
+			grants[role] = nil
+			continue
+		}
+		grants[role] = append(grants[role], authz.Permission(row.Permission.String))
+	}
+	authz.LoadRoles(grants)
+	log.Info().Msgf("%d roles loaded", len(grants))
+}
+
+// runReconciliation is the reconcile command: it scans the ledger once, prints its findings
+// and exits with status 1 when the ledger is inconsistent
+func runReconciliation(store db.Store, args []string) {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	save := flags.Bool("save", false, "write the findings to the reconciliation_reports table")
+	_ = flags.Parse(args)
+
+	report, err := reconcile.Run(context.Background(), store)
+	if err != nil {
+		log.Fatal().Msgf("cannot reconcile the ledger: %s", err)
+	}
+	if err = reconcile.Write(os.Stdout, report); err != nil {
+		log.Fatal().Msgf("cannot print the reconciliation report: %s", err)
+	}
+	if *save {
+		saved, err := reconcile.Save(context.Background(), store, report)
+		if err != nil {
+			log.Fatal().Msgf("cannot save the reconciliation report: %s", err)
+		}
+		log.Info().Msgf("reconciliation report %d saved", saved.ID)
+	}
+	if !report.Clean() {
+		os.Exit(1)
+	}
+}
+
+// backgroundJob is run by runBackgroundJob on each tick
+type backgroundJob func(now time.Time) error
+
+// runBackgroundJob creates a job with newJob and runs it every interval, a job whose interval is not set is not
+// created. The errors of a run are logged, the job runs again on the next tick.
+func runBackgroundJob(name string, interval time.Duration, config util.Config, store db.Store,
+	newJob func(util.Config, db.Store) (backgroundJob, error)) {
+	if interval <= 0 {
+		return
+	}
+	job, err := newJob(config, store)
+	if err != nil {
+		log.Fatal().Msgf("cannot create %s job: %s", name, err)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		if err := job(now); err != nil {
+			log.Error().Msgf("%s job: %s", name, err)
+		}
+	}
+}
+
+// reconciliationJob scans the ledger and saves the reports
+func reconciliationJob(config util.Config, store db.Store) (backgroundJob, error) {
+	return func(now time.Time) error {
+		report, err := reconcile.Run(context.Background(), store)
+		if err != nil {
+			return fmt.Errorf("cannot reconcile the ledger: %w", err)
+		}
+		saved, err := reconcile.Save(context.Background(), store, report)
+		if err != nil {
+			return fmt.Errorf("cannot save the reconciliation report: %w", err)
+		}
+		if !report.Clean() {
+			log.Warn().Msgf("ledger inconsistent: %d drifted accounts, %d orphaned entries, %d orphaned transfers, see report %d",
+				saved.DriftedAccounts, saved.OrphanedEntries, saved.OrphanedTransfers, saved.ID)
+		}
+		return nil
+	}, nil
+}
+
+// scheduledTransfersJob executes the due scheduled transfers
+func scheduledTransfersJob(config util.Config, store db.Store) (backgroundJob, error) {
+	return func(now time.Time) error {
+		runs, err := schedule.RunDue(context.Background(), store, now)
+		for _, run := range runs {
+			if run.Error.Valid {
+				log.Warn().Msgf("scheduled transfer %d failed, attempt %d: %s", run.ScheduledTransferID, run.Attempt, run.Error.String)
+			}
+		}
+		return err
+	}, nil
+}
+
+// holdExpiryBatch bounds the number of holds released by each run of the hold expiry job
+const holdExpiryBatch = 100
+
+// holdExpiryJob releases the holds of the pending transfers not captured in time
+func holdExpiryJob(config util.Config, store db.Store) (backgroundJob, error) {
+	return func(now time.Time) error {
+		holds, err := store.ListExpiredTransferHolds(context.Background(), db.ListExpiredTransferHoldsParams{
+			ExpiredAt: now,
+			Limit:     holdExpiryBatch,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot list expired holds: %w", err)
+		}
+		var errs []error
+		for _, hold := range holds {
+			// a transfer captured or voided since it was listed is no longer pending
+			_, err := store.ExpireTransferTx(context.Background(), hold.TransferID)
+			if err != nil && !errors.Is(err, db.ErrTransferNotPending) {
+				errs = append(errs, fmt.Errorf("cannot expire transfer %d: %w", hold.TransferID, err))
+			}
+		}
+		return errors.Join(errs...)
+	}, nil
+}
+
+// outboxRelayJob publishes the events written to the outbox by the transactions
+func outboxRelayJob(config util.Config, store db.Store) (backgroundJob, error) {
+	publisher, err := outbox.NewPublisher(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create outbox publisher: %w", err)
+	}
+	return func(now time.Time) error {
+		_, err := outbox.Relay(context.Background(), store, publisher, now)
+		return err
+	}, nil
+}
+
+// taskWorkerJob runs the tasks enqueued by the store transactions
+func taskWorkerJob(config util.Config, store db.Store) (backgroundJob, error) {
+	sender, err := mail.NewSender(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create mail sender: %w", err)
+	}
+	sendVerifyEmail, err := worker.SendVerifyEmail(store, sender, config.VerifyEmailURL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create task handler: %w", err)
+	}
+	processor := worker.NewProcessor(store)
+	processor.Handle(db.TaskSendVerifyEmail, sendVerifyEmail)
+
+	return func(now time.Time) error {
+		_, err := processor.RunDue(context.Background(), now)
+		return err
+	}, nil
+}
+
 func runGinServer(config util.Config, store db.Store) {
 	server, err := api.NewServer(config, store)
 	if err != nil {
@@ -68,8 +266,9 @@ func runGrpcServer(config util.Config, store db.Store) {
 		log.Fatal().Msgf("cannot create server: %s", err)
 	}
 
-	grpcLogger := grpc.UnaryInterceptor(gapi.GrpcLogger)
-	grpcServer := grpc.NewServer(grpcLogger)
+	unaryInterceptors := grpc.ChainUnaryInterceptor(gapi.GrpcLogger, server.UnaryAuthInterceptor)
+	streamInterceptors := grpc.ChainStreamInterceptor(server.StreamAuthInterceptor)
+	grpcServer := grpc.NewServer(unaryInterceptors, streamInterceptors)
 	pb.RegisterSimpleBankServer(grpcServer, server)
 	reflection.Register(grpcServer) //optional
 
@@ -103,12 +302,14 @@ func runGatewayServer(config util.Config, store db.Store) {
 		},
 	})
 
-	grpcMux := runtime.NewServeMux(jsonOption)
+	grpcMux := runtime.NewServeMux(jsonOption, runtime.WithIncomingHeaderMatcher(gapi.IncomingHeaderMatcher))
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	err = pb.RegisterSimpleBankHandlerServer(ctx, grpcMux, server)
+	// the gateway calls the gRPC server so that its requests go through the interceptors
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	err = pb.RegisterSimpleBankHandlerFromEndpoint(ctx, grpcMux, config.GRPCServerAddress, dialOptions)
 	if err != nil {
-		log.Fatal().Msgf("cannot register handler server %s", err)
+		log.Fatal().Msgf("cannot register handler %s", err)
 	}
 	mux := http.NewServeMux()
 	mux.Handle("/", grpcMux)
@@ -122,6 +323,7 @@ func runGatewayServer(config util.Config, store db.Store) {
 	swaggerHandler := http.StripPrefix("/swagger/", http.FileServer(statikFs))
 
 	mux.Handle("/swagger/", swaggerHandler)
+	mux.Handle(gapi.JWKSPath, server.JWKSHandler())
 
 	listener, err := net.Listen("tcp", config.HTTPServerAddress)
 
@@ -0,0 +1,66 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+)
+
+// ChannelPublisher hands the events to the subscribers of the process through channels, it wires the
+// relay to in-process consumers and tests. Publishing while no one subscribes fails with ErrNoSubscriber,
+// so that the relay keeps the event rather than marking it published.
+type ChannelPublisher struct {
+	mu          sync.RWMutex
+	subscribers []chan Event
+	closed      bool
+}
+
+func NewChannelPublisher() *ChannelPublisher {
+	return &ChannelPublisher{}
+}
+
+// Subscribe returns a channel receiving every event published from now on, it is closed with the publisher.
+// Publish waits for the subscribers whose buffer is full, a subscriber must keep reading.
+func (publisher *ChannelPublisher) Subscribe(buffer int) <-chan Event {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	events := make(chan Event, buffer)
+	if publisher.closed {
+		close(events)
+		return events
+	}
+	publisher.subscribers = append(publisher.subscribers, events)
+	return events
+}
+
+func (publisher *ChannelPublisher) Publish(ctx context.Context, event Event) error {
+	publisher.mu.RLock()
+	defer publisher.mu.RUnlock()
+	if publisher.closed {
+		return ErrPublisherClosed
+	}
+	if len(publisher.subscribers) == 0 {
+		return ErrNoSubscriber
+	}
+	for _, events := range publisher.subscribers {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (publisher *ChannelPublisher) Close() error {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	if publisher.closed {
+		return nil
+	}
+	publisher.closed = true
+	for _, events := range publisher.subscribers {
+		close(events)
+	}
+	publisher.subscribers = nil
+	return nil
+}
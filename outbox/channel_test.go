@@ -0,0 +1,47 @@
+package outbox
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestChannelPublisher(t *testing.T) {
+	publisher := NewChannelPublisher()
+	event := Event{ID: 1, Type: "transfer.posted"}
+
+	// no one listens yet, the event is kept by the relay
+	require.ErrorIs(t, publisher.Publish(context.Background(), event), ErrNoSubscriber)
+
+	first := publisher.Subscribe(1)
+	second := publisher.Subscribe(1)
+	require.NoError(t, publisher.Publish(context.Background(), event))
+	require.Equal(t, event, <-first)
+	require.Equal(t, event, <-second)
+
+	// a full subscriber blocks the publication until the context is done
+	require.NoError(t, publisher.Publish(context.Background(), event))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, publisher.Publish(ctx, event), context.DeadlineExceeded)
+
+	require.NoError(t, publisher.Close())
+	<-first
+	_, open := <-first
+	require.False(t, open)
+	require.ErrorIs(t, publisher.Publish(context.Background(), event), ErrPublisherClosed)
+}
+
+func TestNewPublisher(t *testing.T) {
+	_, err := NewPublisher(util.Config{})
+	require.Error(t, err)
+	_, err = NewPublisher(util.Config{OutboxPublisher: "memory"})
+	require.Error(t, err)
+
+	publisher, err := NewPublisher(util.Config{OutboxPublisher: "redis", RedisAddress: "localhost:6379"})
+	require.NoError(t, err)
+	require.IsType(t, &RedisPublisher{}, publisher)
+	require.NoError(t, publisher.Close())
+}
@@ -0,0 +1,59 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/util"
+	"time"
+)
+
+var (
+	ErrPublisherClosed = errors.New("publisher is closed")
+	// ErrNoSubscriber is returned by ChannelPublisher when no one would receive the event
+	ErrNoSubscriber = errors.New("no subscriber")
+)
+
+// Event is an outbox event as handed to the publishers. The relay publishes every event at least once,
+// the consumers tell the duplicates apart by ID.
+type Event struct {
+	ID            int64           `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+func NewEvent(event db.OutboxEvent) Event {
+	return Event{
+		ID:            event.ID,
+		Type:          event.EventType,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Payload:       event.Payload,
+		CreatedAt:     event.CreatedAt,
+	}
+}
+
+// Publisher delivers the outbox events to the downstream services
+type Publisher interface {
+	// Publish returns once the event is delivered, an error means it may not have been
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
+
+// NewPublisher returns the publisher of the relay named by OUTBOX_PUBLISHER: "redis" appends the events
+// to the OUTBOX_STREAM stream of REDIS_ADDRESS. There is no default, the relay marks the events published
+// once handed over and they must reach a service outside the process.
+func NewPublisher(config util.Config) (Publisher, error) {
+	switch config.OutboxPublisher {
+	case "redis":
+		return NewRedisPublisher(config.RedisAddress, config.OutboxStream), nil
+	case "":
+		return nil, errors.New("OUTBOX_PUBLISHER is not set")
+	}
+	return nil, fmt.Errorf("unknown outbox publisher %q", config.OutboxPublisher)
+}
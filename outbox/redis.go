@@ -0,0 +1,44 @@
+package outbox
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+// redisStreamMaxLen trims the stream approximately, the consumers must keep up with it
+const redisStreamMaxLen = 1_000_000
+
+// RedisPublisher appends the events to a Redis stream with XADD, one entry per event holding its fields
+type RedisPublisher struct {
+	client *redis.Client
+	stream string
+}
+
+func NewRedisPublisher(address string, stream string) *RedisPublisher {
+	return &RedisPublisher{
+		client: redis.NewClient(&redis.Options{Addr: address}),
+		stream: stream,
+	}
+}
+
+func (publisher *RedisPublisher) Publish(ctx context.Context, event Event) error {
+	return publisher.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: publisher.stream,
+		MaxLen: redisStreamMaxLen,
+		Approx: true,
+		Values: []any{
+			"id", strconv.FormatInt(event.ID, 10),
+			"type", event.Type,
+			"aggregate_type", event.AggregateType,
+			"aggregate_id", event.AggregateID,
+			"payload", string(event.Payload),
+			"created_at", event.CreatedAt.Format(time.RFC3339Nano),
+		},
+	}).Err()
+}
+
+func (publisher *RedisPublisher) Close() error {
+	return publisher.client.Close()
+}
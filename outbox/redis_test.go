@@ -0,0 +1,47 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestRedisPublisher runs against a local server, such as the container started by `task redis`:
+// REDIS_ADDRESS=localhost:6379 go test ./outbox
+func TestRedisPublisher(t *testing.T) {
+	address := os.Getenv("REDIS_ADDRESS")
+	if address == "" {
+		t.Skip("REDIS_ADDRESS is not set")
+	}
+	ctx := context.Background()
+	stream := "simplebank:test:" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	publisher := NewRedisPublisher(address, stream)
+	defer publisher.Close()
+	defer publisher.client.Del(ctx, stream)
+
+	event := Event{
+		ID:            7,
+		Type:          "transfer.posted",
+		AggregateType: "transfer",
+		AggregateID:   "12",
+		Payload:       json.RawMessage(`{"amount":100}`),
+		CreatedAt:     time.Now().UTC(),
+	}
+	require.NoError(t, publisher.Publish(ctx, event))
+
+	entries, err := publisher.client.XRange(ctx, stream, "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	fields := entries[0].Values
+	require.Equal(t, "7", fields["id"])
+	require.Equal(t, event.Type, fields["type"])
+	require.Equal(t, event.AggregateType, fields["aggregate_type"])
+	require.Equal(t, event.AggregateID, fields["aggregate_id"])
+	require.JSONEq(t, string(event.Payload), fields["payload"].(string))
+	require.Equal(t, event.CreatedAt.Format(time.RFC3339Nano), fields["created_at"])
+}
@@ -0,0 +1,96 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/queue"
+	"sort"
+	"time"
+)
+
+const (
+	// RetryBackoff is the delay before the first retry of a failed publication, it doubles with each
+	// failed attempt up to MaxRetryBackoff
+	RetryBackoff    = 5 * time.Second
+	MaxRetryBackoff = 10 * time.Minute
+	// claimLease keeps the claimed events away from the other relays, an event whose publication
+	// outlasts it may be published twice
+	claimLease = time.Minute
+	batchSize  = 100
+)
+
+var (
+	lease = queue.Lease{Name: "outbox events", Duration: claimLease, BatchSize: batchSize}
+	// a publication is retried until it succeeds, the events written after it wait for it
+	retry = queue.Retry{Backoff: RetryBackoff, MaxBackoff: MaxRetryBackoff}
+)
+
+// Relay publishes the outbox events available at now in the order they were written and returns the
+// number of published events. It stops at the first failed publication: the event is retried after
+// a backoff and the rest of the batch is delayed with it. The claim skips nothing, the events written
+// after a failed one wait for its retry.
+func Relay(ctx context.Context, store db.Store, publisher Publisher, now time.Time) (int, error) {
+	events, err := queue.ClaimDue(ctx, lease, now, func(ctx context.Context, claim queue.Claim) ([]db.OutboxEvent, error) {
+		return store.ClaimOutboxEvents(ctx, db.ClaimOutboxEventsParams(claim))
+	})
+	if db.ErrCode(err) == db.LockNotAvailable {
+		// another relay is claiming the next events, they are published by it
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	// the rows returned by an update come in no particular order
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].ID < events[j].ID
+	})
+
+	for i, event := range events {
+		if err = publisher.Publish(ctx, NewEvent(event)); err != nil {
+			err = fmt.Errorf("cannot publish outbox event [%d]: %w", event.ID, err)
+			return i, errors.Join(err, retryLater(ctx, store, events[i:], err, now))
+		}
+		err = store.MarkOutboxEventPublished(ctx, db.MarkOutboxEventPublishedParams{
+			PublishedAt: now,
+			ID:          event.ID,
+		})
+		if err != nil {
+			// the event is published again once its claim expires
+			return i, fmt.Errorf("cannot mark outbox event [%d] published: %w", event.ID, err)
+		}
+	}
+	return len(events), nil
+}
+
+// retryLater records the failed publication of the first event and delays the others until its retry
+func retryLater(ctx context.Context, store db.Store, events []db.OutboxEvent, failure error, now time.Time) error {
+	failed := events[0]
+	retryAt, _ := retry.After(failed.Attempts+1, now)
+
+	err := store.RecordOutboxEventFailure(ctx, db.RecordOutboxEventFailureParams{
+		LastError: failure.Error(),
+		RetryAt:   retryAt,
+		ID:        failed.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot record the failure of outbox event [%d]: %w", failed.ID, err)
+	}
+	if len(events) == 1 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(events)-1)
+	for _, event := range events[1:] {
+		ids = append(ids, event.ID)
+	}
+	err = store.DelayOutboxEvents(ctx, db.DelayOutboxEventsParams{
+		AvailableAt: retryAt,
+		Ids:         ids,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot delay outbox events: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	mockdb "github.com/fayca121/simplebank/db/mock"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+var errUnavailable = errors.New("broker unavailable")
+
+// recordingPublisher keeps the published events and fails the event failID
+type recordingPublisher struct {
+	failID    int64
+	published []Event
+}
+
+func (publisher *recordingPublisher) Publish(_ context.Context, event Event) error {
+	if event.ID == publisher.failID {
+		return errUnavailable
+	}
+	publisher.published = append(publisher.published, event)
+	return nil
+}
+
+func (publisher *recordingPublisher) Close() error {
+	return nil
+}
+
+func TestRelay(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	// claimed out of order, the relay publishes them by id
+	events := []db.OutboxEvent{
+		{ID: 3, EventType: db.EventTransferPosted, AggregateType: db.EventAggregateTransfer, AggregateID: "30", Payload: []byte(`{}`)},
+		{ID: 1, EventType: db.EventTransferPosted, AggregateType: db.EventAggregateTransfer, AggregateID: "10", Payload: []byte(`{}`)},
+		{ID: 2, EventType: db.EventTransferPosted, AggregateType: db.EventAggregateTransfer, AggregateID: "20", Payload: []byte(`{}`), Attempts: 2},
+	}
+
+	testCases := []struct {
+		name          string
+		failID        int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, published int, err error, publisher *recordingPublisher)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				for _, id := range []int64{1, 2, 3} {
+					store.EXPECT().
+						MarkOutboxEventPublished(gomock.Any(), gomock.Eq(db.MarkOutboxEventPublishedParams{PublishedAt: now, ID: id})).
+						Times(1).
+						Return(nil)
+				}
+			},
+			checkResponse: func(t *testing.T, published int, err error, publisher *recordingPublisher) {
+				require.NoError(t, err)
+				require.Equal(t, 3, published)
+				require.Len(t, publisher.published, 3)
+				for i, event := range publisher.published {
+					require.Equal(t, int64(i+1), event.ID)
+				}
+			},
+		},
+		{
+			name:   "PublishFailure",
+			failID: 2,
+			buildStubs: func(store *mockdb.MockStore) {
+				retryAt := now.Add(util.Backoff(3, RetryBackoff, MaxRetryBackoff))
+				store.EXPECT().
+					MarkOutboxEventPublished(gomock.Any(), gomock.Eq(db.MarkOutboxEventPublishedParams{PublishedAt: now, ID: 1})).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					RecordOutboxEventFailure(gomock.Any(), gomock.Eq(db.RecordOutboxEventFailureParams{
+						LastError: "cannot publish outbox event [2]: " + errUnavailable.Error(),
+						RetryAt:   retryAt,
+						ID:        2,
+					})).
+					Times(1).
+					Return(nil)
+				// the next events wait for the failed one
+				store.EXPECT().
+					DelayOutboxEvents(gomock.Any(), gomock.Eq(db.DelayOutboxEventsParams{AvailableAt: retryAt, Ids: []int64{3}})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, published int, err error, publisher *recordingPublisher) {
+				require.ErrorIs(t, err, errUnavailable)
+				require.Equal(t, 1, published)
+				require.Len(t, publisher.published, 1)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+
+			store.EXPECT().
+				ClaimOutboxEvents(gomock.Any(), gomock.Eq(db.ClaimOutboxEventsParams{
+					LeaseUntil: now.Add(claimLease),
+					DueAt:      now,
+					Limit:      batchSize,
+				})).
+				Times(1).
+				Return(append([]db.OutboxEvent(nil), events...), nil)
+			tc.buildStubs(store)
+
+			publisher := &recordingPublisher{failID: tc.failID}
+			published, err := Relay(context.Background(), store, publisher, now)
+			tc.checkResponse(t, published, err, publisher)
+		})
+	}
+}
+
+func TestRelayWhileAnotherRelayClaims(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ClaimOutboxEvents(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil, &pgconn.PgError{Code: db.LockNotAvailable})
+
+	published, err := Relay(context.Background(), store, &recordingPublisher{}, time.Now())
+	require.NoError(t, err)
+	require.Zero(t, published)
+}
@@ -0,0 +1,175 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const minKeySize = 32
+
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// Scopes of the listings, a page token only pages through the listing it was issued for
+
+func AccountsScope(owner string) string {
+	return "accounts:" + owner
+}
+
+func EntriesScope(accountID int64) string {
+	return "entries:" + strconv.FormatInt(accountID, 10)
+}
+
+func TransfersScope(accountID int64) string {
+	return "transfers:" + strconv.FormatInt(accountID, 10)
+}
+
+func SessionsScope(username string) string {
+	return "sessions:" + username
+}
+
+func ScheduledTransfersScope(accountID int64) string {
+	return "scheduled-transfers:" + strconv.FormatInt(accountID, 10)
+}
+
+func AuditEventsScope() string {
+	return "audit-events"
+}
+
+// Cursor is the position of the last row of a page in the (created_at, id) order of a listing
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"i"`
+}
+
+func NewCursor(createdAt time.Time, id int64) Cursor {
+	return Cursor{CreatedAt: createdAt, ID: strconv.FormatInt(id, 10)}
+}
+
+func NewUUIDCursor(createdAt time.Time, id uuid.UUID) Cursor {
+	return Cursor{CreatedAt: createdAt, ID: id.String()}
+}
+
+// Int64ID returns the id of a cursor created by NewCursor
+func (cursor Cursor) Int64ID() (int64, error) {
+	id, err := strconv.ParseInt(cursor.ID, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidPageToken
+	}
+	return id, nil
+}
+
+// UUID returns the id of a cursor created by NewUUIDCursor
+func (cursor Cursor) UUID() (uuid.UUID, error) {
+	id, err := uuid.Parse(cursor.ID)
+	if err != nil {
+		return uuid.UUID{}, ErrInvalidPageToken
+	}
+	return id, nil
+}
+
+// Signer turns cursors into opaque page tokens. A token is signed with HMAC-SHA256 and bound
+// to the scope of the listing it was issued for, so it can't be forged or replayed on another listing.
+type Signer struct {
+	key []byte
+}
+
+func NewSigner(key string) (*Signer, error) {
+	if len(key) < minKeySize {
+		return nil, fmt.Errorf("invalid key size: must be at least %d characters", minKeySize)
+	}
+	return &Signer{key: []byte(key)}, nil
+}
+
+func (signer *Signer) Encode(scope string, cursor Cursor) string {
+	// the payload can't fail to marshal: it only holds a time and a string
+	payload, _ := json.Marshal(cursor)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(signer.sign(scope, encoded))
+}
+
+func (signer *Signer) Decode(scope string, token string) (Cursor, error) {
+	var cursor Cursor
+
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return cursor, ErrInvalidPageToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, signer.sign(scope, encoded)) {
+		return cursor, ErrInvalidPageToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, ErrInvalidPageToken
+	}
+	if err = json.Unmarshal(payload, &cursor); err != nil {
+		return cursor, ErrInvalidPageToken
+	}
+	return cursor, nil
+}
+
+// After decodes the page token of a request, an empty token asks for the first page and returns a nil cursor
+func (signer *Signer) After(scope string, token string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	cursor, err := signer.Decode(scope, token)
+	if err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// Keyset decodes the page token of a listing ordered by (created_at, id) with an integer id
+func (signer *Signer) Keyset(scope string, token string) (db.Keyset, error) {
+	cursor, err := signer.After(scope, token)
+	if err != nil || cursor == nil {
+		return db.Keyset{}, err
+	}
+	id, err := cursor.Int64ID()
+	if err != nil {
+		return db.Keyset{}, err
+	}
+	return db.NewKeyset(cursor.CreatedAt, id), nil
+}
+
+// UUIDKeyset decodes the page token of a listing ordered by (created_at, id) with a uuid id
+func (signer *Signer) UUIDKeyset(scope string, token string) (pgtype.Timestamptz, pgtype.UUID, error) {
+	cursor, err := signer.After(scope, token)
+	if err != nil || cursor == nil {
+		return pgtype.Timestamptz{}, pgtype.UUID{}, err
+	}
+	id, err := cursor.UUID()
+	if err != nil {
+		return pgtype.Timestamptz{}, pgtype.UUID{}, err
+	}
+	return pgtype.Timestamptz{Time: cursor.CreatedAt, Valid: true}, pgtype.UUID{Bytes: id, Valid: true}, nil
+}
+
+// NextPage trims the rows of a listing queried with a limit of size+1 to its page and returns the
+// token of the next page, which is empty on the last page
+func NextPage[T any](signer *Signer, scope string, rows []T, size int32, cursorOf func(T) Cursor) ([]T, string) {
+	if int32(len(rows)) <= size {
+		return rows, ""
+	}
+	rows = rows[:size]
+	return rows, signer.Encode(scope, cursorOf(rows[size-1]))
+}
+
+func (signer *Signer) sign(scope string, encoded string) []byte {
+	mac := hmac.New(sha256.New, signer.key)
+	mac.Write([]byte(scope))
+	mac.Write([]byte{0})
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,73 @@
+package pagination
+
+import (
+	"github.com/fayca121/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestSigner(t *testing.T) {
+	signer, err := NewSigner(util.RandomString(32))
+	require.NoError(t, err)
+
+	cursor := Cursor{CreatedAt: time.Now().UTC().Truncate(time.Microsecond), ID: "42"}
+	token := signer.Encode("entries:1", cursor)
+
+	decoded, err := signer.Decode("entries:1", token)
+	require.NoError(t, err)
+	require.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+	require.Equal(t, cursor.ID, decoded.ID)
+
+	// the token is bound to its listing
+	_, err = signer.Decode("entries:2", token)
+	require.ErrorIs(t, err, ErrInvalidPageToken)
+
+	other, err := NewSigner(util.RandomString(32))
+	require.NoError(t, err)
+	_, err = other.Decode("entries:1", token)
+	require.ErrorIs(t, err, ErrInvalidPageToken)
+}
+
+func TestSignerTamperedToken(t *testing.T) {
+	signer, err := NewSigner(util.RandomString(32))
+	require.NoError(t, err)
+	token := signer.Encode("accounts:alice", Cursor{CreatedAt: time.Now(), ID: "1"})
+	forged := signer.Encode("accounts:alice", Cursor{CreatedAt: time.Now(), ID: "2"})
+
+	for _, invalid := range []string{"", "garbage", token[:len(token)-2], forged[:len(forged)/2] + token[len(token)/2:]} {
+		_, err = signer.Decode("accounts:alice", invalid)
+		require.ErrorIs(t, err, ErrInvalidPageToken)
+	}
+}
+
+func TestNewSignerShortKey(t *testing.T) {
+	_, err := NewSigner(util.RandomString(16))
+	require.Error(t, err)
+}
+
+func TestNextPage(t *testing.T) {
+	signer, err := NewSigner(util.RandomString(32))
+	require.NoError(t, err)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	rows := []int64{1, 2, 3, 4, 5, 6}
+	cursorOf := func(id int64) Cursor { return NewCursor(now, id) }
+
+	page, next := NextPage(signer, "accounts:alice", rows, 5, cursorOf)
+	require.Equal(t, rows[:5], page)
+	require.NotEmpty(t, next)
+
+	after, err := signer.After("accounts:alice", next)
+	require.NoError(t, err)
+	id, err := after.Int64ID()
+	require.NoError(t, err)
+	require.Equal(t, int64(5), id)
+
+	page, next = NextPage(signer, "accounts:alice", rows[:5], 5, cursorOf)
+	require.Len(t, page, 5)
+	require.Empty(t, next)
+
+	after, err = signer.After("accounts:alice", "")
+	require.NoError(t, err)
+	require.Nil(t, after)
+}
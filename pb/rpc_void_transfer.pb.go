@@ -0,0 +1,241 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        v4.25.1
+// source: rpc_void_transfer.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type VoidTransferRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TransferId int64 `protobuf:"varint,1,opt,name=transfer_id,json=transferId,proto3" json:"transfer_id,omitempty"`
+}
+
+func (x *VoidTransferRequest) Reset() {
+	*x = VoidTransferRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_void_transfer_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VoidTransferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VoidTransferRequest) ProtoMessage() {}
+
+func (x *VoidTransferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_void_transfer_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VoidTransferRequest.ProtoReflect.Descriptor instead.
+func (*VoidTransferRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_void_transfer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *VoidTransferRequest) GetTransferId() int64 {
+	if x != nil {
+		return x.TransferId
+	}
+	return 0
+}
+
+type VoidTransferResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the voided transfer
+	Transfer    *Transfer     `protobuf:"bytes,1,opt,name=transfer,proto3" json:"transfer,omitempty"`
+	Hold        *TransferHold `protobuf:"bytes,2,opt,name=hold,proto3" json:"hold,omitempty"`
+	FromAccount *Account      `protobuf:"bytes,3,opt,name=from_account,json=fromAccount,proto3" json:"from_account,omitempty"`
+}
+
+func (x *VoidTransferResponse) Reset() {
+	*x = VoidTransferResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_void_transfer_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VoidTransferResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VoidTransferResponse) ProtoMessage() {}
+
+func (x *VoidTransferResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_void_transfer_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VoidTransferResponse.ProtoReflect.Descriptor instead.
+func (*VoidTransferResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_void_transfer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *VoidTransferResponse) GetTransfer() *Transfer {
+	if x != nil {
+		return x.Transfer
+	}
+	return nil
+}
+
+func (x *VoidTransferResponse) GetHold() *TransferHold {
+	if x != nil {
+		return x.Hold
+	}
+	return nil
+}
+
+func (x *VoidTransferResponse) GetFromAccount() *Account {
+	if x != nil {
+		return x.FromAccount
+	}
+	return nil
+}
+
+var File_rpc_void_transfer_proto protoreflect.FileDescriptor
+
+var file_rpc_void_transfer_proto_rawDesc = []byte{
+	0x0a, 0x17, 0x72, 0x70, 0x63, 0x5f, 0x76, 0x6f, 0x69, 0x64, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73,
+	0x66, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x1a, 0x0d, 0x61,
+	0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0e, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x36, 0x0a, 0x13,
+	0x56, 0x6f, 0x69, 0x64, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x65, 0x72, 0x49, 0x64, 0x22, 0x96, 0x01, 0x0a, 0x14, 0x56, 0x6f, 0x69, 0x64, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28, 0x0a,
+	0x08, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x08, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x12, 0x24, 0x0a, 0x04, 0x68, 0x6f, 0x6c, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x66, 0x65, 0x72, 0x48, 0x6f, 0x6c, 0x64, 0x52, 0x04, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x2e, 0x0a,
+	0x0c, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x52, 0x0b, 0x66, 0x72, 0x6f, 0x6d, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x42, 0x23, 0x5a,
+	0x21, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x66, 0x61, 0x79, 0x63,
+	0x61, 0x31, 0x32, 0x31, 0x2f, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x62, 0x61, 0x6e, 0x6b, 0x2f,
+	0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rpc_void_transfer_proto_rawDescOnce sync.Once
+	file_rpc_void_transfer_proto_rawDescData = file_rpc_void_transfer_proto_rawDesc
+)
+
+func file_rpc_void_transfer_proto_rawDescGZIP() []byte {
+	file_rpc_void_transfer_proto_rawDescOnce.Do(func() {
+		file_rpc_void_transfer_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_void_transfer_proto_rawDescData)
+	})
+	return file_rpc_void_transfer_proto_rawDescData
+}
+
+var file_rpc_void_transfer_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_void_transfer_proto_goTypes = []interface{}{
+	(*VoidTransferRequest)(nil),  // 0: pb.VoidTransferRequest
+	(*VoidTransferResponse)(nil), // 1: pb.VoidTransferResponse
+	(*Transfer)(nil),             // 2: pb.Transfer
+	(*TransferHold)(nil),         // 3: pb.TransferHold
+	(*Account)(nil),              // 4: pb.Account
+}
+var file_rpc_void_transfer_proto_depIdxs = []int32{
+	2, // 0: pb.VoidTransferResponse.transfer:type_name -> pb.Transfer
+	3, // 1: pb.VoidTransferResponse.hold:type_name -> pb.TransferHold
+	4, // 2: pb.VoidTransferResponse.from_account:type_name -> pb.Account
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_rpc_void_transfer_proto_init() }
+func file_rpc_void_transfer_proto_init() {
+	if File_rpc_void_transfer_proto != nil {
+		return
+	}
+	file_account_proto_init()
+	file_transfer_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_void_transfer_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VoidTransferRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_void_transfer_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VoidTransferResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_void_transfer_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_void_transfer_proto_goTypes,
+		DependencyIndexes: file_rpc_void_transfer_proto_depIdxs,
+		MessageInfos:      file_rpc_void_transfer_proto_msgTypes,
+	}.Build()
+	File_rpc_void_transfer_proto = out.File
+	file_rpc_void_transfer_proto_rawDesc = nil
+	file_rpc_void_transfer_proto_goTypes = nil
+	file_rpc_void_transfer_proto_depIdxs = nil
+}
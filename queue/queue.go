@@ -0,0 +1,84 @@
+// Package queue runs the tables the background jobs use as work queues: the scheduled transfers,
+// the outbox events and the tasks. A job claims a batch of the rows due by pushing them back to the
+// end of a lease, the other instances skip them until the outcome of each row is recorded, or until
+// the lease expires when the instance dies and the rows are claimed again.
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fayca121/simplebank/util"
+	"time"
+)
+
+// Claim holds the arguments of the claim queries, it converts to their parameters
+type Claim struct {
+	LeaseUntil time.Time
+	DueAt      time.Time
+	Limit      int32
+}
+
+// Lease tells how a job claims the rows of its queue
+type Lease struct {
+	// Name designates the rows in the errors
+	Name string
+	// Duration keeps the claimed rows away from the other instances, a row whose processing
+	// outlasts it may be processed twice
+	Duration  time.Duration
+	BatchSize int32
+}
+
+// At returns the claim of the rows due at now
+func (lease Lease) At(now time.Time) Claim {
+	return Claim{
+		LeaseUntil: now.Add(lease.Duration),
+		DueAt:      now,
+		Limit:      lease.BatchSize,
+	}
+}
+
+// ClaimDue claims the rows due at now with the claim query of the queue
+func ClaimDue[T any](ctx context.Context, lease Lease, now time.Time,
+	claim func(context.Context, Claim) ([]T, error)) ([]T, error) {
+	rows, err := claim(ctx, lease.At(now))
+	if err != nil {
+		return nil, fmt.Errorf("cannot claim %s: %w", lease.Name, err)
+	}
+	return rows, nil
+}
+
+// Each claims the rows due at now and processes them one by one, every row is processed even when
+// another one fails. It returns the number of rows processed and the failures joined.
+func Each[T any](ctx context.Context, lease Lease, now time.Time,
+	claim func(context.Context, Claim) ([]T, error), process func(context.Context, T) error) (int, error) {
+	rows, err := ClaimDue(ctx, lease, now, claim)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	var errs []error
+	for _, row := range rows {
+		if err := process(ctx, row); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		processed++
+	}
+	return processed, errors.Join(errs...)
+}
+
+// Retry tells when a failed row runs again: after a backoff doubling with each failed attempt
+type Retry struct {
+	// MaxAttempts is the number of runs of a row, zero retries it until it succeeds
+	MaxAttempts int32
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+// After returns when the row runs again after its given failed attempt, and whether that was its last attempt
+func (retry Retry) After(attempt int32, now time.Time) (time.Time, bool) {
+	last := retry.MaxAttempts > 0 && attempt >= retry.MaxAttempts
+	return now.Add(util.Backoff(attempt, retry.Backoff, retry.MaxBackoff)), last
+}
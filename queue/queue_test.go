@@ -0,0 +1,63 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestEach(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	lease := Lease{Name: "rows", Duration: time.Minute, BatchSize: 10}
+
+	var claimed Claim
+	claim := func(ctx context.Context, claim Claim) ([]int, error) {
+		claimed = claim
+		return []int{1, 2, 3}, nil
+	}
+	var seen []int
+	processed, err := Each(context.Background(), lease, now, claim, func(ctx context.Context, row int) error {
+		seen = append(seen, row)
+		if row == 2 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	require.EqualError(t, err, "failed")
+	require.Equal(t, 2, processed)
+	require.Equal(t, []int{1, 2, 3}, seen)
+	require.Equal(t, Claim{LeaseUntil: now.Add(time.Minute), DueAt: now, Limit: 10}, claimed)
+
+	failure := errors.New("connection refused")
+	_, err = Each(context.Background(), lease, now, func(ctx context.Context, claim Claim) ([]int, error) {
+		return nil, failure
+	}, func(ctx context.Context, row int) error {
+		t.Fatal("nothing was claimed")
+		return nil
+	})
+	require.ErrorIs(t, err, failure)
+	require.EqualError(t, err, "cannot claim rows: connection refused")
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	retry := Retry{MaxAttempts: 3, Backoff: time.Minute, MaxBackoff: time.Hour}
+
+	at, last := retry.After(1, now)
+	require.Equal(t, now.Add(time.Minute), at)
+	require.False(t, last)
+
+	at, last = retry.After(2, now)
+	require.Equal(t, now.Add(2*time.Minute), at)
+	require.False(t, last)
+
+	_, last = retry.After(3, now)
+	require.True(t, last)
+
+	// without a maximum, no attempt is the last one
+	retry.MaxAttempts = 0
+	_, last = retry.After(100, now)
+	require.False(t, last)
+}
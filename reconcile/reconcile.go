@@ -0,0 +1,114 @@
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"io"
+	"time"
+)
+
+// Report lists the inconsistencies of the double-entry ledger found by a scan
+type Report struct {
+	StartedAt         time.Time                     `json:"started_at"`
+	FinishedAt        time.Time                     `json:"finished_at"`
+	Drifts            []Drift                       `json:"drifts"`
+	OrphanedEntries   []db.Entry                    `json:"orphaned_entries"`
+	OrphanedTransfers []db.ListOrphanedTransfersRow `json:"orphaned_transfers"`
+}
+
+// Drift is an account whose balance is not the sum of its entries
+type Drift struct {
+	AccountID    int64 `json:"account_id"`
+	Balance      int64 `json:"balance"`
+	EntriesTotal int64 `json:"entries_total"`
+	// Drift is Balance - EntriesTotal
+	Drift int64 `json:"drift"`
+}
+
+// Run scans the ledger: the balance of every account against the sum of its entries,
+// the entries without a transfer and the transfers without their debit and credit entries
+func Run(ctx context.Context, q db.Querier) (*Report, error) {
+	report := &Report{StartedAt: time.Now()}
+
+	drifts, err := q.ListBalanceDrifts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list balance drifts: %w", err)
+	}
+	report.Drifts = make([]Drift, 0, len(drifts))
+	for _, drift := range drifts {
+		report.Drifts = append(report.Drifts, Drift{
+			AccountID:    drift.AccountID,
+			Balance:      drift.Balance,
+			EntriesTotal: drift.EntriesTotal,
+			Drift:        drift.Balance - drift.EntriesTotal,
+		})
+	}
+
+	report.OrphanedEntries, err = q.ListOrphanedEntries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list orphaned entries: %w", err)
+	}
+
+	report.OrphanedTransfers, err = q.ListOrphanedTransfers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list orphaned transfers: %w", err)
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// Clean reports whether the scan found no inconsistency
+func (report *Report) Clean() bool {
+	return len(report.Drifts) == 0 && len(report.OrphanedEntries) == 0 && len(report.OrphanedTransfers) == 0
+}
+
+// Save records the report in the reconciliation_reports table
+func Save(ctx context.Context, q db.Querier, report *Report) (db.ReconciliationReport, error) {
+	findings, err := json.Marshal(report)
+	if err != nil {
+		return db.ReconciliationReport{}, fmt.Errorf("cannot encode findings: %w", err)
+	}
+	return q.CreateReconciliationReport(ctx, db.CreateReconciliationReportParams{
+		StartedAt:         report.StartedAt,
+		FinishedAt:        report.FinishedAt,
+		DriftedAccounts:   int32(len(report.Drifts)),
+		OrphanedEntries:   int32(len(report.OrphanedEntries)),
+		OrphanedTransfers: int32(len(report.OrphanedTransfers)),
+		Findings:          findings,
+	})
+}
+
+// Write prints the report for the reconcile command
+func Write(w io.Writer, report *Report) error {
+	var err error
+	printf := func(format string, args ...any) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+
+	printf("ledger scanned in %s\n", report.FinishedAt.Sub(report.StartedAt).Round(time.Millisecond))
+	printf("%d drifted accounts\n", len(report.Drifts))
+	for _, drift := range report.Drifts {
+		printf("  account %d: balance %d, entries total %d, drift %+d\n",
+			drift.AccountID, drift.Balance, drift.EntriesTotal, drift.Drift)
+	}
+	printf("%d orphaned entries\n", len(report.OrphanedEntries))
+	for _, entry := range report.OrphanedEntries {
+		if entry.TransferID.Valid {
+			printf("  entry %d: account %d, amount %d, not part of transfer %d\n",
+				entry.ID, entry.AccountID, entry.Amount, entry.TransferID.Int64)
+			continue
+		}
+		printf("  entry %d: account %d, amount %d, no transfer\n", entry.ID, entry.AccountID, entry.Amount)
+	}
+	printf("%d orphaned transfers\n", len(report.OrphanedTransfers))
+	for _, transfer := range report.OrphanedTransfers {
+		printf("  transfer %d: %d -> %d, %d debit and %d credit entries\n",
+			transfer.ID, transfer.FromAccountID, transfer.ToAccountID, transfer.DebitEntries, transfer.CreditEntries)
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	mockdb "github.com/fayca121/simplebank/db/mock"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().ListBalanceDrifts(gomock.Any()).Times(1).
+		Return([]db.ListBalanceDriftsRow{{AccountID: 1, Balance: 500, EntriesTotal: 300}}, nil)
+	store.EXPECT().ListOrphanedEntries(gomock.Any()).Times(1).
+		Return([]db.Entry{{ID: 7, AccountID: 1, Amount: 200}}, nil)
+	store.EXPECT().ListOrphanedTransfers(gomock.Any()).Times(1).
+		Return([]db.ListOrphanedTransfersRow{{ID: 3, FromAccountID: 1, ToAccountID: 2, Amount: 10, ToAmount: 10, DebitEntries: 1}}, nil)
+
+	report, err := Run(context.Background(), store)
+	require.NoError(t, err)
+	require.False(t, report.Clean())
+	require.Equal(t, []Drift{{AccountID: 1, Balance: 500, EntriesTotal: 300, Drift: 200}}, report.Drifts)
+	require.Len(t, report.OrphanedEntries, 1)
+	require.Len(t, report.OrphanedTransfers, 1)
+
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, report))
+	require.Contains(t, out.String(), "account 1: balance 500, entries total 300, drift +200")
+	require.Contains(t, out.String(), "entry 7: account 1, amount 200, no transfer")
+	require.Contains(t, out.String(), "transfer 3: 1 -> 2, 1 debit and 0 credit entries")
+}
+
+func TestRunClean(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().ListBalanceDrifts(gomock.Any()).Times(1).Return([]db.ListBalanceDriftsRow{}, nil)
+	store.EXPECT().ListOrphanedEntries(gomock.Any()).Times(1).Return([]db.Entry{}, nil)
+	store.EXPECT().ListOrphanedTransfers(gomock.Any()).Times(1).Return([]db.ListOrphanedTransfersRow{}, nil)
+
+	report, err := Run(context.Background(), store)
+	require.NoError(t, err)
+	require.True(t, report.Clean())
+}
+
+func TestSave(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+
+	report := &Report{
+		Drifts:          []Drift{{AccountID: 1, Balance: 500, EntriesTotal: 300, Drift: 200}},
+		OrphanedEntries: []db.Entry{{ID: 7, AccountID: 1, Amount: 200, TransferID: pgtype.Int8{Int64: 9, Valid: true}}},
+	}
+	store.EXPECT().CreateReconciliationReport(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateReconciliationReportParams) (db.ReconciliationReport, error) {
+			require.Equal(t, int32(1), arg.DriftedAccounts)
+			require.Equal(t, int32(1), arg.OrphanedEntries)
+			require.Zero(t, arg.OrphanedTransfers)
+
+			var findings Report
+			require.NoError(t, json.Unmarshal(arg.Findings, &findings))
+			require.Equal(t, report.Drifts, findings.Drifts)
+			return db.ReconciliationReport{ID: 1}, nil
+		})
+
+	saved, err := Save(context.Background(), store, report)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), saved.ID)
+}
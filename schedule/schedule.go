@@ -0,0 +1,202 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinInterval is the shortest interval accepted by an @every schedule
+const MinInterval = time.Minute
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedule computes the occurrences of a scheduled transfer, all times are evaluated in UTC
+type Schedule interface {
+	// Next returns the first occurrence strictly after t
+	Next(t time.Time) time.Time
+}
+
+// Parse reads a schedule, either an interval such as "@every 168h", one of the shortcuts
+// @hourly, @daily, @weekly, @monthly and @yearly, or a cron expression of five fields:
+// minute, hour, day of month, month and day of week, e.g. "0 9 1 * *" for 9:00 on the 1st of every month
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if interval, found := strings.CutPrefix(spec, "@every "); found {
+		d, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSchedule, err)
+		}
+		if d < MinInterval {
+			return nil, fmt.Errorf("%w: interval must be at least %s", ErrInvalidSchedule, MinInterval)
+		}
+		return every(d.Truncate(time.Second)), nil
+	}
+	if expression, found := shortcuts[spec]; found {
+		spec = expression
+	}
+	return parseCron(spec)
+}
+
+// First returns the first occurrence of the schedule at or after start: start itself for
+// an interval, the first matching minute for a cron expression
+func First(schedule Schedule, start time.Time) time.Time {
+	if _, ok := schedule.(every); ok {
+		return start.UTC()
+	}
+	return schedule.Next(start.Add(-time.Nanosecond))
+}
+
+// every is an interval schedule
+type every time.Duration
+
+func (interval every) Next(t time.Time) time.Time {
+	return t.UTC().Add(time.Duration(interval))
+}
+
+var shortcuts = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+	"@yearly":  "0 0 1 1 *",
+}
+
+// cron holds the values matched by each field as a bit set
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	// restricted days of month and days of week match as either, like in cron
+	domStar, dowStar bool
+}
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+func parseCron(spec string) (Schedule, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("%w: expected %d fields, got %d", ErrInvalidSchedule, len(fields), len(parts))
+	}
+	sets := make([]uint64, len(fields))
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+	c := &cron{
+		minute:  sets[0],
+		hour:    sets[1],
+		dom:     sets[2],
+		month:   sets[3],
+		dow:     sets[4],
+		domStar: parts[2] == "*",
+		dowStar: parts[4] == "*",
+	}
+	if c.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("%w: %q never matches", ErrInvalidSchedule, spec)
+	}
+	return c, nil
+}
+
+// parseField reads a comma separated list of *, values and ranges, each with an optional /step
+func parseField(value string, f field) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		low, high := f.min, f.max
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseValue(lowPart, f); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = parseValue(highPart, f); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				high = f.max
+			}
+			if low > high {
+				return 0, fmt.Errorf("%w: %s range %s is empty", ErrInvalidSchedule, f.name, rangePart)
+			}
+		}
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("%w: %s step %q", ErrInvalidSchedule, f.name, stepPart)
+			}
+		}
+		for v := low; v <= high; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+func parseValue(value string, f field) (int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("%w: %s must be between %d and %d, got %q", ErrInvalidSchedule, f.name, f.min, f.max, value)
+	}
+	return v, nil
+}
+
+// maxYears bounds the search of an expression that never matches, such as "0 0 31 2 *"
+const maxYears = 5
+
+func (c *cron) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxYears, 0, 0)
+
+	for t.Before(limit) {
+		if !has(c.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !has(c.hour, t.Hour()) {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if !has(c.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c *cron) matchDay(t time.Time) bool {
+	domMatch := has(c.dom, t.Day())
+	dowMatch := has(c.dow, int(t.Weekday()))
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func has(set uint64, value int) bool {
+	return set&(1<<uint(value)) != 0
+}
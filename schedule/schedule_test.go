@@ -0,0 +1,102 @@
+package schedule
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestParseNext(t *testing.T) {
+	testCases := []struct {
+		name string
+		spec string
+		from time.Time
+		next []time.Time
+	}{
+		{
+			name: "Monthly",
+			spec: "0 9 1 * *",
+			from: date(2024, time.January, 15, 12, 0),
+			next: []time.Time{date(2024, time.February, 1, 9, 0), date(2024, time.March, 1, 9, 0)},
+		},
+		{
+			name: "Every",
+			spec: "@every 168h",
+			from: date(2024, time.January, 15, 12, 30),
+			next: []time.Time{date(2024, time.January, 22, 12, 30), date(2024, time.January, 29, 12, 30)},
+		},
+		{
+			name: "Shortcut",
+			spec: "@daily",
+			from: date(2024, time.December, 31, 0, 0),
+			next: []time.Time{date(2025, time.January, 1, 0, 0), date(2025, time.January, 2, 0, 0)},
+		},
+		{
+			name: "WeekdaysStep",
+			spec: "*/30 8-9 * * 1-5",
+			from: date(2024, time.January, 5, 9, 45), // a friday
+			next: []time.Time{date(2024, time.January, 8, 8, 0), date(2024, time.January, 8, 8, 30)},
+		},
+		{
+			// a restricted day of month and day of week match as either
+			name: "DayOfMonthOrWeek",
+			spec: "0 0 13 * 5",
+			from: date(2024, time.September, 1, 0, 0),
+			next: []time.Time{date(2024, time.September, 6, 0, 0), date(2024, time.September, 13, 0, 0)},
+		},
+		{
+			name: "LeapDay",
+			spec: "0 0 29 2 *",
+			from: date(2024, time.March, 1, 0, 0),
+			next: []time.Time{date(2028, time.February, 29, 0, 0)},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			schedule, err := Parse(tc.spec)
+			require.NoError(t, err)
+			current := tc.from
+			for _, expected := range tc.next {
+				current = schedule.Next(current)
+				require.Equal(t, expected, current)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"0 9 1 *",
+		"60 * * * *",
+		"* * 0 * *",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"@every 30s",
+		"@every week",
+		"@fortnightly",
+		"0 0 31 2 *",
+	} {
+		_, err := Parse(spec)
+		require.ErrorIs(t, err, ErrInvalidSchedule, spec)
+	}
+}
+
+func TestFirst(t *testing.T) {
+	start := date(2024, time.January, 1, 9, 0)
+
+	monthly, err := Parse("0 9 1 * *")
+	require.NoError(t, err)
+	require.Equal(t, start, First(monthly, start))
+	require.Equal(t, date(2024, time.February, 1, 9, 0), First(monthly, start.Add(time.Second)))
+
+	interval, err := Parse("@every 24h")
+	require.NoError(t, err)
+	require.Equal(t, start, First(interval, start))
+}
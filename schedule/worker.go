@@ -0,0 +1,143 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/queue"
+	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
+
+const (
+	// MaxAttempts is the number of runs of an occurrence, it is skipped after the last failed one
+	MaxAttempts = 5
+	// RetryBackoff is the delay before the first retry, it doubles with each failed attempt up to MaxRetryBackoff
+	RetryBackoff    = time.Minute
+	MaxRetryBackoff = time.Hour
+	// claimLease keeps a claimed transfer away from the other workers, a run that outlasts it may be repeated
+	// but its idempotency key prevents a second transfer
+	claimLease = 5 * time.Minute
+	batchSize  = 50
+)
+
+var (
+	lease = queue.Lease{Name: "due scheduled transfers", Duration: claimLease, BatchSize: batchSize}
+	retry = queue.Retry{MaxAttempts: MaxAttempts, Backoff: RetryBackoff, MaxBackoff: MaxRetryBackoff}
+)
+
+// RunDue executes the scheduled transfers due at now through TransferTx and records the outcome of each run.
+// Every claimed transfer is run even when recording another one fails, the errors are joined. The run of
+// a transfer deleted meanwhile isn't recorded.
+func RunDue(ctx context.Context, store db.Store, now time.Time) ([]db.ScheduledTransferRun, error) {
+	claim := func(ctx context.Context, claim queue.Claim) ([]db.ScheduledTransfer, error) {
+		return store.ClaimDueScheduledTransfers(ctx, db.ClaimDueScheduledTransfersParams(claim))
+	}
+	var runs []db.ScheduledTransferRun
+	_, err := queue.Each(ctx, lease, now, claim, func(ctx context.Context, scheduled db.ScheduledTransfer) error {
+		run, err := execute(ctx, store, scheduled, now)
+		if errors.Is(err, db.ErrScheduledTransferDeleted) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("scheduled transfer [%d]: %w", scheduled.ID, err)
+		}
+		runs = append(runs, run)
+		return nil
+	})
+	return runs, err
+}
+
+// execute makes the transfer of the current occurrence. A failed run is retried with an exponential
+// backoff, after MaxAttempts runs the occurrence is skipped and the transfer moves to the next one.
+func execute(ctx context.Context, store db.Store, scheduled db.ScheduledTransfer, now time.Time) (db.ScheduledTransferRun, error) {
+	schedule, err := Parse(scheduled.Schedule)
+	if err != nil {
+		return db.ScheduledTransferRun{}, err
+	}
+
+	attempt := scheduled.Attempts + 1
+	arg := db.RecordScheduledTransferRunTxParam{
+		Run: db.CreateScheduledTransferRunParams{
+			ScheduledTransferID: scheduled.ID,
+			OccurrenceAt:        scheduled.OccurrenceAt,
+			Attempt:             attempt,
+		},
+		Advance: db.AdvanceScheduledTransferParams{
+			ID:                  scheduled.ID,
+			ClaimedOccurrenceAt: scheduled.OccurrenceAt,
+			ClaimedNextRunAt:    scheduled.NextRunAt,
+		},
+	}
+
+	result, err := store.TransferTx(ctx, db.TransferTxParam{
+		FromAccountID:  scheduled.FromAccountID,
+		ToAccountID:    scheduled.ToAccountID,
+		Amount:         scheduled.Amount,
+		Username:       scheduled.Username,
+		IdempotencyKey: IdempotencyKey(scheduled),
+	})
+	if err != nil {
+		arg.Run.Error = pgtype.Text{String: err.Error(), Valid: true}
+	} else {
+		arg.Run.TransferID = pgtype.Int8{Int64: result.Transfer.ID, Valid: true}
+	}
+
+	retryAt, last := retry.After(attempt, now)
+	if err != nil && !last {
+		arg.Advance.OccurrenceAt = scheduled.OccurrenceAt
+		arg.Advance.NextRunAt = retryAt
+		arg.Advance.Attempts = attempt
+	} else {
+		next := NextOccurrence(schedule, scheduled.OccurrenceAt, now)
+		arg.Advance.OccurrenceAt = next
+		arg.Advance.NextRunAt = next
+	}
+
+	recorded, err := store.RecordScheduledTransferRunTx(ctx, arg)
+	if err != nil {
+		return db.ScheduledTransferRun{}, fmt.Errorf("cannot record run: %w", err)
+	}
+	return recorded.Run, nil
+}
+
+// NextOccurrence returns the first occurrence after both occurrence and now: the occurrences
+// missed while no worker was running are skipped rather than caught up
+func NextOccurrence(schedule Schedule, occurrence, now time.Time) time.Time {
+	next := schedule.Next(occurrence)
+	for !next.IsZero() && !next.After(now) {
+		next = schedule.Next(next)
+	}
+	return next
+}
+
+// IdempotencyKey identifies the transfer of an occurrence, the retries of a run replay its transfer
+// instead of making a second one
+func IdempotencyKey(scheduled db.ScheduledTransfer) string {
+	return fmt.Sprintf("scheduled-transfer:%d:%d", scheduled.ID, scheduled.OccurrenceAt.Unix())
+}
+
+// Reschedule sets the occurrence an updated scheduled transfer restarts from, it is left unset when the
+// transfer keeps its current occurrence: a new schedule starts after now, and resuming a paused transfer
+// skips the occurrences missed while it was paused
+func Reschedule(scheduled db.ScheduledTransfer, arg *db.UpdateScheduledTransferParams, now time.Time) error {
+	if arg.Schedule.Valid && arg.Schedule.String != scheduled.Schedule {
+		schedule, err := Parse(arg.Schedule.String)
+		if err != nil {
+			return err
+		}
+		arg.OccurrenceAt = pgtype.Timestamptz{Time: schedule.Next(now), Valid: true}
+		return nil
+	}
+	resumed := arg.Status.Valid && arg.Status.String == db.ScheduledTransferActive &&
+		scheduled.Status == db.ScheduledTransferPaused
+	if resumed && !scheduled.OccurrenceAt.After(now) {
+		schedule, err := Parse(scheduled.Schedule)
+		if err != nil {
+			return err
+		}
+		arg.OccurrenceAt = pgtype.Timestamptz{Time: NextOccurrence(schedule, scheduled.OccurrenceAt, now), Valid: true}
+	}
+	return nil
+}
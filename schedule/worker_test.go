@@ -0,0 +1,171 @@
+package schedule
+
+import (
+	"context"
+	mockdb "github.com/fayca121/simplebank/db/mock"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRunDue(t *testing.T) {
+	now := date(2024, time.March, 1, 9, 2)
+	scheduled := db.ScheduledTransfer{
+		ID:            1,
+		Username:      "alice",
+		FromAccountID: 10,
+		ToAccountID:   20,
+		Amount:        500,
+		Schedule:      "0 9 1 * *",
+		Status:        db.ScheduledTransferActive,
+		OccurrenceAt:  date(2024, time.March, 1, 9, 0),
+		NextRunAt:     now.Add(claimLease),
+	}
+	next := date(2024, time.April, 1, 9, 0)
+	claimed := db.AdvanceScheduledTransferParams{
+		ID:                  scheduled.ID,
+		ClaimedOccurrenceAt: scheduled.OccurrenceAt,
+		ClaimedNextRunAt:    scheduled.NextRunAt,
+	}
+	advance := func(occurrenceAt, nextRunAt time.Time, attempts int32) db.AdvanceScheduledTransferParams {
+		arg := claimed
+		arg.OccurrenceAt, arg.NextRunAt, arg.Attempts = occurrenceAt, nextRunAt, attempts
+		return arg
+	}
+
+	testCases := []struct {
+		name      string
+		scheduled db.ScheduledTransfer
+		transfer  error
+		advance   db.AdvanceScheduledTransferParams
+	}{
+		{
+			name:      "Success",
+			scheduled: scheduled,
+			advance:   advance(next, next, 0),
+		},
+		{
+			name:      "Retry",
+			scheduled: scheduled,
+			transfer:  db.ErrInsufficientFunds,
+			advance:   advance(scheduled.OccurrenceAt, now.Add(RetryBackoff), 1),
+		},
+		{
+			name: "SkipAfterLastAttempt",
+			scheduled: func() db.ScheduledTransfer {
+				last := scheduled
+				last.Attempts = MaxAttempts - 1
+				return last
+			}(),
+			transfer: db.ErrInsufficientFunds,
+			advance:  advance(next, next, 0),
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+
+			store.EXPECT().
+				ClaimDueScheduledTransfers(gomock.Any(), gomock.Eq(db.ClaimDueScheduledTransfersParams{
+					LeaseUntil: now.Add(claimLease),
+					DueAt:      now,
+					Limit:      batchSize,
+				})).
+				Times(1).
+				Return([]db.ScheduledTransfer{tc.scheduled}, nil)
+			store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParam{
+					FromAccountID:  scheduled.FromAccountID,
+					ToAccountID:    scheduled.ToAccountID,
+					Amount:         scheduled.Amount,
+					Username:       scheduled.Username,
+					IdempotencyKey: IdempotencyKey(scheduled),
+				})).
+				Times(1).
+				Return(db.TransferTxResult{Transfer: db.Transfer{ID: 99}}, tc.transfer)
+
+			run := db.CreateScheduledTransferRunParams{
+				ScheduledTransferID: scheduled.ID,
+				OccurrenceAt:        scheduled.OccurrenceAt,
+				Attempt:             tc.scheduled.Attempts + 1,
+			}
+			if tc.transfer == nil {
+				run.TransferID = pgtype.Int8{Int64: 99, Valid: true}
+			} else {
+				run.Error = pgtype.Text{String: tc.transfer.Error(), Valid: true}
+			}
+			store.EXPECT().
+				RecordScheduledTransferRunTx(gomock.Any(), gomock.Eq(db.RecordScheduledTransferRunTxParam{Run: run, Advance: tc.advance})).
+				Times(1).
+				Return(db.RecordScheduledTransferRunTxResult{Run: db.ScheduledTransferRun{ID: 5}}, nil)
+
+			runs, err := RunDue(context.Background(), store, now)
+			require.NoError(t, err)
+			require.Len(t, runs, 1)
+		})
+	}
+}
+
+func TestRunDueDeletedDuringRun(t *testing.T) {
+	now := date(2024, time.March, 1, 9, 2)
+	scheduled := db.ScheduledTransfer{
+		ID:           1,
+		Schedule:     "0 9 1 * *",
+		Status:       db.ScheduledTransferActive,
+		OccurrenceAt: date(2024, time.March, 1, 9, 0),
+		NextRunAt:    now.Add(claimLease),
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).Times(1).Return([]db.ScheduledTransfer{scheduled}, nil)
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{Transfer: db.Transfer{ID: 99}}, nil)
+	store.EXPECT().RecordScheduledTransferRunTx(gomock.Any(), gomock.Any()).Times(1).
+		Return(db.RecordScheduledTransferRunTxResult{}, db.ErrScheduledTransferDeleted)
+
+	runs, err := RunDue(context.Background(), store, now)
+	require.NoError(t, err)
+	require.Empty(t, runs)
+}
+
+func TestNextOccurrenceSkipsMissed(t *testing.T) {
+	schedule, err := Parse("@every 24h")
+	require.NoError(t, err)
+
+	occurrence := date(2024, time.March, 1, 9, 0)
+	now := date(2024, time.March, 4, 12, 0)
+	require.Equal(t, date(2024, time.March, 5, 9, 0), NextOccurrence(schedule, occurrence, now))
+}
+
+func TestReschedule(t *testing.T) {
+	now := date(2024, time.March, 10, 12, 0)
+	paused := db.ScheduledTransfer{
+		ID:           1,
+		Schedule:     "0 9 1 * *",
+		Status:       db.ScheduledTransferPaused,
+		OccurrenceAt: date(2024, time.March, 1, 9, 0),
+	}
+
+	// resuming skips the occurrence missed while paused
+	arg := db.UpdateScheduledTransferParams{ID: 1, Status: pgtype.Text{String: db.ScheduledTransferActive, Valid: true}}
+	require.NoError(t, Reschedule(paused, &arg, now))
+	require.Equal(t, pgtype.Timestamptz{Time: date(2024, time.April, 1, 9, 0), Valid: true}, arg.OccurrenceAt)
+
+	// a new schedule starts after now
+	arg = db.UpdateScheduledTransferParams{ID: 1, Schedule: pgtype.Text{String: "0 9 15 * *", Valid: true}}
+	require.NoError(t, Reschedule(paused, &arg, now))
+	require.Equal(t, pgtype.Timestamptz{Time: date(2024, time.March, 15, 9, 0), Valid: true}, arg.OccurrenceAt)
+
+	// the amount alone keeps the current occurrence
+	arg = db.UpdateScheduledTransferParams{ID: 1, Amount: pgtype.Int8{Int64: 10, Valid: true}}
+	require.NoError(t, Reschedule(paused, &arg, now))
+	require.False(t, arg.OccurrenceAt.Valid)
+}
@@ -0,0 +1,79 @@
+package statement
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// writeCSV writes a header row, the opening balance, one row per entry and the closing balance
+func writeCSV(w io.Writer, statement *Statement) error {
+	writer := csv.NewWriter(w)
+	records := [][]string{
+		{"date", "entry_id", "description", "amount", "balance", "currency"},
+		{statement.From.Format(time.RFC3339), "", "opening balance", "",
+			formatAmount(statement.OpeningBalance, statement.Currency), statement.Currency},
+	}
+	for _, line := range statement.Lines {
+		records = append(records, []string{
+			line.CreatedAt.Format(time.RFC3339),
+			strconv.FormatInt(line.EntryID, 10),
+			description(line),
+			formatAmount(line.Amount, statement.Currency),
+			formatAmount(line.Balance, statement.Currency),
+			statement.Currency,
+		})
+	}
+	records = append(records, []string{statement.To.Format(time.RFC3339), "", "closing balance", "",
+		formatAmount(statement.ClosingBalance, statement.Currency), statement.Currency})
+
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func description(line Line) string {
+	if line.Amount < 0 {
+		return "debit"
+	}
+	return "credit"
+}
+
+type jsonStatement struct {
+	AccountID      int64      `json:"account_id"`
+	Owner          string     `json:"owner"`
+	Currency       string     `json:"currency"`
+	From           time.Time  `json:"from"`
+	To             time.Time  `json:"to"`
+	OpeningBalance int64      `json:"opening_balance"`
+	ClosingBalance int64      `json:"closing_balance"`
+	Lines          []jsonLine `json:"lines"`
+}
+
+type jsonLine struct {
+	EntryID   int64     `json:"entry_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Amount    int64     `json:"amount"`
+	Balance   int64     `json:"balance"`
+}
+
+// writeJSON writes the statement with the amounts in minor units, like the rest of the API
+func writeJSON(w io.Writer, statement *Statement) error {
+	body := jsonStatement{
+		AccountID:      statement.AccountID,
+		Owner:          statement.Owner,
+		Currency:       statement.Currency,
+		From:           statement.From,
+		To:             statement.To,
+		OpeningBalance: statement.OpeningBalance,
+		ClosingBalance: statement.ClosingBalance,
+		Lines:          make([]jsonLine, 0, len(statement.Lines)),
+	}
+	for _, line := range statement.Lines {
+		body.Lines = append(body.Lines, jsonLine(line))
+	}
+	return json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,123 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	pdfLinesPerPage = 60
+	pdfFontSize     = 9
+	pdfLeading      = 12
+	// A4 in points
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 50
+)
+
+// pdfWriter writes the objects of a PDF file and keeps their offsets for the cross-reference table
+type pdfWriter struct {
+	w       io.Writer
+	offset  int
+	offsets []int
+	err     error
+}
+
+func (pdf *pdfWriter) printf(format string, args ...any) {
+	if pdf.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(pdf.w, format, args...)
+	pdf.offset += n
+	pdf.err = err
+}
+
+// object writes the next object, objects are numbered from 1 in the order they are written
+func (pdf *pdfWriter) object(body string) {
+	pdf.offsets = append(pdf.offsets, pdf.offset)
+	pdf.printf("%d 0 obj\n%s\nendobj\n", len(pdf.offsets), body)
+}
+
+// writePDF writes a plain text PDF of the statement in a monospaced font
+func writePDF(w io.Writer, statement *Statement) error {
+	pages := pdfPages(pdfText(statement))
+
+	pdf := &pdfWriter{w: w}
+	pdf.printf("%%PDF-1.4\n")
+
+	// objects 1 to 3 are the catalog, the page tree and the font, then each page is followed by its content
+	kids := make([]string, 0, len(pages))
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 4+2*i))
+	}
+	pdf.object("<< /Type /Catalog /Pages 2 0 R >>")
+	pdf.object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	pdf.object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>")
+	for i, page := range pages {
+		pdf.object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, 5+2*i))
+		content := pdfContent(page)
+		pdf.object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := pdf.offset
+	pdf.printf("xref\n0 %d\n0000000000 65535 f \n", len(pdf.offsets)+1)
+	for _, offset := range pdf.offsets {
+		pdf.printf("%010d 00000 n \n", offset)
+	}
+	pdf.printf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(pdf.offsets)+1, xref)
+	return pdf.err
+}
+
+// pdfText lays the statement out as lines of text
+func pdfText(statement *Statement) []string {
+	text := []string{
+		fmt.Sprintf("Statement of account %d", statement.AccountID),
+		fmt.Sprintf("Owner: %s", statement.Owner),
+		fmt.Sprintf("Period: %s to %s", statement.From.Format(time.RFC3339), statement.To.Format(time.RFC3339)),
+		"",
+		fmt.Sprintf("%-20s %-10s %-6s %15s %15s", "Date", "Entry", "Type", "Amount", "Balance"),
+		fmt.Sprintf("%-20s %-10s %-6s %15s %15s", "", "", "", "Opening", formatAmount(statement.OpeningBalance, statement.Currency)),
+	}
+	for _, line := range statement.Lines {
+		text = append(text, fmt.Sprintf("%-20s %-10d %-6s %15s %15s",
+			line.CreatedAt.UTC().Format("2006-01-02 15:04:05"),
+			line.EntryID,
+			description(line),
+			formatAmount(line.Amount, statement.Currency),
+			formatAmount(line.Balance, statement.Currency)))
+	}
+	return append(text,
+		fmt.Sprintf("%-20s %-10s %-6s %15s %15s", "", "", "", "Closing", formatAmount(statement.ClosingBalance, statement.Currency)),
+		"",
+		fmt.Sprintf("Amounts in %s", statement.Currency))
+}
+
+func pdfPages(text []string) [][]string {
+	var pages [][]string
+	for len(text) > pdfLinesPerPage {
+		pages = append(pages, text[:pdfLinesPerPage])
+		text = text[pdfLinesPerPage:]
+	}
+	return append(pages, text)
+}
+
+// pdfContent is the content stream printing the lines from the top left corner of the page
+func pdfContent(lines []string) string {
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin)
+	for _, line := range lines {
+		fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscape(line))
+	}
+	content.WriteString("ET")
+	return content.String()
+}
+
+var pdfEscaper = strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+
+func pdfEscape(text string) string {
+	return pdfEscaper.Replace(text)
+}
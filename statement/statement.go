@@ -0,0 +1,160 @@
+package statement
+
+import (
+	"context"
+	"fmt"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/util"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Statement lists the entries of an account over the [From, To) period with the balance after each of them
+type Statement struct {
+	AccountID      int64
+	Owner          string
+	Currency       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance int64
+	ClosingBalance int64
+	Lines          []Line
+}
+
+type Line struct {
+	EntryID   int64
+	CreatedAt time.Time
+	Amount    int64
+	// Balance is the balance of the account right after the entry
+	Balance int64
+}
+
+// New builds the statement from the balance at the start of the period and the entries of the period,
+// sorted by creation time
+func New(account db.Account, from time.Time, to time.Time, openingBalance int64, entries []db.Entry) *Statement {
+	statement := &Statement{
+		AccountID:      account.ID,
+		Owner:          account.Owner,
+		Currency:       account.Currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: openingBalance,
+		Lines:          make([]Line, 0, len(entries)),
+	}
+	balance := openingBalance
+	for _, entry := range entries {
+		balance += entry.Amount
+		statement.Lines = append(statement.Lines, Line{
+			EntryID:   entry.ID,
+			CreatedAt: entry.CreatedAt,
+			Amount:    entry.Amount,
+			Balance:   balance,
+		})
+	}
+	statement.ClosingBalance = balance
+	return statement
+}
+
+// Generate loads the statement of the account over the [from, to) period
+func Generate(ctx context.Context, q db.Querier, account db.Account, from time.Time, to time.Time) (*Statement, error) {
+	openingBalance, err := q.GetOpeningBalance(ctx, db.GetOpeningBalanceParams{
+		Since:     from,
+		AccountID: account.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get opening balance: %w", err)
+	}
+
+	entries, err := q.ListEntriesBetween(ctx, db.ListEntriesBetweenParams{
+		AccountID: account.ID,
+		FromTime:  from,
+		ToTime:    to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list entries: %w", err)
+	}
+
+	return New(account, from, to, openingBalance, entries), nil
+}
+
+// Filename is the name suggested to save the statement in the format
+func (statement *Statement) Filename(format Format) string {
+	return fmt.Sprintf("statement-%d-%s-%s.%s", statement.AccountID,
+		statement.From.Format(dateLayout), statement.To.Format(dateLayout), format)
+}
+
+const dateLayout = "2006-01-02"
+
+// Format is the file format of a statement
+type Format string
+
+const (
+	CSV  Format = "csv"
+	JSON Format = "json"
+	PDF  Format = "pdf"
+)
+
+// ParseFormat parses a format name, the empty name stands for CSV
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(value)); format {
+	case "":
+		return CSV, nil
+	case CSV, JSON, PDF:
+		return format, nil
+	}
+	return "", fmt.Errorf("unsupported statement format %q", value)
+}
+
+func (format Format) ContentType() string {
+	switch format {
+	case JSON:
+		return "application/json"
+	case PDF:
+		return "application/pdf"
+	}
+	return "text/csv"
+}
+
+// Write encodes the statement in the format
+func Write(w io.Writer, statement *Statement, format Format) error {
+	switch format {
+	case CSV:
+		return writeCSV(w, statement)
+	case JSON:
+		return writeJSON(w, statement)
+	case PDF:
+		return writePDF(w, statement)
+	}
+	return fmt.Errorf("unsupported statement format %q", format)
+}
+
+// formatAmount renders an amount in minor units as a decimal number of the currency
+func formatAmount(amount int64, currency string) string {
+	minorUnits := int32(2)
+	if c, ok := util.LookupCurrency(currency); ok {
+		minorUnits = c.MinorUnits
+	}
+	if minorUnits <= 0 {
+		return strconv.FormatInt(amount, 10)
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+	}
+	digits := strconv.FormatUint(absolute(amount), 10)
+	if len(digits) <= int(minorUnits) {
+		digits = strings.Repeat("0", int(minorUnits)-len(digits)+1) + digits
+	}
+	point := len(digits) - int(minorUnits)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+func absolute(amount int64) uint64 {
+	if amount < 0 {
+		return uint64(-amount)
+	}
+	return uint64(amount)
+}
@@ -0,0 +1,141 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/fayca121/simplebank/db/mock"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func randomStatement() *Statement {
+	account := db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    util.RandomOwner(),
+		Currency: util.USD,
+	}
+	from := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	entries := []db.Entry{
+		{ID: 1, AccountID: account.ID, Amount: 2500, CreatedAt: from.Add(time.Hour)},
+		{ID: 2, AccountID: account.ID, Amount: -1000, CreatedAt: from.Add(2 * time.Hour)},
+		{ID: 3, AccountID: account.ID, Amount: 5, CreatedAt: from.Add(3 * time.Hour)},
+	}
+	return New(account, from, to, 10000, entries)
+}
+
+func TestNew(t *testing.T) {
+	statement := randomStatement()
+	require.Len(t, statement.Lines, 3)
+	require.Equal(t, int64(12500), statement.Lines[0].Balance)
+	require.Equal(t, int64(11500), statement.Lines[1].Balance)
+	require.Equal(t, int64(11505), statement.Lines[2].Balance)
+	require.Equal(t, int64(11505), statement.ClosingBalance)
+}
+
+func TestGenerate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+
+	account := db.Account{ID: util.RandomInt(1, 1000), Owner: util.RandomOwner(), Currency: util.EUR}
+	from := time.Now().AddDate(0, -1, 0)
+	to := time.Now()
+	entries := []db.Entry{{ID: 1, AccountID: account.ID, Amount: -300, CreatedAt: from}}
+
+	store.EXPECT().
+		GetOpeningBalance(gomock.Any(), gomock.Eq(db.GetOpeningBalanceParams{Since: from, AccountID: account.ID})).
+		Times(1).
+		Return(int64(1000), nil)
+	store.EXPECT().
+		ListEntriesBetween(gomock.Any(), gomock.Eq(db.ListEntriesBetweenParams{AccountID: account.ID, FromTime: from, ToTime: to})).
+		Times(1).
+		Return(entries, nil)
+
+	statement, err := Generate(context.Background(), store, account, from, to)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), statement.OpeningBalance)
+	require.Equal(t, int64(700), statement.ClosingBalance)
+}
+
+func TestParseFormat(t *testing.T) {
+	for value, expected := range map[string]Format{"": CSV, "csv": CSV, "JSON": JSON, "pdf": PDF} {
+		format, err := ParseFormat(value)
+		require.NoError(t, err)
+		require.Equal(t, expected, format)
+	}
+	_, err := ParseFormat("xlsx")
+	require.Error(t, err)
+}
+
+func TestFormatAmount(t *testing.T) {
+	require.Equal(t, "0.00", formatAmount(0, util.USD))
+	require.Equal(t, "0.05", formatAmount(5, util.USD))
+	require.Equal(t, "-10.00", formatAmount(-1000, util.USD))
+	require.Equal(t, "1234.56", formatAmount(123456, util.USD))
+}
+
+func TestWriteCSV(t *testing.T) {
+	statement := randomStatement()
+	var buffer bytes.Buffer
+	require.NoError(t, Write(&buffer, statement, CSV))
+
+	records, err := csv.NewReader(&buffer).ReadAll()
+	require.NoError(t, err)
+	// header, opening balance, entries and closing balance
+	require.Len(t, records, len(statement.Lines)+3)
+	require.Equal(t, "100.00", records[1][4])
+	require.Equal(t, "-10.00", records[3][3])
+	require.Equal(t, "115.05", records[len(records)-1][4])
+}
+
+func TestWriteJSON(t *testing.T) {
+	statement := randomStatement()
+	var buffer bytes.Buffer
+	require.NoError(t, Write(&buffer, statement, JSON))
+
+	var body jsonStatement
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &body))
+	require.Equal(t, statement.AccountID, body.AccountID)
+	require.Equal(t, statement.OpeningBalance, body.OpeningBalance)
+	require.Equal(t, statement.ClosingBalance, body.ClosingBalance)
+	require.Len(t, body.Lines, len(statement.Lines))
+}
+
+func TestWritePDF(t *testing.T) {
+	statement := randomStatement()
+	// enough entries for several pages
+	for i := 0; i < 2*pdfLinesPerPage; i++ {
+		statement.Lines = append(statement.Lines, statement.Lines[0])
+	}
+	var buffer bytes.Buffer
+	require.NoError(t, Write(&buffer, statement, PDF))
+
+	content := buffer.Bytes()
+	require.True(t, bytes.HasPrefix(content, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(content, []byte("%%EOF\n")))
+	require.Contains(t, buffer.String(), "/Count 3")
+
+	// every offset of the cross-reference table points to its object
+	xref := regexp.MustCompile(`(?m)^(\d{10}) 00000 n $`).FindAllSubmatch(content, -1)
+	require.Len(t, xref, 3+2*3)
+	for i, match := range xref {
+		offset, err := strconv.Atoi(string(match[1]))
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(content[offset:], []byte(fmt.Sprintf("%d 0 obj", i+1))))
+	}
+}
+
+func TestFilename(t *testing.T) {
+	statement := randomStatement()
+	require.Equal(t, fmt.Sprintf("statement-%d-2026-09-01-2026-10-01.pdf", statement.AccountID), statement.Filename(PDF))
+}
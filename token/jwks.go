@@ -0,0 +1,70 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// JWK is the public part of a signing key, as described by RFC 7517
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is the key set published to the services verifying our tokens
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeySetPublisher is implemented by the makers whose tokens can be verified offline with public keys
+type KeySetPublisher interface {
+	KeySet() (JWKS, error)
+}
+
+// keySet returns the public keys of the keyring, algorithm is the "alg" of every key
+func (keyring *SigningKeyring) keySet(algorithm string) (JWKS, error) {
+	set := JWKS{Keys: make([]JWK, 0, len(keyring.keys))}
+	for _, id := range keyring.ids() {
+		jwk, err := publicJWK(id, algorithm, keyring.keys[id].Public())
+		if err != nil {
+			return JWKS{}, err
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set, nil
+}
+
+func publicJWK(id string, algorithm string, key crypto.PublicKey) (JWK, error) {
+	encode := base64.RawURLEncoding.EncodeToString
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		return JWK{
+			KeyType:   "OKP",
+			KeyID:     id,
+			Use:       "sig",
+			Algorithm: algorithm,
+			Curve:     "Ed25519",
+			X:         encode(key),
+		}, nil
+	case *rsa.PublicKey:
+		return JWK{
+			KeyType:   "RSA",
+			KeyID:     id,
+			Use:       "sig",
+			Algorithm: algorithm,
+			N:         encode(key.N.Bytes()),
+			E:         encode(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	}
+	return JWK{}, fmt.Errorf("unsupported key type %T", key)
+}
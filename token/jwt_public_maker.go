@@ -0,0 +1,87 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+	"github.com/fayca121/simplebank/util"
+	"github.com/golang-jwt/jwt/v5"
+	"time"
+)
+
+// JWTPublicMaker signs tokens with EdDSA (Ed25519 keys) or RS256 (RSA keys),
+// they can be verified with the public keys only
+type JWTPublicMaker struct {
+	keyring *SigningKeyring
+	method  jwt.SigningMethod
+}
+
+// NewJWTPublicMaker creates a maker for algorithm "EdDSA" or "RS256", every key of the keyring must match it
+func NewJWTPublicMaker(keyring *SigningKeyring, algorithm string) (*JWTPublicMaker, error) {
+	if keyring == nil {
+		return nil, fmt.Errorf("missing keyring")
+	}
+	var method jwt.SigningMethod
+	for _, id := range keyring.ids() {
+		var ok bool
+		switch algorithm {
+		case jwt.SigningMethodEdDSA.Alg():
+			method = jwt.SigningMethodEdDSA
+			_, ok = keyring.keys[id].(ed25519.PrivateKey)
+		case jwt.SigningMethodRS256.Alg():
+			method = jwt.SigningMethodRS256
+			_, ok = keyring.keys[id].(*rsa.PrivateKey)
+		default:
+			return nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+		}
+		if !ok {
+			return nil, fmt.Errorf("key %q cannot be used with %s", id, algorithm)
+		}
+	}
+	return &JWTPublicMaker{
+		keyring: keyring,
+		method:  method,
+	}, nil
+}
+
+func (maker *JWTPublicMaker) CreateToken(username string, role util.Role, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayLoad(username, role, duration)
+	if err != nil {
+		return "", nil, err
+	}
+
+	keyID, key := maker.keyring.current()
+	jwtToken := jwt.NewWithClaims(maker.method, payLoadToJWTClaims(payload))
+	jwtToken.Header["kid"] = keyID
+	token, err := jwtToken.SignedString(key)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, payload, nil
+}
+
+func (maker *JWTPublicMaker) VerifyToken(token string) (*Payload, error) {
+	t, err := jwt.ParseWithClaims(token, &jwtClaims{},
+		func(token *jwt.Token) (interface{}, error) {
+			keyID, _ := token.Header["kid"].(string)
+			key, ok := maker.keyring.key(keyID)
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, keyID)
+			}
+			return key.Public(), nil
+		}, jwt.WithValidMethods([]string{maker.method.Alg()}))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !t.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	return jwtClaimsToPayLoad(t.Claims)
+}
+
+func (maker *JWTPublicMaker) KeySet() (JWKS, error) {
+	return maker.keyring.keySet(maker.method.Alg())
+}
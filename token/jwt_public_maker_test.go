@@ -0,0 +1,123 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/fayca121/simplebank/util"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJWTPublicMaker(t *testing.T) {
+	ed25519Keyring, err := GenerateEd25519Keyring()
+	require.NoError(t, err)
+	rsaKeyring, err := GenerateRSAKeyring()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		keyring   *SigningKeyring
+		algorithm string
+		keyType   string
+	}{
+		{name: "EdDSA", keyring: ed25519Keyring, algorithm: "EdDSA", keyType: "OKP"},
+		{name: "RS256", keyring: rsaKeyring, algorithm: "RS256", keyType: "RSA"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			maker, err := NewJWTPublicMaker(tc.keyring, tc.algorithm)
+			require.NoError(t, err)
+			username := util.RandomOwner()
+
+			token, _, err := maker.CreateToken(username, util.DepositorRole, time.Minute)
+			require.NoError(t, err)
+
+			payload, err := maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, username, payload.Username)
+
+			keySet, err := maker.KeySet()
+			require.NoError(t, err)
+			require.Len(t, keySet.Keys, 1)
+			require.Equal(t, tc.keyType, keySet.Keys[0].KeyType)
+			require.Equal(t, tc.algorithm, keySet.Keys[0].Algorithm)
+			require.Equal(t, defaultKeyID, keySet.Keys[0].KeyID)
+
+			expiredToken, _, err := maker.CreateToken(username, util.DepositorRole, -time.Minute)
+			require.NoError(t, err)
+			_, err = maker.VerifyToken(expiredToken)
+			require.ErrorIs(t, err, jwt.ErrTokenExpired)
+		})
+	}
+}
+
+func TestJWTPublicMakerRejectsOtherAlgorithms(t *testing.T) {
+	keyring, err := GenerateEd25519Keyring()
+	require.NoError(t, err)
+	maker, err := NewJWTPublicMaker(keyring, "EdDSA")
+	require.NoError(t, err)
+
+	// a symmetric token is not accepted, whatever its key
+	symmetricMaker, err := NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+	token, _, err := symmetricMaker.CreateToken(util.RandomOwner(), util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(token)
+	require.Error(t, err)
+
+	_, err = NewJWTPublicMaker(keyring, "RS256")
+	require.Error(t, err)
+	_, err = NewJWTPublicMaker(keyring, "HS256")
+	require.Error(t, err)
+}
+
+func TestLoadSigningKeyring(t *testing.T) {
+	dir := t.TempDir()
+	writeKey := func(name string, key crypto.Signer) string {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+		return path
+	}
+	_, key1, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, key2, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	path1 := writeKey("k1.pem", key1)
+	path2 := writeKey("k2.pem", key2)
+
+	oldKeyring, err := LoadSigningKeyring(fmt.Sprintf("k1=%s", path1), "")
+	require.NoError(t, err)
+	oldMaker, err := NewJWTPublicMaker(oldKeyring, "EdDSA")
+	require.NoError(t, err)
+	oldToken, _, err := oldMaker.CreateToken(util.RandomOwner(), util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	keyring, err := LoadSigningKeyring(fmt.Sprintf("k1=%s,k2=%s", path1, path2), "k2")
+	require.NoError(t, err)
+	maker, err := NewJWTPublicMaker(keyring, "EdDSA")
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	keySet, err := maker.KeySet()
+	require.NoError(t, err)
+	require.Len(t, keySet.Keys, 2)
+	require.Equal(t, "k1", keySet.Keys[0].KeyID)
+	require.Equal(t, "k2", keySet.Keys[1].KeyID)
+
+	_, err = LoadSigningKeyring(fmt.Sprintf("k1=%s", filepath.Join(dir, "missing.pem")), "")
+	require.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package token
+
+import (
+	"aidanwoods.dev/go-paseto"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+// defaultKeyID names the key derived from TOKEN_SYMMETRIC_KEY when no PASETO key is configured
+const defaultKeyID = "default"
+
+// Keyring holds the PASETO keys by key id. New tokens are encrypted with the current key,
+// the other keys are only used to decrypt tokens issued before a rotation.
+type Keyring struct {
+	currentID string
+	keys      map[string]paseto.V4SymmetricKey
+}
+
+func NewKeyring(currentID string, keys map[string]paseto.V4SymmetricKey) (*Keyring, error) {
+	if _, ok := keys[currentID]; !ok {
+		return nil, fmt.Errorf("current key %q is not in the keyring", currentID)
+	}
+	return &Keyring{
+		currentID: currentID,
+		keys:      keys,
+	}, nil
+}
+
+// GenerateKeyring returns a keyring holding a single random key, tokens don't survive a restart
+func GenerateKeyring() *Keyring {
+	return &Keyring{
+		currentID: defaultKeyID,
+		keys:      map[string]paseto.V4SymmetricKey{defaultKeyID: paseto.NewV4SymmetricKey()},
+	}
+}
+
+// ParseKeyring reads keys written as "id1=hex,id2=hex", each key being 32 bytes hex encoded.
+// currentID defaults to the first key of the list.
+func ParseKeyring(spec string, currentID string) (*Keyring, error) {
+	keys := make(map[string]paseto.V4SymmetricKey)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, hexKey, found := strings.Cut(entry, "=")
+		if !found || id == "" {
+			return nil, fmt.Errorf("invalid key entry %q, expected id=hex", entry)
+		}
+		if _, ok := keys[id]; ok {
+			return nil, fmt.Errorf("duplicated key id %q", id)
+		}
+		key, err := paseto.V4SymmetricKeyFromHex(hexKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", id, err)
+		}
+		keys[id] = key
+		if currentID == "" {
+			currentID = id
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no key in keyring")
+	}
+	return NewKeyring(currentID, keys)
+}
+
+// DeriveKeyring returns a keyring holding a single key derived from secret,
+// so TOKEN_SYMMETRIC_KEY can be reused when no PASETO key is configured
+func DeriveKeyring(secret string) (*Keyring, error) {
+	if len(secret) < minSecretKeySize {
+		return nil, fmt.Errorf("invalid key size: must be at least %d characters", minSecretKeySize)
+	}
+	sum := sha256.Sum256([]byte("simplebank paseto v4.local " + secret))
+	key, err := paseto.V4SymmetricKeyFromBytes(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyring(defaultKeyID, map[string]paseto.V4SymmetricKey{defaultKeyID: key})
+}
+
+func (keyring *Keyring) current() (string, paseto.V4SymmetricKey) {
+	return keyring.currentID, keyring.keys[keyring.currentID]
+}
+
+func (keyring *Keyring) key(id string) (paseto.V4SymmetricKey, bool) {
+	key, ok := keyring.keys[id]
+	return key, ok
+}
+
+// LoadKeyring parses the configured PASETO keys, falling back to a key derived from
+// secret when none is configured
+func LoadKeyring(keys string, currentID string, secret string) (*Keyring, error) {
+	if strings.TrimSpace(keys) == "" {
+		return DeriveKeyring(secret)
+	}
+	return ParseKeyring(keys, currentID)
+}
@@ -0,0 +1,85 @@
+package token
+
+import (
+	"aidanwoods.dev/go-paseto"
+	"fmt"
+	"github.com/fayca121/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestKeyringRotation(t *testing.T) {
+	key1 := paseto.NewV4SymmetricKey().ExportHex()
+	key2 := paseto.NewV4SymmetricKey().ExportHex()
+
+	keyring1, err := ParseKeyring(fmt.Sprintf("k1=%s", key1), "")
+	require.NoError(t, err)
+	maker1, err := NewPasetoMaker(keyring1)
+	require.NoError(t, err)
+
+	oldToken, _, err := maker1.CreateToken(util.RandomOwner(), util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	// k2 becomes the current key, k1 is kept to verify the tokens it signed
+	keyring2, err := ParseKeyring(fmt.Sprintf("k1=%s, k2=%s", key1, key2), "k2")
+	require.NoError(t, err)
+	maker2, err := NewPasetoMaker(keyring2)
+	require.NoError(t, err)
+
+	payload, err := maker2.VerifyToken(oldToken)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	newToken, _, err := maker2.CreateToken(util.RandomOwner(), util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+	_, err = maker1.VerifyToken(newToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// once k1 is removed its tokens are rejected
+	keyring3, err := ParseKeyring(fmt.Sprintf("k2=%s", key2), "")
+	require.NoError(t, err)
+	maker3, err := NewPasetoMaker(keyring3)
+	require.NoError(t, err)
+	_, err = maker3.VerifyToken(oldToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	_, err = maker3.VerifyToken(newToken)
+	require.NoError(t, err)
+}
+
+func TestDeriveKeyring(t *testing.T) {
+	secret := util.RandomString(32)
+
+	// two replicas sharing the secret verify each other's tokens
+	keyring1, err := LoadKeyring("", "", secret)
+	require.NoError(t, err)
+	keyring2, err := LoadKeyring("", "", secret)
+	require.NoError(t, err)
+	maker1, err := NewPasetoMaker(keyring1)
+	require.NoError(t, err)
+	maker2, err := NewPasetoMaker(keyring2)
+	require.NoError(t, err)
+
+	token, _, err := maker1.CreateToken(util.RandomOwner(), util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+	_, err = maker2.VerifyToken(token)
+	require.NoError(t, err)
+
+	_, err = DeriveKeyring(util.RandomString(10))
+	require.Error(t, err)
+}
+
+func TestParseKeyringErrors(t *testing.T) {
+	key := paseto.NewV4SymmetricKey().ExportHex()
+
+	_, err := ParseKeyring("", "")
+	require.Error(t, err)
+	_, err = ParseKeyring("k1", "")
+	require.Error(t, err)
+	_, err = ParseKeyring("k1=not-hex", "")
+	require.Error(t, err)
+	_, err = ParseKeyring(fmt.Sprintf("k1=%s,k1=%s", key, key), "")
+	require.Error(t, err)
+	_, err = ParseKeyring(fmt.Sprintf("k1=%s", key), "k2")
+	require.Error(t, err)
+}
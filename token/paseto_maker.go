@@ -2,17 +2,27 @@ package token
 
 import (
 	"aidanwoods.dev/go-paseto"
+	"encoding/json"
+	"fmt"
 	"github.com/fayca121/simplebank/util"
 	"time"
 )
 
 type PasetoMaker struct {
-	symmetricKey paseto.V4SymmetricKey
+	keyring *Keyring
 }
 
-func NewPasetoMaker() (*PasetoMaker, error) {
+// pasetoFooter carries the id of the key used to encrypt the token, the footer is authenticated but not encrypted
+type pasetoFooter struct {
+	KeyID string `json:"kid"`
+}
+
+func NewPasetoMaker(keyring *Keyring) (*PasetoMaker, error) {
+	if keyring == nil {
+		return nil, fmt.Errorf("missing keyring")
+	}
 	return &PasetoMaker{
-		symmetricKey: paseto.NewV4SymmetricKey(),
+		keyring: keyring,
 	}, nil
 }
 
@@ -22,7 +32,34 @@ func (p *PasetoMaker) CreateToken(username string, role util.Role, duration time
 		return "", nil, err
 	}
 
-	// create Token
+	keyID, key := p.keyring.current()
+	token, err := newPasetoToken(payload, keyID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	encrypted := token.V4Encrypt(key, nil)
+	return encrypted, payload, nil
+}
+
+func (p *PasetoMaker) VerifyToken(token string) (*Payload, error) {
+	keyID, err := pasetoKeyID(paseto.V4Local, token)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := p.keyring.key(keyID)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, keyID)
+	}
+	verifiedToken, err := paseto.NewParser().ParseV4Local(key, token, nil)
+	if err != nil {
+		return nil, err
+	}
+	return pasetoTokenToPayload(verifiedToken)
+}
+
+// newPasetoToken sets the claims of the payload and the id of the key in the footer
+func newPasetoToken(payload *Payload, keyID string) (*paseto.Token, error) {
 	token := paseto.NewToken()
 	token.SetSubject(payload.Username)
 	token.SetIssuer(payload.Issuer)
@@ -31,15 +68,28 @@ func (p *PasetoMaker) CreateToken(username string, role util.Role, duration time
 	token.SetString("ID", payload.ID)
 	token.SetString("role", payload.Role)
 
-	encrypted := token.V4Encrypt(p.symmetricKey, nil)
-	return encrypted, payload, nil
+	footer, err := json.Marshal(pasetoFooter{KeyID: keyID})
+	if err != nil {
+		return nil, err
+	}
+	token.SetFooter(footer)
+	return &token, nil
 }
 
-func (p *PasetoMaker) VerifyToken(token string) (*Payload, error) {
-	verifiedToken, err := paseto.NewParser().ParseV4Local(p.symmetricKey, token, nil)
+// pasetoKeyID reads the id of the key from the footer, before the token is verified
+func pasetoKeyID(protocol paseto.Protocol, token string) (string, error) {
+	rawFooter, err := paseto.NewParser().UnsafeParseFooter(protocol, token)
 	if err != nil {
-		return nil, err
+		return "", ErrInvalidToken
 	}
+	var footer pasetoFooter
+	if err = json.Unmarshal(rawFooter, &footer); err != nil {
+		return "", ErrInvalidToken
+	}
+	return footer.KeyID, nil
+}
+
+func pasetoTokenToPayload(verifiedToken *paseto.Token) (*Payload, error) {
 	issuer, err := verifiedToken.GetIssuer()
 	if err != nil {
 		return nil, err
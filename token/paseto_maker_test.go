@@ -8,7 +8,7 @@ import (
 )
 
 func TestPasetoMaker(t *testing.T) {
-	maker, err := NewPasetoMaker()
+	maker, err := NewPasetoMaker(GenerateKeyring())
 	require.NoError(t, err)
 	username := util.RandomOwner()
 	duration := time.Minute
@@ -30,7 +30,7 @@ func TestPasetoMaker(t *testing.T) {
 }
 
 func TestExpiredPasetoToken(t *testing.T) {
-	maker, err := NewPasetoMaker()
+	maker, err := NewPasetoMaker(GenerateKeyring())
 	require.NoError(t, err)
 	token, payload, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, -time.Minute)
 	require.NoError(t, err)
@@ -0,0 +1,75 @@
+package token
+
+import (
+	"aidanwoods.dev/go-paseto"
+	"crypto/ed25519"
+	"fmt"
+	"github.com/fayca121/simplebank/util"
+	"time"
+)
+
+// pasetoPublicAlgorithm is the "alg" published for the PASETO keys, JOSE doesn't define one
+const pasetoPublicAlgorithm = "v4.public"
+
+// PasetoPublicMaker signs v4.public tokens with Ed25519 keys, they can be verified with the public keys only
+type PasetoPublicMaker struct {
+	keyring *SigningKeyring
+}
+
+func NewPasetoPublicMaker(keyring *SigningKeyring) (*PasetoPublicMaker, error) {
+	if keyring == nil {
+		return nil, fmt.Errorf("missing keyring")
+	}
+	for _, id := range keyring.ids() {
+		if _, ok := keyring.keys[id].(ed25519.PrivateKey); !ok {
+			return nil, fmt.Errorf("key %q is not an Ed25519 key", id)
+		}
+	}
+	return &PasetoPublicMaker{
+		keyring: keyring,
+	}, nil
+}
+
+func (p *PasetoPublicMaker) CreateToken(username string, role util.Role, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayLoad(username, role, duration)
+	if err != nil {
+		return "", nil, err
+	}
+
+	keyID, signer := p.keyring.current()
+	token, err := newPasetoToken(payload, keyID)
+	if err != nil {
+		return "", nil, err
+	}
+	key, err := paseto.NewV4AsymmetricSecretKeyFromEd25519(signer.(ed25519.PrivateKey))
+	if err != nil {
+		return "", nil, err
+	}
+
+	signed := token.V4Sign(key, nil)
+	return signed, payload, nil
+}
+
+func (p *PasetoPublicMaker) VerifyToken(token string) (*Payload, error) {
+	keyID, err := pasetoKeyID(paseto.V4Public, token)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := p.keyring.key(keyID)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, keyID)
+	}
+	key, err := paseto.NewV4AsymmetricPublicKeyFromEd25519(signer.Public().(ed25519.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	verifiedToken, err := paseto.NewParser().ParseV4Public(key, token, nil)
+	if err != nil {
+		return nil, err
+	}
+	return pasetoTokenToPayload(verifiedToken)
+}
+
+func (p *PasetoPublicMaker) KeySet() (JWKS, error) {
+	return p.keyring.keySet(pasetoPublicAlgorithm)
+}
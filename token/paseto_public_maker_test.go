@@ -0,0 +1,51 @@
+package token
+
+import (
+	"github.com/fayca121/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPasetoPublicMaker(t *testing.T) {
+	keyring, err := GenerateEd25519Keyring()
+	require.NoError(t, err)
+	maker, err := NewPasetoPublicMaker(keyring)
+	require.NoError(t, err)
+	username := util.RandomOwner()
+	issuedAt := time.Now()
+
+	token, payload, err := maker.CreateToken(username, util.BankerRole, time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, util.BankerRole.String(), payload.Role)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+
+	// the key set is enough to verify the token
+	keySet, err := maker.KeySet()
+	require.NoError(t, err)
+	require.Len(t, keySet.Keys, 1)
+	require.Equal(t, "OKP", keySet.Keys[0].KeyType)
+	require.Equal(t, "v4.public", keySet.Keys[0].Algorithm)
+	require.NotEmpty(t, keySet.Keys[0].X)
+
+	// a local token is not accepted
+	localMaker, err := NewPasetoMaker(GenerateKeyring())
+	require.NoError(t, err)
+	localToken, _, err := localMaker.CreateToken(username, util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(localToken)
+	require.Error(t, err)
+}
+
+func TestPasetoPublicMakerRejectsRSAKeys(t *testing.T) {
+	keyring, err := GenerateRSAKeyring()
+	require.NoError(t, err)
+	_, err = NewPasetoPublicMaker(keyring)
+	require.Error(t, err)
+}
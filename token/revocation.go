@@ -0,0 +1,85 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrRevokedToken = errors.New("token has been revoked")
+
+// RevocationStore denies access tokens before they expire
+type RevocationStore interface {
+	// RevokeToken denies the token until it expires
+	RevokeToken(ctx context.Context, payload *Payload) error
+	// RevokeUserTokens denies every token of the user issued before the given time
+	RevokeUserTokens(ctx context.Context, username string, issuedBefore time.Time) error
+	// IsRevoked reports whether the token has been revoked
+	IsRevoked(ctx context.Context, payload *Payload) (bool, error)
+}
+
+// CheckRevocation returns ErrRevokedToken when the token has been revoked
+func CheckRevocation(ctx context.Context, store RevocationStore, payload *Payload) error {
+	revoked, err := store.IsRevoked(ctx, payload)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrRevokedToken
+	}
+	return nil
+}
+
+// MemoryRevocationStore keeps the denylist in the process, it is lost on restart
+// and isn't shared between instances
+type MemoryRevocationStore struct {
+	mu     sync.RWMutex
+	tokens map[string]time.Time
+	users  map[string]time.Time
+}
+
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		tokens: make(map[string]time.Time),
+		users:  make(map[string]time.Time),
+	}
+}
+
+func (store *MemoryRevocationStore) RevokeToken(_ context.Context, payload *Payload) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	now := time.Now()
+	// expired tokens are rejected anyway, no need to remember them
+	for id, expiredAt := range store.tokens {
+		if expiredAt.Before(now) {
+			delete(store.tokens, id)
+		}
+	}
+	store.tokens[payload.ID] = payload.ExpiredAt
+	return nil
+}
+
+func (store *MemoryRevocationStore) RevokeUserTokens(_ context.Context, username string, issuedBefore time.Time) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if issuedBefore.After(store.users[username]) {
+		store.users[username] = issuedBefore
+	}
+	return nil
+}
+
+func (store *MemoryRevocationStore) IsRevoked(_ context.Context, payload *Payload) (bool, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	if _, ok := store.tokens[payload.ID]; ok {
+		return true, nil
+	}
+	return IssuedBefore(payload, store.users[payload.Username]), nil
+}
+
+// IssuedBefore reports whether the token was issued before t. Tokens only carry
+// the issue time to the second, so t is truncated the same way.
+func IssuedBefore(payload *Payload, t time.Time) bool {
+	return payload.IssuedAt.Before(t.Truncate(time.Second))
+}
@@ -0,0 +1,46 @@
+package token
+
+import (
+	"context"
+	"github.com/fayca121/simplebank/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestMemoryRevocationStore(t *testing.T) {
+	maker, err := NewPasetoMaker(GenerateKeyring())
+	require.NoError(t, err)
+	store := NewMemoryRevocationStore()
+	ctx := context.Background()
+
+	username := util.RandomOwner()
+	_, payload1, err := maker.CreateToken(username, util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+	_, payload2, err := maker.CreateToken(username, util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, CheckRevocation(ctx, store, payload1))
+
+	require.NoError(t, store.RevokeToken(ctx, payload1))
+	require.ErrorIs(t, CheckRevocation(ctx, store, payload1), ErrRevokedToken)
+	require.NoError(t, CheckRevocation(ctx, store, payload2))
+
+	// every token issued before the cutoff is denied
+	require.NoError(t, store.RevokeUserTokens(ctx, username, time.Now().Add(time.Second)))
+	require.ErrorIs(t, CheckRevocation(ctx, store, payload2), ErrRevokedToken)
+
+	_, payload3, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, CheckRevocation(ctx, store, payload3))
+}
+
+func TestIssuedBefore(t *testing.T) {
+	issuedAt := time.Now().Truncate(time.Second)
+	payload := &Payload{IssuedAt: issuedAt}
+
+	// a token issued in the same second as the cutoff is still accepted
+	require.False(t, IssuedBefore(payload, issuedAt.Add(500*time.Millisecond)))
+	require.True(t, IssuedBefore(payload, issuedAt.Add(time.Second)))
+	require.False(t, IssuedBefore(payload, time.Time{}))
+}
@@ -0,0 +1,122 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// SigningKeyring holds the private keys of the public-key makers by key id. New tokens are
+// signed with the current key, the other keys are only used to verify tokens issued before a rotation.
+type SigningKeyring struct {
+	currentID string
+	keys      map[string]crypto.Signer
+}
+
+func NewSigningKeyring(currentID string, keys map[string]crypto.Signer) (*SigningKeyring, error) {
+	if _, ok := keys[currentID]; !ok {
+		return nil, fmt.Errorf("current key %q is not in the keyring", currentID)
+	}
+	return &SigningKeyring{
+		currentID: currentID,
+		keys:      keys,
+	}, nil
+}
+
+// GenerateEd25519Keyring returns a keyring holding a single random Ed25519 key
+func GenerateEd25519Keyring() (*SigningKeyring, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewSigningKeyring(defaultKeyID, map[string]crypto.Signer{defaultKeyID: privateKey})
+}
+
+// GenerateRSAKeyring returns a keyring holding a single random 2048 bits RSA key
+func GenerateRSAKeyring() (*SigningKeyring, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return NewSigningKeyring(defaultKeyID, map[string]crypto.Signer{defaultKeyID: privateKey})
+}
+
+// LoadSigningKeyring reads the keys listed as "id1=path,id2=path", each file holding a PKCS #8
+// PEM encoded Ed25519 or RSA private key. currentID defaults to the first key of the list.
+func LoadSigningKeyring(files string, currentID string) (*SigningKeyring, error) {
+	keys := make(map[string]crypto.Signer)
+	for _, entry := range strings.Split(files, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, path, found := strings.Cut(entry, "=")
+		if !found || id == "" {
+			return nil, fmt.Errorf("invalid key entry %q, expected id=path", entry)
+		}
+		if _, ok := keys[id]; ok {
+			return nil, fmt.Errorf("duplicated key id %q", id)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read key %q: %w", id, err)
+		}
+		key, err := ParsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", id, err)
+		}
+		keys[id] = key
+		if currentID == "" {
+			currentID = id
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no key in keyring")
+	}
+	return NewSigningKeyring(currentID, keys)
+}
+
+// ParsePrivateKey decodes a PKCS #8 PEM encoded Ed25519 or RSA private key
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch key := key.(type) {
+	case ed25519.PrivateKey:
+		return key, nil
+	case *rsa.PrivateKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T", key)
+}
+
+func (keyring *SigningKeyring) current() (string, crypto.Signer) {
+	return keyring.currentID, keyring.keys[keyring.currentID]
+}
+
+func (keyring *SigningKeyring) key(id string) (crypto.Signer, bool) {
+	key, ok := keyring.keys[id]
+	return key, ok
+}
+
+// ids returns the key ids sorted, so the published key set is stable
+func (keyring *SigningKeyring) ids() []string {
+	ids := make([]string, 0, len(keyring.keys))
+	for id := range keyring.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,23 @@
+package util
+
+import (
+	"math/bits"
+	"time"
+)
+
+// Backoff returns the delay before the retry following the given failed attempt: base doubles
+// with each attempt and is capped at maxDelay
+func Backoff(attempt int32, base, maxDelay time.Duration) time.Duration {
+	if attempt < 1 {
+		return base
+	}
+	shift := int(attempt - 1)
+	if shift >= 63-bits.Len64(uint64(base)) {
+		return maxDelay
+	}
+	delay := base << shift
+	if delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
@@ -0,0 +1,14 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, time.Minute, Backoff(1, time.Minute, time.Hour))
+	require.Equal(t, 8*time.Minute, Backoff(4, time.Minute, time.Hour))
+	require.Equal(t, time.Hour, Backoff(7, time.Minute, time.Hour))
+	require.Equal(t, time.Hour, Backoff(100, time.Minute, time.Hour))
+}
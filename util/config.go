@@ -12,8 +12,44 @@ type Config struct {
 	HTTPServerAddress    string        `mapstructure:"HTTP_SERVER_ADDRESS"`
 	GRPCServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
 	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	PasetoKeys           string        `mapstructure:"PASETO_KEYS"`
+	PasetoCurrentKeyID   string        `mapstructure:"PASETO_CURRENT_KEY_ID"`
+	TokenAlgorithm       string        `mapstructure:"TOKEN_ALGORITHM"`
+	TokenPrivateKeyFiles string        `mapstructure:"TOKEN_PRIVATE_KEY_FILES"`
+	TokenCurrentKeyID    string        `mapstructure:"TOKEN_CURRENT_KEY_ID"`
 	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
 	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	ExchangeRatesFile    string        `mapstructure:"EXCHANGE_RATES_FILE"`
+	TokenRevocationStore string        `mapstructure:"TOKEN_REVOCATION_STORE"`
+	PageTokenKey         string        `mapstructure:"PAGE_TOKEN_KEY"`
+	// GatewayAddress is the IP the gRPC gateway connects from when it runs apart from the server,
+	// the x-forwarded-for it sets is trusted like the one set by a gateway on the loopback
+	GatewayAddress string `mapstructure:"GATEWAY_ADDRESS"`
+	// ReconciliationInterval is the period of the ledger reconciliation job, zero disables it
+	ReconciliationInterval time.Duration `mapstructure:"RECONCILIATION_INTERVAL"`
+	// SchedulerInterval is the period at which the due scheduled transfers are run, zero disables it
+	SchedulerInterval time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+	// HoldExpiryInterval is the period at which the expired holds of pending transfers are released, zero disables it
+	HoldExpiryInterval time.Duration `mapstructure:"HOLD_EXPIRY_INTERVAL"`
+	// OutboxPublisher selects where the outbox events are published, only "redis" is supported
+	OutboxPublisher string `mapstructure:"OUTBOX_PUBLISHER"`
+	// OutboxRelayInterval is the period at which the outbox events are published, zero disables it
+	OutboxRelayInterval time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"`
+	RedisAddress        string        `mapstructure:"REDIS_ADDRESS"`
+	// OutboxStream is the Redis stream the events are appended to
+	OutboxStream string `mapstructure:"OUTBOX_STREAM"`
+	// TaskWorkerInterval is the period at which the due background tasks are run, zero disables it
+	TaskWorkerInterval time.Duration `mapstructure:"TASK_WORKER_INTERVAL"`
+	// VerifyEmailURL is the endpoint of the links sent to verify the emails of the users
+	VerifyEmailURL string `mapstructure:"VERIFY_EMAIL_URL"`
+	// MailSender selects how the emails are sent: "file" writes them to MailDirectory, "smtp" sends them
+	MailSender    string `mapstructure:"MAIL_SENDER"`
+	MailFrom      string `mapstructure:"MAIL_FROM"`
+	MailDirectory string `mapstructure:"MAIL_DIRECTORY"`
+	SMTPHost      string `mapstructure:"SMTP_HOST"`
+	SMTPPort      int    `mapstructure:"SMTP_PORT"`
+	SMTPUsername  string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword  string `mapstructure:"SMTP_PASSWORD"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
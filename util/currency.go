@@ -1,16 +1,75 @@
 package util
 
+import (
+	"sync"
+)
+
 const (
 	USD = "USD"
 	EUR = "EUR"
 	CAD = "CAD"
 )
 
-func IsSupportedCurrency(currency string) bool {
-	switch currency {
-	case CAD, EUR, USD:
-		return true
-	default:
-		return false
+// Currency describes an ISO 4217 currency. Amounts are stored in minor units,
+// MinorUnits is the number of decimal places of the minor unit (2 for USD, 0 for JPY)
+type Currency struct {
+	Code       string
+	MinorUnits int32
+	Enabled    bool
+}
+
+// CurrencyRegistry is the in-memory copy of the currencies table
+type CurrencyRegistry struct {
+	mu         sync.RWMutex
+	currencies map[string]Currency
+}
+
+func NewCurrencyRegistry(currencies ...Currency) *CurrencyRegistry {
+	registry := &CurrencyRegistry{}
+	registry.Load(currencies)
+	return registry
+}
+
+// Load replaces the content of the registry
+func (registry *CurrencyRegistry) Load(currencies []Currency) {
+	byCode := make(map[string]Currency, len(currencies))
+	for _, currency := range currencies {
+		byCode[currency.Code] = currency
 	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.currencies = byCode
+}
+
+func (registry *CurrencyRegistry) Lookup(code string) (Currency, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	currency, ok := registry.currencies[code]
+	return currency, ok
+}
+
+// IsSupported reports whether new accounts and transfers may use the currency
+func (registry *CurrencyRegistry) IsSupported(code string) bool {
+	currency, ok := registry.Lookup(code)
+	return ok && currency.Enabled
+}
+
+// Currencies is the registry used by the validators, it holds the built-in currencies
+// until LoadCurrencies is called with the content of the database
+var Currencies = NewCurrencyRegistry(
+	Currency{Code: USD, MinorUnits: 2, Enabled: true},
+	Currency{Code: EUR, MinorUnits: 2, Enabled: true},
+	Currency{Code: CAD, MinorUnits: 2, Enabled: true},
+)
+
+func LoadCurrencies(currencies []Currency) {
+	Currencies.Load(currencies)
+}
+
+func LookupCurrency(code string) (Currency, bool) {
+	return Currencies.Lookup(code)
+}
+
+func IsSupportedCurrency(currency string) bool {
+	return Currencies.IsSupported(currency)
 }
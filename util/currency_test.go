@@ -0,0 +1,34 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCurrencyRegistry(t *testing.T) {
+	registry := NewCurrencyRegistry(
+		Currency{Code: USD, MinorUnits: 2, Enabled: true},
+		Currency{Code: "GBP", MinorUnits: 2, Enabled: false},
+	)
+
+	require.True(t, registry.IsSupported(USD))
+	require.False(t, registry.IsSupported("GBP"))
+	require.False(t, registry.IsSupported("JPY"))
+
+	// a disabled currency is still known, existing accounts keep using it
+	currency, ok := registry.Lookup("GBP")
+	require.True(t, ok)
+	require.Equal(t, int32(2), currency.MinorUnits)
+
+	registry.Load([]Currency{
+		{Code: USD, MinorUnits: 2, Enabled: true},
+		{Code: "GBP", MinorUnits: 2, Enabled: true},
+		{Code: "JPY", MinorUnits: 0, Enabled: true},
+	})
+	require.True(t, registry.IsSupported("GBP"))
+	require.True(t, registry.IsSupported("JPY"))
+
+	currency, ok = registry.Lookup("JPY")
+	require.True(t, ok)
+	require.Zero(t, currency.MinorUnits)
+}
@@ -5,6 +5,9 @@ type Role string
 var (
 	DepositorRole = Role("depositor")
 	BankerRole    = Role("banker")
+	// AuditorRole and SupportRole have read-only access to the data of every user
+	AuditorRole = Role("auditor")
+	SupportRole = Role("support")
 )
 
 func (role Role) String() string {
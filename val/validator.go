@@ -2,8 +2,12 @@ package val
 
 import (
 	"fmt"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/schedule"
+	"github.com/fayca121/simplebank/util"
 	"net/mail"
 	"regexp"
+	"time"
 )
 
 var (
@@ -55,3 +59,125 @@ func ValidateFullName(value string) error {
 	}
 	return nil
 }
+
+// ValidateIdempotencyKey accepts an empty key, which means the request is not idempotent
+func ValidateIdempotencyKey(value string) error {
+	if value == "" {
+		return nil
+	}
+	return ValidateString(value, 1, 255)
+}
+
+func ValidateID(value int64) error {
+	if value < 1 {
+		return fmt.Errorf("must be a positive integer")
+	}
+	return nil
+}
+
+func ValidateAmount(value int64) error {
+	if value <= 0 {
+		return fmt.Errorf("must be greater than 0")
+	}
+	return nil
+}
+
+func ValidateOverdraftLimit(value int64) error {
+	if value < 0 {
+		return fmt.Errorf("must be 0 or greater")
+	}
+	return nil
+}
+
+func ValidateCurrency(value string) error {
+	if !util.IsSupportedCurrency(value) {
+		return fmt.Errorf("unsupported currency %s", value)
+	}
+	return nil
+}
+
+const (
+	MinPageSize = 5
+	MaxPageSize = 100
+)
+
+func ValidatePageSize(value int32) error {
+	if value < MinPageSize || value > MaxPageSize {
+		return fmt.Errorf("must be from %d-%d", MinPageSize, MaxPageSize)
+	}
+	return nil
+}
+
+// MaxStatementPeriod is the longest period covered by a statement
+const MaxStatementPeriod = 366 * 24 * time.Hour
+
+// ValidateStatementPeriod checks the [from, to) period of a statement
+func ValidateStatementPeriod(from time.Time, to time.Time) error {
+	if !from.Before(to) {
+		return fmt.Errorf("must end after it starts")
+	}
+	if to.Sub(from) > MaxStatementPeriod {
+		return fmt.Errorf("must not exceed %d days", MaxStatementPeriod/(24*time.Hour))
+	}
+	return nil
+}
+
+// ValidateDirection accepts an empty direction, which means both directions
+func ValidateDirection(value string) error {
+	switch value {
+	case "", db.DirectionBoth, db.DirectionIncoming, db.DirectionOutgoing:
+		return nil
+	}
+	return fmt.Errorf("must be one of %s, %s or %s", db.DirectionBoth, db.DirectionIncoming, db.DirectionOutgoing)
+}
+
+// ValidateAmountRange accepts missing bounds
+func ValidateAmountRange(min *int64, max *int64) error {
+	if min != nil && *min < 0 || max != nil && *max < 0 {
+		return fmt.Errorf("must not be negative")
+	}
+	if min != nil && max != nil && *min > *max {
+		return fmt.Errorf("minimum must not exceed maximum")
+	}
+	return nil
+}
+
+// ValidateTimeRange accepts missing bounds
+func ValidateTimeRange(from *time.Time, to *time.Time) error {
+	if from != nil && to != nil && !from.Before(*to) {
+		return fmt.Errorf("must end after it starts")
+	}
+	return nil
+}
+
+func ValidateSchedule(value string) error {
+	if err := ValidateString(value, 1, 100); err != nil {
+		return err
+	}
+	_, err := schedule.Parse(value)
+	return err
+}
+
+func ValidateScheduledTransferStatus(value string) error {
+	switch value {
+	case db.ScheduledTransferActive, db.ScheduledTransferPaused:
+		return nil
+	}
+	return fmt.Errorf("must be %s or %s", db.ScheduledTransferActive, db.ScheduledTransferPaused)
+}
+
+func ValidateAccountStatus(value string) error {
+	switch value {
+	case db.AccountStatusActive, db.AccountStatusFrozen, db.AccountStatusClosed:
+		return nil
+	}
+	return fmt.Errorf("must be %s, %s or %s", db.AccountStatusActive, db.AccountStatusFrozen, db.AccountStatusClosed)
+}
+
+func ValidateStatusReason(value string) error {
+	return ValidateString(value, 1, 200)
+}
+
+func ValidateSecretCode(value string) error {
+	return ValidateString(value, 32, 128)
+}
@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/queue"
+	"time"
+)
+
+const (
+	// MaxAttempts is the number of runs of a task, it fails after the last failed one
+	MaxAttempts = 5
+	// RetryBackoff is the delay before the first retry, it doubles with each failed attempt up to MaxRetryBackoff
+	RetryBackoff    = 30 * time.Second
+	MaxRetryBackoff = time.Hour
+	// claimLease keeps a claimed task away from the other workers, a task that outlasts it may be run twice
+	claimLease = 5 * time.Minute
+	batchSize  = 50
+)
+
+var (
+	lease = queue.Lease{Name: "tasks", Duration: claimLease, BatchSize: batchSize}
+	retry = queue.Retry{MaxAttempts: MaxAttempts, Backoff: RetryBackoff, MaxBackoff: MaxRetryBackoff}
+)
+
+// ErrSkipRetry is wrapped by the handlers whose task can't succeed, it fails without being retried
+var ErrSkipRetry = errors.New("task is not retried")
+
+// Handler runs a task from its payload, the task is done once it returns nil
+type Handler func(ctx context.Context, payload []byte) error
+
+// Processor runs the tasks enqueued by the store transactions with the handler of their kind
+type Processor struct {
+	store    db.Store
+	handlers map[string]Handler
+}
+
+func NewProcessor(store db.Store) *Processor {
+	return &Processor{
+		store:    store,
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Handle registers the handler of the tasks of the given kind
+func (processor *Processor) Handle(kind string, handler Handler) {
+	processor.handlers[kind] = handler
+}
+
+// RunDue runs the tasks due at now and returns the number of tasks done. A failed task is retried with
+// an exponential backoff and fails after MaxAttempts runs, every failure is among the joined errors.
+func (processor *Processor) RunDue(ctx context.Context, now time.Time) (int, error) {
+	claim := func(ctx context.Context, claim queue.Claim) ([]db.Task, error) {
+		return processor.store.ClaimTasks(ctx, db.ClaimTasksParams(claim))
+	}
+	return queue.Each(ctx, lease, now, claim, func(ctx context.Context, task db.Task) error {
+		if err := processor.run(ctx, task, now); err != nil {
+			return fmt.Errorf("task %s [%d]: %w", task.Kind, task.ID, err)
+		}
+		return nil
+	})
+}
+
+func (processor *Processor) run(ctx context.Context, task db.Task, now time.Time) error {
+	var err error
+	if handler, ok := processor.handlers[task.Kind]; ok {
+		err = handler(ctx, task.Payload)
+	} else {
+		err = fmt.Errorf("%w: no handler for the kind %s", ErrSkipRetry, task.Kind)
+	}
+
+	if err == nil {
+		return processor.store.CompleteTask(ctx, db.CompleteTaskParams{CompletedAt: now, ID: task.ID})
+	}
+
+	retryAt, last := retry.After(task.Attempts+1, now)
+	arg := db.RecordTaskFailureParams{
+		LastError: err.Error(),
+		Status:    db.TaskPending,
+		RunAt:     retryAt,
+		ID:        task.ID,
+	}
+	if last || errors.Is(err, ErrSkipRetry) {
+		arg.Status, arg.RunAt = db.TaskFailed, now
+	}
+	if recordErr := processor.store.RecordTaskFailure(ctx, arg); recordErr != nil {
+		return errors.Join(err, fmt.Errorf("cannot record the failure: %w", recordErr))
+	}
+	return err
+}
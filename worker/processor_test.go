@@ -0,0 +1,148 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	mockdb "github.com/fayca121/simplebank/db/mock"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRunDue(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	task := db.Task{ID: 1, Kind: "test", Payload: []byte(`{}`), Status: db.TaskPending}
+	errHandler := errors.New("smtp unavailable")
+
+	testCases := []struct {
+		name       string
+		task       db.Task
+		handler    Handler
+		buildStubs func(store *mockdb.MockStore)
+		done       int
+	}{
+		{
+			name: "Done",
+			task: task,
+			handler: func(ctx context.Context, payload []byte) error {
+				return nil
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CompleteTask(gomock.Any(), gomock.Eq(db.CompleteTaskParams{CompletedAt: now, ID: task.ID})).
+					Times(1).
+					Return(nil)
+			},
+			done: 1,
+		},
+		{
+			name: "Retry",
+			task: task,
+			handler: func(ctx context.Context, payload []byte) error {
+				return errHandler
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RecordTaskFailure(gomock.Any(), gomock.Eq(db.RecordTaskFailureParams{
+						LastError: errHandler.Error(),
+						Status:    db.TaskPending,
+						RunAt:     now.Add(util.Backoff(1, RetryBackoff, MaxRetryBackoff)),
+						ID:        task.ID,
+					})).
+					Times(1).
+					Return(nil)
+			},
+		},
+		{
+			name: "FailAfterLastAttempt",
+			task: func() db.Task {
+				last := task
+				last.Attempts = MaxAttempts - 1
+				return last
+			}(),
+			handler: func(ctx context.Context, payload []byte) error {
+				return errHandler
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RecordTaskFailure(gomock.Any(), gomock.Eq(db.RecordTaskFailureParams{
+						LastError: errHandler.Error(),
+						Status:    db.TaskFailed,
+						RunAt:     now,
+						ID:        task.ID,
+					})).
+					Times(1).
+					Return(nil)
+			},
+		},
+		{
+			name: "SkipRetry",
+			task: task,
+			handler: func(ctx context.Context, payload []byte) error {
+				return fmt.Errorf("%w: user not found", ErrSkipRetry)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RecordTaskFailure(gomock.Any(), gomock.Eq(db.RecordTaskFailureParams{
+						LastError: ErrSkipRetry.Error() + ": user not found",
+						Status:    db.TaskFailed,
+						RunAt:     now,
+						ID:        task.ID,
+					})).
+					Times(1).
+					Return(nil)
+			},
+		},
+		{
+			name: "UnknownKind",
+			task: func() db.Task {
+				unknown := task
+				unknown.Kind = "unknown"
+				return unknown
+			}(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RecordTaskFailure(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RecordTaskFailureParams) error {
+						require.Equal(t, db.TaskFailed, arg.Status)
+						return nil
+					})
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+
+			store.EXPECT().
+				ClaimTasks(gomock.Any(), gomock.Eq(db.ClaimTasksParams{
+					LeaseUntil: now.Add(claimLease),
+					DueAt:      now,
+					Limit:      batchSize,
+				})).
+				Times(1).
+				Return([]db.Task{tc.task}, nil)
+			tc.buildStubs(store)
+
+			processor := NewProcessor(store)
+			processor.Handle(task.Kind, tc.handler)
+			done, err := processor.RunDue(context.Background(), now)
+			require.Equal(t, tc.done, done)
+			if tc.done == 0 {
+				// the failures are returned to be logged
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
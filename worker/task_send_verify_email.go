@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/mail"
+	"github.com/jackc/pgx/v5"
+	"net/url"
+	"strconv"
+)
+
+// secretCodeBytes is the entropy of the email verification codes
+const secretCodeBytes = 32
+
+// SendVerifyEmail returns the handler of the send_verify_email tasks: it creates a verification code for the
+// current email of the user and mails the link to verifyURL that uses it
+func SendVerifyEmail(store db.Store, sender mail.Sender, verifyURL string) (Handler, error) {
+	tmpl, err := mail.LoadTemplate(mail.TemplateVerifyEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, payload []byte) error {
+		var task db.SendVerifyEmailPayload
+		if err := json.Unmarshal(payload, &task); err != nil {
+			return fmt.Errorf("%w: cannot unmarshal the payload: %s", ErrSkipRetry, err)
+		}
+
+		user, err := store.GetUser(ctx, task.Username)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%w: user %s not found", ErrSkipRetry, task.Username)
+			}
+			return fmt.Errorf("cannot get user: %w", err)
+		}
+		if user.IsEmailVerified {
+			return nil
+		}
+
+		code, err := secretCode()
+		if err != nil {
+			return err
+		}
+		verifyEmail, err := store.CreateVerifyEmail(ctx, db.CreateVerifyEmailParams{
+			Username:   user.Username,
+			Email:      user.Email,
+			SecretCode: code,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot create verify email: %w", err)
+		}
+
+		msg, err := tmpl.Message(struct {
+			FullName string
+			Link     string
+		}{
+			FullName: user.FullName,
+			Link:     VerifyEmailLink(verifyURL, verifyEmail),
+		})
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrSkipRetry, err)
+		}
+		msg.To = []string{user.Email}
+		if err = sender.Send(ctx, msg); err != nil {
+			return fmt.Errorf("cannot send verify email: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// VerifyEmailLink returns the link to verifyURL with the id and secret code of the verify email
+func VerifyEmailLink(verifyURL string, verifyEmail db.VerifyEmail) string {
+	query := url.Values{}
+	query.Set("email_id", strconv.FormatInt(verifyEmail.ID, 10))
+	query.Set("secret_code", verifyEmail.SecretCode)
+	return verifyURL + "?" + query.Encode()
+}
+
+func secretCode() (string, error) {
+	code := make([]byte, secretCodeBytes)
+	if _, err := rand.Read(code); err != nil {
+		return "", fmt.Errorf("cannot generate secret code: %w", err)
+	}
+	return hex.EncodeToString(code), nil
+}
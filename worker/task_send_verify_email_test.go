@@ -0,0 +1,110 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	mockdb "github.com/fayca121/simplebank/db/mock"
+	db "github.com/fayca121/simplebank/db/sqlc"
+	"github.com/fayca121/simplebank/mail"
+	"github.com/fayca121/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"testing"
+)
+
+// recordingSender keeps the messages it is asked to send
+type recordingSender struct {
+	sent []mail.Message
+}
+
+func (sender *recordingSender) Send(_ context.Context, msg mail.Message) error {
+	sender.sent = append(sender.sent, msg)
+	return nil
+}
+
+func TestSendVerifyEmail(t *testing.T) {
+	user := db.User{Username: util.RandomOwner(), Email: util.RandomEmail()}
+	payload, err := json.Marshal(db.SendVerifyEmailPayload{Username: user.Username})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, err error, sent []mail.Message)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().
+					CreateVerifyEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateVerifyEmailParams) (db.VerifyEmail, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, user.Email, arg.Email)
+						require.Len(t, arg.SecretCode, 2*secretCodeBytes)
+						return db.VerifyEmail{ID: 1, Username: arg.Username, Email: arg.Email, SecretCode: arg.SecretCode}, nil
+					})
+			},
+			check: func(t *testing.T, err error, sent []mail.Message) {
+				require.NoError(t, err)
+				require.Len(t, sent, 1)
+				require.Equal(t, []string{user.Email}, sent[0].To)
+				require.Contains(t, sent[0].Text, "email_id=1")
+				require.Contains(t, sent[0].HTML, "email_id=1")
+			},
+		},
+		{
+			name: "AlreadyVerified",
+			buildStubs: func(store *mockdb.MockStore) {
+				verified := user
+				verified.IsEmailVerified = true
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(verified, nil)
+				store.EXPECT().CreateVerifyEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, err error, sent []mail.Message) {
+				require.NoError(t, err)
+				require.Empty(t, sent)
+			},
+		},
+		{
+			name: "UserNotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.User{}, pgx.ErrNoRows)
+				store.EXPECT().CreateVerifyEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, err error, sent []mail.Message) {
+				require.ErrorIs(t, err, ErrSkipRetry)
+				require.Empty(t, sent)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			sender := &recordingSender{}
+			handler, err := SendVerifyEmail(store, sender, "http://localhost:8080/v1/verify_email")
+			require.NoError(t, err)
+			err = handler(context.Background(), payload)
+			tc.check(t, err, sender.sent)
+		})
+	}
+}
+
+func TestVerifyEmailLink(t *testing.T) {
+	link := VerifyEmailLink("http://localhost:8080/v1/verify_email", db.VerifyEmail{ID: 7, SecretCode: "a+b"})
+
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	require.Equal(t, "/v1/verify_email", parsed.Path)
+	require.Equal(t, "7", parsed.Query().Get("email_id"))
+	require.Equal(t, "a+b", parsed.Query().Get("secret_code"))
+}